@@ -20,7 +20,9 @@ import (
 	event "github.com/nirmata/kyverno/pkg/event"
 	"github.com/nirmata/kyverno/pkg/generate"
 	generatecleanup "github.com/nirmata/kyverno/pkg/generate/cleanup"
+	"github.com/nirmata/kyverno/pkg/leaderelection"
 	"github.com/nirmata/kyverno/pkg/policy"
+	"github.com/nirmata/kyverno/pkg/policyreport"
 	"github.com/nirmata/kyverno/pkg/policystatus"
 	"github.com/nirmata/kyverno/pkg/policyviolation"
 	"github.com/nirmata/kyverno/pkg/signal"
@@ -35,7 +37,11 @@ import (
 	log "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-const resyncPeriod = 15 * time.Minute
+const (
+	resyncPeriod = 15 * time.Minute
+	// leaderElectionLeaseName is the name of the lease held by the leader in the Kyverno namespace
+	leaderElectionLeaseName = "kyverno"
+)
 
 var (
 	kubeconfig                     string
@@ -50,8 +56,11 @@ var (
 	excludeGroupRole string
 	excludeUsername  string
 	// User FQDN as CSR CN
-	fqdncn   bool
-	setupLog = log.Log.WithName("setup")
+	fqdncn bool
+	// create policy violations in addition to the policy reports
+	policyViolations bool
+	auditWarnings    bool
+	setupLog         = log.Log.WithName("setup")
 )
 
 func main() {
@@ -65,6 +74,8 @@ func main() {
 	flag.StringVar(&serverIP, "serverIP", "", "IP address where Kyverno controller runs. Only required if out-of-cluster.")
 	flag.StringVar(&runValidationInMutatingWebhook, "runValidationInMutatingWebhook", "", "Validation will also be done using the mutation webhook, set to 'true' to enable. Older kubernetes versions do not work properly when a validation webhook is registered.")
 	flag.BoolVar(&profile, "profile", false, "Set this flag to 'true', to enable profiling.")
+	flag.BoolVar(&policyViolations, "policyViolations", true, "Set this flag to 'false', to only report the policy results in the policy reports and not create policy violations.")
+	flag.BoolVar(&auditWarnings, "auditWarnings", false, "Set this flag to 'true', to apply the audit policies during admission and return the failures as warnings to admission.k8s.io/v1 clients.")
 	if err := flag.Set("v", "2"); err != nil {
 		setupLog.Error(err, "failed to set log level")
 		os.Exit(1)
@@ -121,6 +132,15 @@ func main() {
 	// TODO: To be removed for v1.2.0
 	utils.CleanupOldCrd(client, log.Log)
 
+	// LEADER ELECTION
+	// - all the instances serve the admission requests
+	// - only the leader processes the existing resources, writes the policy status and registers the webhooks
+	le, err := leaderelection.New(leaderElectionLeaseName, config.KubePolicyNamespace, kubeClient, log.Log.WithName("LeaderElection"))
+	if err != nil {
+		setupLog.Error(err, "Failed to create leader election")
+		os.Exit(1)
+	}
+
 	kubeInformer := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, resyncPeriod)
 	kubedynamicInformer := client.NewDynamicSharedInformerFactory(resyncPeriod)
 
@@ -131,23 +151,28 @@ func main() {
 		int32(webhookTimeout),
 		log.Log)
 
+	// KYVERNO CRD INFORMER
+	// watches CRD resources:
+	//		- Policy
+	//		- PolicyVolation
+	pInformer := kyvernoinformer.NewSharedInformerFactoryWithOptions(pclient, resyncPeriod)
+
+	pCacheController := policycache.NewPolicyCacheController(
+		pInformer.Kyverno().V1().ClusterPolicies(),
+		pInformer.Kyverno().V1().Policies(),
+		log.Log.WithName("PolicyCacheController"),
+	)
+
 	// Resource Mutating Webhook Watcher
 	lastReqTime := checker.NewLastReqTime(log.Log.WithName("LastReqTime"))
 	rWebhookWatcher := webhookconfig.NewResourceWebhookRegister(
 		lastReqTime,
-		kubeInformer.Admissionregistration().V1beta1().MutatingWebhookConfigurations(),
-		kubeInformer.Admissionregistration().V1beta1().ValidatingWebhookConfigurations(),
 		webhookRegistrationClient,
+		pCacheController,
 		runValidationInMutatingWebhook,
 		log.Log.WithName("ResourceWebhookRegister"),
 	)
 
-	// KYVERNO CRD INFORMER
-	// watches CRD resources:
-	//		- Policy
-	//		- PolicyVolation
-	pInformer := kyvernoinformer.NewSharedInformerFactoryWithOptions(pclient, resyncPeriod)
-
 	// Configuration Data
 	// dynamically load the configuration from configMap
 	// - resource filters
@@ -171,15 +196,27 @@ func main() {
 	// Policy Status Handler - deals with all logic related to policy status
 	statusSync := policystatus.NewSync(
 		pclient,
-		pInformer.Kyverno().V1().ClusterPolicies().Lister())
+		pInformer.Kyverno().V1().ClusterPolicies().Lister(),
+		pInformer.Kyverno().V1().Policies().Lister())
+
+	// POLICY REPORT GENERATOR
+	// -- batch the policy results into policy reports
+	prgen := policyreport.NewGenerator(client,
+		pInformer.Kyverno().V1().ClusterPolicies(),
+		pInformer.Kyverno().V1().Policies(),
+		log.Log.WithName("PolicyReportGenerator"),
+	)
 
 	// POLICY VIOLATION GENERATOR
-	// -- generate policy violation
+	// -- report policy results
+	// -- generate policy violation, if enabled
 	pvgen := policyviolation.NewPVGenerator(pclient,
 		client,
 		pInformer.Kyverno().V1().ClusterPolicyViolations(),
 		pInformer.Kyverno().V1().PolicyViolations(),
 		statusSync.Listener,
+		prgen,
+		policyViolations,
 		log.Log.WithName("PolicyViolationGenerator"),
 	)
 
@@ -190,13 +227,16 @@ func main() {
 	policyCtrl, err := policy.NewPolicyController(pclient,
 		client,
 		pInformer.Kyverno().V1().ClusterPolicies(),
+		pInformer.Kyverno().V1().Policies(),
 		pInformer.Kyverno().V1().ClusterPolicyViolations(),
 		pInformer.Kyverno().V1().PolicyViolations(),
 		configData,
 		eventGenerator,
 		pvgen,
 		rWebhookWatcher,
+		statusSync.Listener,
 		kubeInformer.Core().V1().Namespaces(),
+		kubeInformer.Core().V1().ConfigMaps(),
 		log.Log.WithName("PolicyController"),
 	)
 
@@ -214,7 +254,9 @@ func main() {
 		pclient,
 		client,
 		pInformer.Kyverno().V1().ClusterPolicies(),
+		pInformer.Kyverno().V1().Policies(),
 		pInformer.Kyverno().V1().GenerateRequests(),
+		kubeInformer.Core().V1().ConfigMaps(),
 		eventGenerator,
 		kubedynamicInformer,
 		statusSync.Listener,
@@ -228,27 +270,36 @@ func main() {
 		pclient,
 		client,
 		pInformer.Kyverno().V1().ClusterPolicies(),
+		pInformer.Kyverno().V1().Policies(),
 		pInformer.Kyverno().V1().GenerateRequests(),
 		kubedynamicInformer,
 		log.Log.WithName("GenerateCleanUpController"),
 	)
 
-	pCacheController := policycache.NewPolicyCacheController(
-		pInformer.Kyverno().V1().ClusterPolicies(),
-		log.Log.WithName("PolicyCacheController"),
-	)
-
 	auditHandler := webhooks.NewValidateAuditHandler(
+		client,
 		pCacheController.Cache,
 		eventGenerator,
 		statusSync.Listener,
 		pvgen,
 		kubeInformer.Rbac().V1().RoleBindings(),
 		kubeInformer.Rbac().V1().ClusterRoleBindings(),
+		kubeInformer.Core().V1().ConfigMaps(),
+		kubeInformer.Core().V1().Namespaces(),
 		log.Log.WithName("ValidateAuditHandler"),
 		configData,
 	)
 
+	mutateTargetsHandler := webhooks.NewMutateTargetsHandler(
+		client,
+		pCacheController.Cache,
+		statusSync.Listener,
+		kubeInformer.Core().V1().ConfigMaps(),
+		kubeInformer.Core().V1().Namespaces(),
+		log.Log.WithName("MutateTargetsHandler"),
+		configData,
+	)
+
 	// CONFIGURE CERTIFICATES
 	tlsPair, err := client.InitTLSPemPair(clientConfig, fqdncn)
 	if err != nil {
@@ -256,16 +307,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	// WEBHOOK REGISTRATION
-	// - mutating,validatingwebhookconfiguration (Policy)
-	// - verifymutatingwebhookconfiguration (Kyverno Deployment)
-	// resource webhook confgiuration is generated dynamically in the webhook server and policy controller
-	// based on the policy resources created
-	if err = webhookRegistrationClient.Register(); err != nil {
-		setupLog.Error(err, "Failed to register Admission webhooks")
-		os.Exit(1)
-	}
-
 	openAPIController, err := openapi.NewOpenAPIController()
 	if err != nil {
 		setupLog.Error(err, "Failed to create openAPIController")
@@ -292,6 +333,8 @@ func main() {
 		kubeInformer.Rbac().V1().ClusterRoleBindings(),
 		kubeInformer.Rbac().V1().Roles(),
 		kubeInformer.Rbac().V1().ClusterRoles(),
+		kubeInformer.Core().V1().ConfigMaps(),
+		kubeInformer.Core().V1().Namespaces(),
 		eventGenerator,
 		pCacheController.Cache,
 		webhookRegistrationClient,
@@ -301,7 +344,9 @@ func main() {
 		grgen,
 		rWebhookWatcher,
 		auditHandler,
+		mutateTargetsHandler,
 		supportMudateValidate,
+		auditWarnings,
 		cleanUp,
 		log.Log.WithName("WebhookServer"),
 		openAPIController,
@@ -313,29 +358,77 @@ func main() {
 	}
 
 	// Start the components
+	// the policy violation and report generators run in all the instances, they receive
+	// the results of the admission requests served by the instance
 	pInformer.Start(stopCh)
 	kubeInformer.Start(stopCh)
 	kubedynamicInformer.Start(stopCh)
 	go grgen.Run(1)
-	go rWebhookWatcher.Run(stopCh)
 	go configData.Run(stopCh)
-	go policyCtrl.Run(3, stopCh)
 	go eventGenerator.Run(1, stopCh)
-	go grc.Run(1, stopCh)
-	go grcc.Run(1, stopCh)
 	go pvgen.Run(1, stopCh)
+	go prgen.Run(2, stopCh)
 	go statusSync.Run(1, stopCh)
 	go pCacheController.Run(1, stopCh)
 	go auditHandler.Run(10, stopCh)
+	go mutateTargetsHandler.Run(3, stopCh)
 	openAPISync.Run(1, stopCh)
 
+	// Start the components of the leader
+	// the components are stopped when the leadership is lost or on shutdown
+	startLeading := func(ctx context.Context) {
+		leaderStopCh := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+			case <-stopCh:
+			}
+			close(leaderStopCh)
+		}()
+
+		// WEBHOOK REGISTRATION
+		// - mutating,validatingwebhookconfiguration (Policy)
+		// - verifymutatingwebhookconfiguration (Kyverno Deployment)
+		// resource webhook confgiuration is generated dynamically in the webhook server and policy controller
+		// based on the policy resources created
+		if err := webhookRegistrationClient.Register(); err != nil {
+			setupLog.Error(err, "Failed to register Admission webhooks")
+			os.Exit(1)
+		}
+
+		go rWebhookWatcher.Run(leaderStopCh)
+		go policyCtrl.Run(3, leaderStopCh)
+		go grc.Run(1, leaderStopCh)
+		go grcc.Run(1, leaderStopCh)
+	}
+
+	// the controllers of the leader cannot be started again, an instance that
+	// loses the leadership shuts down and is restarted as a follower
+	leaderLost := make(chan struct{})
+	stopLeading := func() {
+		close(leaderLost)
+	}
+
+	leCtx, leCancel := context.WithCancel(context.Background())
+	leDone := make(chan struct{})
+	go func() {
+		le.Run(leCtx, startLeading, stopLeading)
+		close(leDone)
+	}()
+
 	// verifys if the admission control is enabled and active
 	// resync: 60 seconds
 	// deadline: 60 seconds (send request)
 	// max deadline: deadline*3 (set the deployment annotation as false)
 	server.RunAsync(stopCh)
 
-	<-stopCh
+	lost := false
+	select {
+	case <-stopCh:
+	case <-leaderLost:
+		setupLog.Info("leadership lost, shutting down")
+		lost = true
+	}
 
 	// by default http.Server waits indefinitely for connections to return to idle and then shuts down
 	// adding a threshold will handle zombie connections
@@ -345,11 +438,17 @@ func main() {
 		cancel()
 	}()
 
-	// cleanup webhookconfigurations followed by webhook shutdown
+	// webhook shutdown, the webhook configurations are not removed
+	// as the next leader takes over the registration
 	server.Stop(ctx)
-
-	// resource cleanup
-	// remove webhook configurations
 	<-cleanUp
+
+	// release the lease, so another instance takes over without waiting for the lease to expire
+	leCancel()
+	<-leDone
+
+	if lost {
+		os.Exit(1)
+	}
 	setupLog.Info("Kyverno shutdown successful")
 }
@@ -36,6 +36,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ClusterPolicyList{},
 		&ClusterPolicyViolation{},
 		&ClusterPolicyViolationList{},
+		&Policy{},
+		&PolicyList{},
 		&PolicyViolation{},
 		&PolicyViolationList{},
 		&GenerateRequest{},
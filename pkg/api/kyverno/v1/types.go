@@ -42,6 +42,12 @@ type RequestInfo struct {
 	ClusterRoles []string `json:"clusterRoles" yaml:"clusterRoles"`
 	// UserInfo is the userInfo carried in the admission request
 	AdmissionUserInfo authenticationv1.UserInfo `json:"userInfo" yaml:"userInfo"`
+	// Operation is the operation of the admission request, it is empty for background processing
+	Operation AdmissionOperation `json:"operation,omitempty" yaml:"operation,omitempty"`
+	// SubResource is the subresource of the admission request, e.g. exec or scale
+	SubResource string `json:"subResource,omitempty" yaml:"subResource,omitempty"`
+	// ParentKind is the group, version and kind of the resource owning the requested subresource
+	ParentKind *metav1.GroupVersionKind `json:"parentKind,omitempty" yaml:"parentKind,omitempty"`
 }
 
 //GenerateRequestStatus stores the status of generated request
@@ -124,6 +130,9 @@ type PolicyViolationList struct {
 	Items           []PolicyViolation `json:"items" yaml:"items"`
 }
 
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // Policy contains rules to be applied to created resources
 type Policy struct {
 	metav1.TypeMeta   `json:",inline,omitempty" yaml:",inline,omitempty"`
@@ -141,9 +150,22 @@ type Spec struct {
 	// ValidationFailureAction provides choice to enforce rules to resources during policy violations.
 	// Default value is "audit".
 	ValidationFailureAction string `json:"validationFailureAction,omitempty" yaml:"validationFailureAction,omitempty"`
+	// ValidationFailureActionOverrides overrides the ValidationFailureAction for the selected namespaces.
+	// The first matching override is used.
+	// +optional
+	ValidationFailureActionOverrides []ValidationFailureActionOverride `json:"validationFailureActionOverrides,omitempty" yaml:"validationFailureActionOverrides,omitempty"`
 	// Background provides choice for applying rules to existing resources.
 	// Default value is "true".
 	Background *bool `json:"background,omitempty" yaml:"background,omitempty"`
+	// MutateExistingAction enables the mutation of existing resources in the background
+	// when the policy is created or updated, "apply" patches the resources and "dryRun"
+	// only reports the resources that require mutation. Disabled by default.
+	// +optional
+	MutateExistingAction string `json:"mutateExistingAction,omitempty" yaml:"mutateExistingAction,omitempty"`
+	// GenerateExistingOnPolicyUpdate creates generate requests for the existing resources
+	// that match the generate rules when the policy is created or updated. Disabled by default.
+	// +optional
+	GenerateExistingOnPolicyUpdate bool `json:"generateExistingOnPolicyUpdate,omitempty" yaml:"generateExistingOnPolicyUpdate,omitempty"`
 }
 
 // Rule is set of mutation, validation and generation actions
@@ -151,6 +173,9 @@ type Spec struct {
 type Rule struct {
 	// Specifies rule name
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Specifies data sources to load into the rule context
+	// +optional
+	Context []ContextEntry `json:"context,omitempty" yaml:"context,omitempty"`
 	// Specifies resources for which the rule has to be applied.
 	// If it's defined, "kind" inside MatchResources block is required.
 	// +optional
@@ -170,6 +195,48 @@ type Rule struct {
 	// Specifies patterns to create additional resources
 	// +optional
 	Generation Generation `json:"generate,omitempty" yaml:"generate,omitempty"`
+	// Overrides the ValidationFailureAction of the policy for this rule
+	// +optional
+	ValidationFailureAction string `json:"validationFailureAction,omitempty" yaml:"validationFailureAction,omitempty"`
+}
+
+//ValidationFailureActionOverride sets the ValidationFailureAction for the selected namespaces
+type ValidationFailureActionOverride struct {
+	// Specifies the ValidationFailureAction, "enforce" or "audit"
+	Action string `json:"action" yaml:"action"`
+	// Specifies the namespace names, wildcards are supported
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	// Specifies the namespace labels
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty" yaml:"namespaceSelector,omitempty"`
+}
+
+//ContextEntry adds variables and data sources to a rule context
+type ContextEntry struct {
+	// Specifies the name of the variable that stores the data
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Specifies the ConfigMap to load
+	ConfigMap *ConfigMapReference `json:"configMap,omitempty" yaml:"configMap,omitempty"`
+	// Specifies the API server call to make
+	APICall *APICall `json:"apiCall,omitempty" yaml:"apiCall,omitempty"`
+}
+
+//APICall performs a GET request on the API server
+type APICall struct {
+	// Specifies the URL path of the resource or the list of resources, variables are allowed
+	URLPath string `json:"urlPath" yaml:"urlPath"`
+	// Specifies the JMESPath expression applied to the response
+	// +optional
+	JMESPath string `json:"jmesPath,omitempty" yaml:"jmesPath,omitempty"`
+}
+
+//ConfigMapReference refers to a ConfigMap
+type ConfigMapReference struct {
+	// Specifies the ConfigMap name, variables are allowed
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Specifies the ConfigMap namespace, variables are allowed
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 }
 
 //Condition defines the evaluation condition
@@ -180,6 +247,12 @@ type Condition struct {
 	Operator ConditionOperator `json:"operator,omitempty" yaml:"operator,omitempty"`
 	// Value to be compared
 	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	// Message is reported when the condition determines the result, it may contain variables
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// AnyConditions is a group of conditions of which at least one must pass
+	AnyConditions []Condition `json:"any,omitempty" yaml:"any,omitempty"`
+	// AllConditions is a group of conditions which must all pass
+	AllConditions []Condition `json:"all,omitempty" yaml:"all,omitempty"`
 }
 
 // ConditionOperator defines the type for condition operator
@@ -196,10 +269,42 @@ const (
 	In ConditionOperator = "In"
 	//NotIn for NotIn operator
 	NotIn ConditionOperator = "NotIn"
+	//GreaterThanOrEquals for GreaterThanOrEquals operator
+	GreaterThanOrEquals ConditionOperator = "GreaterThanOrEquals"
+	//GreaterThan for GreaterThan operator
+	GreaterThan ConditionOperator = "GreaterThan"
+	//LessThanOrEquals for LessThanOrEquals operator
+	LessThanOrEquals ConditionOperator = "LessThanOrEquals"
+	//LessThan for LessThan operator
+	LessThan ConditionOperator = "LessThan"
+	//DurationGreaterThanOrEquals for DurationGreaterThanOrEquals operator
+	DurationGreaterThanOrEquals ConditionOperator = "DurationGreaterThanOrEquals"
+	//DurationGreaterThan for DurationGreaterThan operator
+	DurationGreaterThan ConditionOperator = "DurationGreaterThan"
+	//DurationLessThanOrEquals for DurationLessThanOrEquals operator
+	DurationLessThanOrEquals ConditionOperator = "DurationLessThanOrEquals"
+	//DurationLessThan for DurationLessThan operator
+	DurationLessThan ConditionOperator = "DurationLessThan"
+	//SemverInRange for SemverInRange operator
+	SemverInRange ConditionOperator = "SemverInRange"
+	//SemverNotInRange for SemverNotInRange operator
+	SemverNotInRange ConditionOperator = "SemverNotInRange"
+	//AnyIn for AnyIn operator
+	AnyIn ConditionOperator = "AnyIn"
+	//AllIn for AllIn operator
+	AllIn ConditionOperator = "AllIn"
+	//AnyNotIn for AnyNotIn operator
+	AnyNotIn ConditionOperator = "AnyNotIn"
+	//AllNotIn for AllNotIn operator
+	AllNotIn ConditionOperator = "AllNotIn"
 )
 
 //MatchResources contains resource description of the resources that the rule is to apply on
 type MatchResources struct {
+	// Specifies a list of resource filters, the rule matches if any of them matches
+	Any ResourceFilters `json:"any,omitempty" yaml:"any,omitempty"`
+	// Specifies a list of resource filters, the rule matches if all of them match
+	All ResourceFilters `json:"all,omitempty" yaml:"all,omitempty"`
 	// Specifies user information
 	UserInfo `json:",omitempty" yaml:",omitempty"`
 	// Specifies resources to which rule is applied
@@ -208,12 +313,27 @@ type MatchResources struct {
 
 //ExcludeResources container resource description of the resources that are to be excluded from the applying the policy rule
 type ExcludeResources struct {
+	// Specifies a list of resource filters, the resource is excluded if any of them matches
+	Any ResourceFilters `json:"any,omitempty" yaml:"any,omitempty"`
+	// Specifies a list of resource filters, the resource is excluded if all of them match
+	All ResourceFilters `json:"all,omitempty" yaml:"all,omitempty"`
 	// Specifies user information
 	UserInfo `json:",omitempty" yaml:",omitempty"`
 	// Specifies resources to which rule is excluded
 	ResourceDescription `json:"resources,omitempty" yaml:"resources,omitempty"`
 }
 
+// ResourceFilters is a list of resource filters used by any and all
+type ResourceFilters []ResourceFilter
+
+// ResourceFilter describes a single set of user information and resource description
+type ResourceFilter struct {
+	// Specifies user information
+	UserInfo `json:",omitempty" yaml:",omitempty"`
+	// Specifies the resource description
+	ResourceDescription `json:"resources,omitempty" yaml:"resources,omitempty"`
+}
+
 // UserInfo filter based on users
 type UserInfo struct {
 	// Specifies list of namespaced role names
@@ -232,10 +352,30 @@ type ResourceDescription struct {
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
 	// Specifies list of namespaces
 	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	// Specifies the annotations, wildcards are supported in keys and values
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
 	// Specifies the set of selectors
 	Selector *metav1.LabelSelector `json:"selector,omitempty" yaml:"selector,omitempty"`
+	// Specifies the labels of the namespace of the resource
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty" yaml:"namespaceSelector,omitempty"`
+	// Specifies list of admission operations
+	Operations []AdmissionOperation `json:"operations,omitempty" yaml:"operations,omitempty"`
 }
 
+// AdmissionOperation defines the type for the operation of an admission request
+type AdmissionOperation string
+
+const (
+	// Create for CREATE requests
+	Create AdmissionOperation = "CREATE"
+	// Update for UPDATE requests
+	Update AdmissionOperation = "UPDATE"
+	// Delete for DELETE requests
+	Delete AdmissionOperation = "DELETE"
+	// Connect for CONNECT requests, e.g. pods/exec
+	Connect AdmissionOperation = "CONNECT"
+)
+
 // Mutation describes the way how Mutating Webhook will react on resource creation
 type Mutation struct {
 	// Specifies overlay patterns
@@ -248,6 +388,46 @@ type Mutation struct {
 
 	PatchStrategicMerge interface{} `json:"patchStrategicMerge,omitempty" yaml:"patchesStrategicMerge,omitempty"`
 	PatchesJSON6902     string      `json:"patchesJson6902,omitempty" yaml:"patchesJson6902,omitempty"`
+
+	// Applies the patches to each element of a list
+	ForEachMutation *ForEachMutation `json:"foreach,omitempty" yaml:"foreach,omitempty"`
+
+	// Targets selects the resources that are mutated instead of the admission request resource.
+	// Variables of the trigger resource are substituted and the targets are mutated asynchronously,
+	// the target resource is available as {{target}}
+	// +optional
+	Targets []TargetSelector `json:"targets,omitempty" yaml:"targets,omitempty"`
+}
+
+// TargetSelector selects the resources that are mutated by a rule
+type TargetSelector struct {
+	// Specifies the apiVersion of the targets, the preferred version is used if empty
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	// Specifies the kind of the targets
+	Kind string `json:"kind" yaml:"kind"`
+	// Specifies the namespace of the targets, all namespaces are selected if empty
+	// +optional
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Specifies the name of the targets, wildcards are supported
+	// +optional
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Specifies the labels of the targets
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty" yaml:"selector,omitempty"`
+}
+
+// ForEachMutation applies a patch for each element of a list,
+// the current element is available as {{element}} and its index as {{elementIndex}}
+type ForEachMutation struct {
+	// Specifies a JMESPath expression that returns the list of elements
+	List string `json:"list" yaml:"list"`
+	// Specifies the conditions an element must satisfy to be mutated
+	Preconditions []Condition `json:"preconditions,omitempty" yaml:"preconditions,omitempty"`
+	// Specifies the strategic merge patch applied for each element
+	PatchStrategicMerge interface{} `json:"patchStrategicMerge,omitempty" yaml:"patchStrategicMerge,omitempty"`
+	// Specifies the JSON patches applied for each element
+	PatchesJSON6902 string `json:"patchesJson6902,omitempty" yaml:"patchesJson6902,omitempty"`
 }
 
 // +k8s:deepcopy-gen=false
@@ -273,6 +453,25 @@ type Validation struct {
 	AnyPattern []interface{} `json:"anyPattern,omitempty" yaml:"anyPattern,omitempty"`
 	// Specifies conditions to deny validation
 	Deny *Deny `json:"deny,omitempty" yaml:"deny,omitempty"`
+	// Applies the validation to each element of a list
+	ForEachValidation *ForEachValidation `json:"foreach,omitempty" yaml:"foreach,omitempty"`
+	// Specifies the paths that cannot be changed or removed once they are set, e.g. /spec/selector
+	Immutable []string `json:"immutable,omitempty" yaml:"immutable,omitempty"`
+}
+
+// ForEachValidation validates each element of a list,
+// the current element is available as {{element}} and its index as {{elementIndex}}
+type ForEachValidation struct {
+	// Specifies a JMESPath expression that returns the list of elements
+	List string `json:"list" yaml:"list"`
+	// Specifies the conditions an element must satisfy to be validated
+	Preconditions []Condition `json:"preconditions,omitempty" yaml:"preconditions,omitempty"`
+	// Specifies the validation pattern applied to each element
+	Pattern interface{} `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	// Specifies the list of validation patterns applied to each element
+	AnyPattern []interface{} `json:"anyPattern,omitempty" yaml:"anyPattern,omitempty"`
+	// Specifies the conditions to deny each element
+	Deny *Deny `json:"deny,omitempty" yaml:"deny,omitempty"`
 }
 
 type Deny struct {
@@ -289,6 +488,27 @@ type Generation struct {
 	Data interface{} `json:"data,omitempty" yaml:"data,omitempty"`
 	// To clone resource from other resource
 	Clone CloneFrom `json:"clone,omitempty" yaml:"clone,omitempty"`
+	// To clone all the resources of the kinds that match a selector, each resource
+	// is cloned in the namespace of the rule with the same name
+	// +optional
+	CloneList CloneList `json:"cloneList,omitempty" yaml:"cloneList,omitempty"`
+	// Strategic merge patch applied to the cloned resources before they are written
+	// +optional
+	PatchStrategicMerge interface{} `json:"patchStrategicMerge,omitempty" yaml:"patchStrategicMerge,omitempty"`
+	// JSON 6902 patches applied to the cloned resources before they are written
+	// +optional
+	PatchesJSON6902 string `json:"patchesJson6902,omitempty" yaml:"patchesJson6902,omitempty"`
+}
+
+// CloneList selects the resources cloned by a generate rule
+type CloneList struct {
+	// Specifies the namespace of the source resources
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Specifies the kinds of the source resources
+	Kinds []string `json:"kinds,omitempty" yaml:"kinds,omitempty"`
+	// Specifies the labels of the source resources
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty" yaml:"selector,omitempty"`
 }
 
 // CloneFrom - location of the resource
@@ -316,6 +536,14 @@ type PolicyStatus struct {
 	ResourcesMutatedCount int `json:"resourcesMutatedCount,omitempty" yaml:"resourcesMutatedCount,omitempty"`
 	// Count of resources that were successfully generated, across all rules
 	ResourcesGeneratedCount int `json:"resourcesGeneratedCount,omitempty" yaml:"resourcesGeneratedCount,omitempty"`
+	// Count of existing resources that were mutated in the background
+	ExistingResourcesMutatedCount int `json:"existingResourcesMutatedCount,omitempty" yaml:"existingResourcesMutatedCount,omitempty"`
+	// Count of existing resources that require mutation in dryRun mode, or are waiting to be patched in apply mode
+	ExistingResourcesPendingCount int `json:"existingResourcesPendingCount,omitempty" yaml:"existingResourcesPendingCount,omitempty"`
+	// Count of generate requests created for existing trigger resources
+	ExistingGenerateRequestsCount int `json:"existingGenerateRequestsCount,omitempty" yaml:"existingGenerateRequestsCount,omitempty"`
+	// Count of target resources that were mutated, across all rules
+	TargetsMutatedCount int `json:"targetsMutatedCount,omitempty" yaml:"targetsMutatedCount,omitempty"`
 
 	Rules []RuleStats `json:"ruleStatus,omitempty" yaml:"ruleStatus,omitempty"`
 }
@@ -338,9 +566,20 @@ type RuleStats struct {
 	ResourcesMutatedCount int `json:"resourcesMutatedCount,omitempty" yaml:"resourcesMutatedCount,omitempty"`
 	// Count of resources that were successfully generated
 	ResourcesGeneratedCount int `json:"resourcesGeneratedCount,omitempty" yaml:"resourcesGeneratedCount,omitempty"`
+	// Count of target resources that were mutated
+	TargetsMutatedCount int `json:"targetsMutatedCount,omitempty" yaml:"targetsMutatedCount,omitempty"`
+	// Count of target resources that failed to be mutated
+	TargetsFailedCount int `json:"targetsFailedCount,omitempty" yaml:"targetsFailedCount,omitempty"`
 }
 
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // PolicyList is a list of Policy resources
+type PolicyList struct {
+	metav1.TypeMeta `json:",inline" yaml:",inline"`
+	metav1.ListMeta `json:"metadata" yaml:"metadata"`
+	Items           []Policy `json:"items" yaml:"items"`
+}
 
 // PolicyViolationTemplate stores the information regarinding the resources for which a policy failed to apply
 type PolicyViolationTemplate struct {
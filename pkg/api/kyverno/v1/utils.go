@@ -2,8 +2,43 @@ package v1
 
 import (
 	"reflect"
+	"strings"
 )
 
+const (
+	// MutateExistingApply patches the existing resources
+	MutateExistingApply = "apply"
+	// MutateExistingDryRun only reports the existing resources that require mutation
+	MutateExistingDryRun = "dryRun"
+	// GenerateExistingLabel marks the generate requests created for existing trigger resources
+	GenerateExistingLabel = "generate.kyverno.io/existing-resource"
+	// PolicyNamespaceAnnotation records the namespace of the namespaced policy that owns a policy violation
+	PolicyNamespaceAnnotation = "policyNamespace"
+)
+
+//PolicyKey returns the name for a cluster policy and namespace/name for a namespaced policy
+func PolicyKey(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+//ParsePolicyKey returns the namespace and name of a policy key, the namespace is empty for a cluster policy
+func ParsePolicyKey(key string) (namespace, name string) {
+	if i := strings.Index(key, "/"); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
+
+//ConvertPolicyToClusterPolicy converts a copy of a namespaced Policy to a ClusterPolicy,
+// the namespace is retained so the policy stays scoped to it
+func ConvertPolicyToClusterPolicy(nsPolicy *Policy) *ClusterPolicy {
+	cpol := ClusterPolicy(*nsPolicy.DeepCopy())
+	return &cpol
+}
+
 func (p *ClusterPolicy) HasAutoGenAnnotation() bool {
 	annotations := p.GetAnnotations()
 	_, ok := annotations["pod-policies.kyverno.io/autogen-controllers"]
@@ -28,6 +63,26 @@ func (p *ClusterPolicy) BackgroundProcessingEnabled() bool {
 	return *p.Spec.Background
 }
 
+//MutateExistingEnabled checks if the existing resources are mutated in the background
+func (p *ClusterPolicy) MutateExistingEnabled() bool {
+	return p.Spec.MutateExistingAction == MutateExistingApply || p.Spec.MutateExistingAction == MutateExistingDryRun
+}
+
+//HasGenerate checks for generate rules
+func (p *ClusterPolicy) HasGenerate() bool {
+	for _, rule := range p.Spec.Rules {
+		if rule.HasGenerate() {
+			return true
+		}
+	}
+	return false
+}
+
+//HasMutateTargets checks if the mutate rule mutates other resources than the trigger
+func (r Rule) HasMutateTargets() bool {
+	return len(r.Mutation.Targets) > 0
+}
+
 //HasMutate checks for mutate rule
 func (r Rule) HasMutate() bool {
 	return !reflect.DeepEqual(r.Mutation, Mutation{})
@@ -43,9 +98,43 @@ func (r Rule) HasGenerate() bool {
 	return !reflect.DeepEqual(r.Generation, Generation{})
 }
 
+//MatchKinds returns the kinds of the match block, including the kinds of the any and all filters
+func (r Rule) MatchKinds() []string {
+	kinds := append([]string{}, r.MatchResources.Kinds...)
+	for _, filter := range r.MatchResources.Any {
+		kinds = append(kinds, filter.Kinds...)
+	}
+
+	for _, filter := range r.MatchResources.All {
+		kinds = append(kinds, filter.Kinds...)
+	}
+
+	return kinds
+}
+
+//HasResourceFilters checks if the match or exclude block of the rule uses any or all
+func (r Rule) HasResourceFilters() bool {
+	return len(r.MatchResources.Any) > 0 || len(r.MatchResources.All) > 0 ||
+		len(r.ExcludeResources.Any) > 0 || len(r.ExcludeResources.All) > 0
+}
+
 // DeepCopyInto is declared because k8s:deepcopy-gen is
 // not able to generate this method for interface{} member
 func (in *Mutation) DeepCopyInto(out *Mutation) {
+	if out != nil {
+		*out = *in
+		if in.Targets != nil {
+			out.Targets = make([]TargetSelector, len(in.Targets))
+			for i := range in.Targets {
+				in.Targets[i].DeepCopyInto(&out.Targets[i])
+			}
+		}
+	}
+}
+
+// DeepCopyInto is declared because k8s:deepcopy-gen is
+// not able to generate this method for interface{} member
+func (in *ForEachMutation) DeepCopyInto(out *ForEachMutation) {
 	if out != nil {
 		*out = *in
 	}
@@ -67,11 +156,20 @@ func (in *Validation) DeepCopyInto(out *Validation) {
 	}
 }
 
+// DeepCopyInto is declared because k8s:deepcopy-gen is
+// not able to generate this method for interface{} member
+func (in *ForEachValidation) DeepCopyInto(out *ForEachValidation) {
+	if out != nil {
+		*out = *in
+	}
+}
+
 // DeepCopyInto is declared because k8s:deepcopy-gen is
 // not able to generate this method for interface{} member
 func (gen *Generation) DeepCopyInto(out *Generation) {
 	if out != nil {
 		*out = *gen
+		gen.CloneList.DeepCopyInto(&out.CloneList)
 	}
 }
 
@@ -80,9 +178,26 @@ func (gen *Generation) DeepCopyInto(out *Generation) {
 func (cond *Condition) DeepCopyInto(out *Condition) {
 	if out != nil {
 		*out = *cond
+		if cond.AnyConditions != nil {
+			out.AnyConditions = make([]Condition, len(cond.AnyConditions))
+			for i := range cond.AnyConditions {
+				cond.AnyConditions[i].DeepCopyInto(&out.AnyConditions[i])
+			}
+		}
+		if cond.AllConditions != nil {
+			out.AllConditions = make([]Condition, len(cond.AllConditions))
+			for i := range cond.AllConditions {
+				cond.AllConditions[i].DeepCopyInto(&out.AllConditions[i])
+			}
+		}
 	}
 }
 
+//HasConditionGroups checks if the condition is a group of any or all conditions
+func (cond Condition) HasConditionGroups() bool {
+	return len(cond.AnyConditions) > 0 || len(cond.AllConditions) > 0
+}
+
 //ToKey generates the key string used for adding label to polivy violation
 func (rs ResourceSpec) ToKey() string {
 	return rs.Kind + "." + rs.Name
@@ -0,0 +1,6 @@
+package policyreport
+
+const (
+	// GroupName must be the same as specified in the PolicyReport CRD
+	GroupName = "wgpolicyk8s.io"
+)
@@ -0,0 +1,4 @@
+// +k8s:deepcopy-gen=package
+// +groupName=wgpolicyk8s.io
+
+package v1alpha1
@@ -0,0 +1,128 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PolicyStatus is the result of a policy rule check: pass, fail, warn, error or skip
+type PolicyStatus string
+
+const (
+	// StatusPass is the status of a result that meets the policy requirements
+	StatusPass PolicyStatus = "pass"
+	// StatusFail is the status of a result that does not meet the policy requirements
+	StatusFail PolicyStatus = "fail"
+	// StatusWarn is the status of a result that does not meet the requirements of a policy which is not scored
+	StatusWarn PolicyStatus = "warn"
+	// StatusError is the status of a result that could not be evaluated
+	StatusError PolicyStatus = "error"
+	// StatusSkip is the status of a result that was not evaluated
+	StatusSkip PolicyStatus = "skip"
+)
+
+// PolicySeverity has one of the following values: high, medium, low
+type PolicySeverity string
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PolicyReport is the results of the policies applied to the resources of a namespace
+type PolicyReport struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	// Scope is an optional reference to the report scope (e.g. a Deployment, Namespace, or Node)
+	// +optional
+	Scope *corev1.ObjectReference `json:"scope,omitempty" yaml:"scope,omitempty"`
+	// ScopeSelector is an optional selector for multiple scopes (e.g. Pods)
+	// +optional
+	ScopeSelector *metav1.LabelSelector `json:"scopeSelector,omitempty" yaml:"scopeSelector,omitempty"`
+	// Summary provides a summary of results
+	// +optional
+	Summary PolicyReportSummary `json:"summary,omitempty" yaml:"summary,omitempty"`
+	// Results contains the results of the policies
+	// +optional
+	Results []*PolicyReportResult `json:"results,omitempty" yaml:"results,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PolicyReportList ...
+type PolicyReportList struct {
+	metav1.TypeMeta `json:",inline" yaml:",inline"`
+	metav1.ListMeta `json:"metadata" yaml:"metadata"`
+	Items           []PolicyReport `json:"items" yaml:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterPolicyReport is the results of the policies applied to cluster-wide resources
+type ClusterPolicyReport struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	// Scope is an optional reference to the report scope (e.g. a Deployment, Namespace, or Node)
+	// +optional
+	Scope *corev1.ObjectReference `json:"scope,omitempty" yaml:"scope,omitempty"`
+	// ScopeSelector is an optional selector for multiple scopes (e.g. Pods)
+	// +optional
+	ScopeSelector *metav1.LabelSelector `json:"scopeSelector,omitempty" yaml:"scopeSelector,omitempty"`
+	// Summary provides a summary of results
+	// +optional
+	Summary PolicyReportSummary `json:"summary,omitempty" yaml:"summary,omitempty"`
+	// Results contains the results of the policies
+	// +optional
+	Results []*PolicyReportResult `json:"results,omitempty" yaml:"results,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterPolicyReportList ...
+type ClusterPolicyReportList struct {
+	metav1.TypeMeta `json:",inline" yaml:",inline"`
+	metav1.ListMeta `json:"metadata" yaml:"metadata"`
+	Items           []ClusterPolicyReport `json:"items" yaml:"items"`
+}
+
+// PolicyReportSummary provides a status count summary
+type PolicyReportSummary struct {
+	// Pass provides the count of policies whose requirements were met
+	Pass int `json:"pass" yaml:"pass"`
+	// Fail provides the count of policies whose requirements were not met
+	Fail int `json:"fail" yaml:"fail"`
+	// Warn provides the count of unscored policies whose requirements were not met
+	Warn int `json:"warn" yaml:"warn"`
+	// Error provides the count of policies that could not be evaluated
+	Error int `json:"error" yaml:"error"`
+	// Skip indicates the count of policies that were not selected for evaluation
+	Skip int `json:"skip" yaml:"skip"`
+}
+
+// PolicyReportResult provides the result of a policy rule for a resource
+type PolicyReportResult struct {
+	// Policy is the name of the policy
+	Policy string `json:"policy" yaml:"policy"`
+	// Rule is the name of the policy rule
+	// +optional
+	Rule string `json:"rule,omitempty" yaml:"rule,omitempty"`
+	// Category indicates policy category
+	// +optional
+	Category string `json:"category,omitempty" yaml:"category,omitempty"`
+	// Severity indicates policy severity
+	// +optional
+	Severity PolicySeverity `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Message is a short user friendly description of the result
+	// +optional
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Status indicates the result of the policy rule check
+	Status PolicyStatus `json:"status,omitempty" yaml:"status,omitempty"`
+	// Resources is an optional reference to the resources checked by the policy rule
+	// +optional
+	Resources []*corev1.ObjectReference `json:"resources,omitempty" yaml:"resources,omitempty"`
+	// Scored indicates if this policy rule is scored
+	Scored bool `json:"scored" yaml:"scored"`
+	// Data provides additional information for the policy rule
+	// +optional
+	Data map[string]string `json:"data,omitempty" yaml:"data,omitempty"`
+}
@@ -24,4 +24,6 @@ type ClusterPolicyViolationExpansion interface{}
 
 type GenerateRequestExpansion interface{}
 
+type PolicyExpansion interface{}
+
 type PolicyViolationExpansion interface{}
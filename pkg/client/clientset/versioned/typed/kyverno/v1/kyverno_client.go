@@ -29,6 +29,7 @@ type KyvernoV1Interface interface {
 	ClusterPoliciesGetter
 	ClusterPolicyViolationsGetter
 	GenerateRequestsGetter
+	PoliciesGetter
 	PolicyViolationsGetter
 }
 
@@ -49,6 +50,10 @@ func (c *KyvernoV1Client) GenerateRequests(namespace string) GenerateRequestInte
 	return newGenerateRequests(c, namespace)
 }
 
+func (c *KyvernoV1Client) Policies(namespace string) PolicyInterface {
+	return newPolicies(c, namespace)
+}
+
 func (c *KyvernoV1Client) PolicyViolations(namespace string) PolicyViolationInterface {
 	return newPolicyViolations(c, namespace)
 }
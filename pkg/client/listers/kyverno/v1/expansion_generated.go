@@ -41,6 +41,14 @@ type ClusterPolicyViolationListerExpansion interface {
 	ListResources(selector labels.Selector) (ret []*kyvernov1.ClusterPolicyViolation, err error)
 }
 
+// PolicyListerExpansion allows custom methods to be added to
+// PolicyLister.
+type PolicyListerExpansion interface{}
+
+// PolicyNamespaceListerExpansion allows custom methods to be added to
+// PolicyNamespaceLister.
+type PolicyNamespaceListerExpansion interface{}
+
 // PolicyViolationListerExpansion allows custom methods to be added to
 // PolicyViolationLister.
 type PolicyViolationListerExpansion interface{}
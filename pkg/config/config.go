@@ -75,6 +75,8 @@ var (
 	LivenessServicePath = "/health/liveness"
 	// ReadinessServicePath is the path for check readness health
 	ReadinessServicePath = "/health/readiness"
+	// MetricsServicePath is the path for the Prometheus metrics
+	MetricsServicePath = "/metrics"
 )
 
 //CreateClientConfig creates client config
@@ -5,6 +5,7 @@ import "time"
 const (
 	CRDControllerResync             = 15 * time.Minute
 	PolicyViolationControllerResync = 15 * time.Minute
+	PolicyReportControllerResync    = 15 * time.Minute
 	PolicyControllerResync          = 15 * time.Minute
 	EventControllerResync           = 15 * time.Minute
 	GenerateControllerResync        = 15 * time.Minute
@@ -138,6 +138,14 @@ func (c *Client) GetDynamicInterface() dynamic.Interface {
 	return c.client
 }
 
+// RawAbsPath performs a raw GET request on the absolute path of the API server
+func (c *Client) RawAbsPath(path string, timeout time.Duration) ([]byte, error) {
+	if c.kclient == nil {
+		return nil, fmt.Errorf("kubernetes client is not initialized")
+	}
+	return c.kclient.Discovery().RESTClient().Get().AbsPath(path).Timeout(timeout).DoRaw()
+}
+
 // ListResource returns the list of resources in unstructured/json format
 // Access items using []Items
 func (c *Client) ListResource(apiVersion string, kind string, namespace string, lselector *meta.LabelSelector) (*unstructured.UnstructuredList, error) {
@@ -228,6 +236,7 @@ type IDiscovery interface {
 	FindResource(apiVersion string, kind string) (*meta.APIResource, schema.GroupVersionResource, error)
 	GetGVRFromKind(kind string) schema.GroupVersionResource
 	GetGVRFromAPIVersionKind(apiVersion string, kind string) schema.GroupVersionResource
+	GetGVKFromGVR(gvr schema.GroupVersionResource) (schema.GroupVersionKind, error)
 	GetServerVersion() (*version.Info, error)
 	OpenAPISchema() (*openapi_v2.Document, error)
 }
@@ -290,6 +299,47 @@ func (c ServerPreferredResources) GetGVRFromAPIVersionKind(apiVersion string, ki
 	return gvr
 }
 
+// GetGVKFromGVR gets the Group Version Kind of a Group Version Resource. If the resource is not
+// found and the Cache is not fresh, the cache is invalidated and a retry is attempted
+func (c ServerPreferredResources) GetGVKFromGVR(gvr schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	gvk, err := c.findKind(gvr)
+	if err == nil {
+		return gvk, nil
+	}
+
+	if !c.cachedClient.Fresh() {
+		c.cachedClient.Invalidate()
+		if gvk, err = c.findKind(gvr); err == nil {
+			return gvk, nil
+		}
+	}
+
+	return schema.GroupVersionKind{}, err
+}
+
+func (c ServerPreferredResources) findKind(gvr schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	serverresources, err := c.cachedClient.ServerResources()
+	if err != nil {
+		c.log.Error(err, "failed to get registered resources")
+		return schema.GroupVersionKind{}, err
+	}
+
+	groupVersion := gvr.GroupVersion().String()
+	for _, serverresource := range serverresources {
+		if serverresource.GroupVersion != groupVersion {
+			continue
+		}
+
+		for _, resource := range serverresource.APIResources {
+			if resource.Name == gvr.Resource {
+				return gvr.GroupVersion().WithKind(resource.Kind), nil
+			}
+		}
+	}
+
+	return schema.GroupVersionKind{}, fmt.Errorf("resource '%s' not found in groupVersion '%s'", gvr.Resource, groupVersion)
+}
+
 // GetServerVersion returns the server version of the cluster
 func (c ServerPreferredResources) GetServerVersion() (*version.Info, error) {
 	return c.cachedClient.ServerVersion()
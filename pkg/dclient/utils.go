@@ -83,6 +83,10 @@ func (c *fakeDiscoveryClient) GetGVRFromAPIVersionKind(apiVersion string, kind s
 	return c.getGVR(resource)
 }
 
+func (c *fakeDiscoveryClient) GetGVKFromGVR(gvr schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	return schema.GroupVersionKind{}, fmt.Errorf("Not implemented")
+}
+
 func (c *fakeDiscoveryClient) FindResource(apiVersion string, kind string) (*meta.APIResource, schema.GroupVersionResource, error) {
 	return nil, schema.GroupVersionResource{}, fmt.Errorf("Not implemented")
 }
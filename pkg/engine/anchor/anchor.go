@@ -20,6 +20,8 @@ func CreateElementHandler(element string, pattern interface{}, path string) Vali
 	switch {
 	case IsConditionAnchor(element):
 		return NewConditionAnchorHandler(element, pattern, path)
+	case IsGlobalAnchor(element):
+		return NewGlobalAnchorHandler(element, pattern, path)
 	case IsExistenceAnchor(element):
 		return NewExistenceHandler(element, pattern, path)
 	case IsEqualityAnchor(element):
@@ -157,6 +159,39 @@ func (ch ConditionAnchorHandler) Handle(handler resourceElementHandler, resource
 	return "", nil
 }
 
+//NewGlobalAnchorHandler returns an instance of global anchor handler
+func NewGlobalAnchorHandler(anchor string, pattern interface{}, path string) ValidationHandler {
+	return GlobalAnchorHandler{
+		anchor:  anchor,
+		pattern: pattern,
+		path:    path,
+	}
+}
+
+//GlobalAnchorHandler provides handler for global anchor
+type GlobalAnchorHandler struct {
+	anchor  string
+	pattern interface{}
+	path    string
+}
+
+//Handle processes the global anchor, unlike the condition anchor the field has to be present
+func (gh GlobalAnchorHandler) Handle(handler resourceElementHandler, resourceMap map[string]interface{}, originPattern interface{}) (string, error) {
+	anchorKey := removeAnchor(gh.anchor)
+	currentPath := gh.path + anchorKey + "/"
+	value, ok := resourceMap[anchorKey]
+	if !ok {
+		return currentPath, fmt.Errorf("Global anchor failed at %s, field %s is not present", currentPath, anchorKey)
+	}
+
+	// validate the values of the pattern
+	returnPath, err := handler(log.Log, value, gh.pattern, originPattern, currentPath)
+	if err != nil {
+		return returnPath, err
+	}
+	return "", nil
+}
+
 //NewExistenceHandler returns existence handler
 func NewExistenceHandler(anchor string, pattern interface{}, path string) ValidationHandler {
 	return ExistenceHandler{
@@ -221,7 +256,7 @@ func GetAnchorsResourcesFromMap(patternMap map[string]interface{}) (map[string]i
 	anchors := map[string]interface{}{}
 	resources := map[string]interface{}{}
 	for key, value := range patternMap {
-		if IsConditionAnchor(key) || IsExistenceAnchor(key) || IsEqualityAnchor(key) || IsNegationAnchor(key) {
+		if IsConditionAnchor(key) || IsExistenceAnchor(key) || IsEqualityAnchor(key) || IsNegationAnchor(key) || IsGlobalAnchor(key) {
 			anchors[key] = value
 			continue
 		}
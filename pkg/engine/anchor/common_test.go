@@ -56,3 +56,16 @@ func TestIsExistenceAnchor_OnlyHat(t *testing.T) {
 func TestIsExistenceAnchor_ConditionAnchor(t *testing.T) {
 	assert.Assert(t, !IsExistenceAnchor("(abc)"))
 }
+
+func TestIsGlobalAnchor_Yes(t *testing.T) {
+	assert.Assert(t, IsGlobalAnchor("<(image)"))
+}
+
+func TestIsGlobalAnchor_ConditionAnchor(t *testing.T) {
+	assert.Assert(t, !IsGlobalAnchor("(image)"))
+	assert.Assert(t, !IsConditionAnchor("<(image)"))
+}
+
+func TestRemoveAnchor_GlobalAnchor(t *testing.T) {
+	assert.Equal(t, removeAnchor("<(image)"), "image")
+}
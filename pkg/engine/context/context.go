@@ -20,9 +20,21 @@ type Interface interface {
 	//AddResource merges resource json under request.object
 	AddResource(dataRaw []byte) error
 	//AddUserInfo merges userInfo json under kyverno.userInfo
-	AddUserInfo(userInfo kyverno.UserInfo) error
+	AddUserInfo(userInfo kyverno.RequestInfo) error
 	//AddSA merges serrviceaccount
 	AddSA(userName string) error
+	//GetAPICallResult returns the cached response of an API call
+	GetAPICallResult(urlPath string) ([]byte, bool)
+	//AddAPICallResult caches the response of an API call
+	AddAPICallResult(urlPath string, data []byte)
+	//AddElement adds the current foreach element at element and its index at elementIndex
+	AddElement(data interface{}, index int) error
+	//AddTarget adds the target resource of a mutate rule at target
+	AddTarget(data interface{}) error
+	//Checkpoint saves the current data, it can be restored with Restore
+	Checkpoint()
+	//Restore restores the data saved by the last Checkpoint
+	Restore()
 	EvalInterface
 }
 
@@ -35,8 +47,11 @@ type EvalInterface interface {
 type Context struct {
 	mu            sync.RWMutex
 	jsonRaw       []byte
+	checkpoint    []byte
 	whiteListVars []string
-	log           logr.Logger
+	// API call responses are cached for the lifetime of the context
+	apiCallCache map[string][]byte
+	log          logr.Logger
 }
 
 //NewContext returns a new context
@@ -46,6 +61,7 @@ func NewContext(whiteListVars ...string) *Context {
 		// data:    map[string]interface{}{},
 		jsonRaw:       []byte(`{}`), // empty json struct
 		whiteListVars: whiteListVars,
+		apiCallCache:  map[string][]byte{},
 		log:           log.Log.WithName("context"),
 	}
 	return &ctx
@@ -172,3 +188,81 @@ func (ctx *Context) AddSA(userName string) error {
 
 	return nil
 }
+
+//GetAPICallResult returns the cached response of an API call
+func (ctx *Context) GetAPICallResult(urlPath string) ([]byte, bool) {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+	data, ok := ctx.apiCallCache[urlPath]
+	return data, ok
+}
+
+//AddAPICallResult caches the response of an API call
+func (ctx *Context) AddAPICallResult(urlPath string, data []byte) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.apiCallCache[urlPath] = data
+}
+
+//AddElement adds the foreach element at path element and its index at path elementIndex
+func (ctx *Context) AddElement(data interface{}, index int) error {
+	// remove the previous element first, as the data is merged
+	if err := ctx.AddJSON([]byte(`{"element":null,"elementIndex":null}`)); err != nil {
+		return err
+	}
+
+	element := struct {
+		Element      interface{} `json:"element"`
+		ElementIndex int         `json:"elementIndex"`
+	}{
+		Element:      data,
+		ElementIndex: index,
+	}
+
+	objRaw, err := json.Marshal(element)
+	if err != nil {
+		ctx.log.Error(err, "failed to marshal the element")
+		return err
+	}
+	return ctx.AddJSON(objRaw)
+}
+
+//AddTarget adds the target resource of a mutate rule at path target
+func (ctx *Context) AddTarget(data interface{}) error {
+	// remove the previous target first, as the data is merged
+	if err := ctx.AddJSON([]byte(`{"target":null}`)); err != nil {
+		return err
+	}
+
+	target := struct {
+		Target interface{} `json:"target"`
+	}{
+		Target: data,
+	}
+
+	objRaw, err := json.Marshal(target)
+	if err != nil {
+		ctx.log.Error(err, "failed to marshal the target")
+		return err
+	}
+	return ctx.AddJSON(objRaw)
+}
+
+//Checkpoint saves a copy of the current data
+func (ctx *Context) Checkpoint() {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	ctx.checkpoint = make([]byte, len(ctx.jsonRaw))
+	copy(ctx.checkpoint, ctx.jsonRaw)
+}
+
+//Restore restores the data saved by the last checkpoint
+func (ctx *Context) Restore() {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if ctx.checkpoint == nil {
+		return
+	}
+	ctx.jsonRaw = ctx.checkpoint
+	ctx.checkpoint = nil
+}
@@ -118,3 +118,114 @@ func Test_addResourceAndUserContext(t *testing.T) {
 		t.Error("exected result does not match")
 	}
 }
+
+func Test_QueryWhiteListedFunctions(t *testing.T) {
+	rawResource := []byte(`
+	{
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {
+		   "name": "nginx",
+		   "labels": {
+			  "app": "web"
+		   }
+		},
+		"spec": {
+		   "containers": [
+			  {
+				 "name": "nginx",
+				 "image": "nginx:1.19"
+			  }
+		   ]
+		}
+	 }
+	`)
+
+	ctx := NewContext("request.object")
+	if err := ctx.AddResource(rawResource); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		query       string
+		whiteListed bool
+	}{
+		{query: "request.object.metadata.name", whiteListed: true},
+		{query: "to_upper(request.object.metadata.name)", whiteListed: true},
+		{query: " split(request.object.spec.containers[0].image, ':')[1]", whiteListed: true},
+		{query: "request.object.spec.containers[?name=='nginx'].image | [0]", whiteListed: true},
+		{query: "regex_match('request', request.object.metadata.name)", whiteListed: true},
+		{query: "request.userInfo.username", whiteListed: false},
+		{query: "to_upper(request.userInfo.username)", whiteListed: false},
+		{query: "label_match(request.object.metadata.labels, serviceAccountName)", whiteListed: false},
+		{query: "[request.object.metadata.name, request.object.kind]", whiteListed: true},
+		{query: "[request.object.metadata.name, serviceAccountName]", whiteListed: false},
+		{query: "{name: request.object.metadata.name, user: request.userInfo.username}", whiteListed: false},
+		{query: "request.object.metadata | keys(@)", whiteListed: true},
+		{query: "request.object.metadata | [request.userInfo]", whiteListed: true},
+		{query: "request.object.metadata.name || request.userInfo.username", whiteListed: false},
+		{query: "contains(request.object.metadata.name, to_upper(request.userInfo.username))", whiteListed: false},
+		{query: "keys(@)", whiteListed: false},
+	}
+
+	for _, tc := range testCases {
+		_, err := ctx.Query(tc.query)
+		if tc.whiteListed && err != nil {
+			t.Errorf("expected query %s to be allowed, got error %v", tc.query, err)
+		}
+		if !tc.whiteListed && err == nil {
+			t.Errorf("expected query %s to be rejected", tc.query)
+		}
+	}
+}
+
+func Test_QueryPaths(t *testing.T) {
+	testCases := []struct {
+		query string
+		paths []string
+	}{
+		{query: "request.object.metadata.name", paths: []string{"request.object.metadata.name"}},
+		{query: "request.object.spec.containers[0].image", paths: []string{"request.object.spec.containers.image"}},
+		{query: "request.object.spec.containers[?name=='nginx'].image | [0]", paths: []string{"request.object.spec.containers"}},
+		{query: "[request.object.kind, images.containers]", paths: []string{"request.object.kind", "images.containers"}},
+		{query: "{kind: request.object.kind, user: request.userInfo}", paths: []string{"request.object.kind", "request.userInfo"}},
+		{query: "request.object | keys(@)", paths: []string{"request.object"}},
+		{query: "sort_by(request.object.spec.containers, &name)", paths: []string{"request.object.spec.containers"}},
+		{query: "contains(request.object.metadata.name, 'nginx') && !serviceAccountName", paths: []string{"request.object.metadata.name", "serviceAccountName"}},
+		{query: "to_upper(@)", paths: []string{""}},
+		{query: "contains(request.object.metadata.name, '}\n  }\n') && \"quoted.field\"", paths: []string{"request.object.metadata.name", "quoted.field"}},
+	}
+
+	for _, tc := range testCases {
+		paths, err := queryPaths(tc.query)
+		if err != nil {
+			t.Errorf("failed to parse query %s: %v", tc.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(paths, tc.paths) {
+			t.Errorf("query %s: expected paths %v, got %v", tc.query, tc.paths, paths)
+		}
+	}
+}
+
+func Test_ParseASTNode_Invalid(t *testing.T) {
+	for _, printed := range []string{
+		"",
+		"ASTField {\n",
+		"ASTField {\n  value: \"name\"\n}\n}\n",
+		"ASTSubexpression {\n  children: {\n  ASTField {\n  }\n}\n",
+	} {
+		if _, err := parseASTNode(printed); err == nil {
+			t.Errorf("expected an error for %q", printed)
+		}
+	}
+
+	// unknown node types are not allowed
+	node, err := parseASTNode("ASTUnknown {\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := node.paths(); err == nil {
+		t.Error("expected an error for an unknown node type")
+	}
+}
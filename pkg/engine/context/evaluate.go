@@ -3,25 +3,27 @@ package context
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
-	jmespath "github.com/jmespath/go-jmespath"
+	gojmespath "github.com/jmespath/go-jmespath"
+	"github.com/nirmata/kyverno/pkg/engine/jmespath"
 )
 
 //Query the JSON context with JMESPATH search path
 func (ctx *Context) Query(query string) (interface{}, error) {
 	var emptyResult interface{}
-	// check for white-listed variables
-	if !ctx.isWhiteListed(query) {
-		return emptyResult, fmt.Errorf("variable %s cannot be used", query)
-	}
-
-	// compile the query
-	queryPath, err := jmespath.Compile(query)
+	// compile the query with the custom functions
+	queryPath, err := jmespath.New(query)
 	if err != nil {
 		ctx.log.Error(err, "incorrect query", "query", query)
 		return emptyResult, fmt.Errorf("incorrect query %s: %v", query, err)
 	}
+
+	// check for white-listed variables
+	if !ctx.isWhiteListed(query) {
+		return emptyResult, fmt.Errorf("variable %s cannot be used", query)
+	}
 	// search
 	ctx.mu.RLock()
 	defer ctx.mu.RUnlock()
@@ -44,10 +46,184 @@ func (ctx *Context) isWhiteListed(variable string) bool {
 	if len(ctx.whiteListVars) == 0 {
 		return true
 	}
+	// the query can reference multiple paths, e.g. as function arguments
+	paths, err := queryPaths(variable)
+	if err != nil {
+		return false
+	}
+	for _, path := range paths {
+		if !ctx.hasWhiteListedPrefix(path) {
+			return false
+		}
+	}
+	return true
+}
+
+func (ctx *Context) hasWhiteListedPrefix(path string) bool {
 	for _, wVar := range ctx.whiteListVars {
-		if strings.HasPrefix(variable, wVar) {
+		if strings.HasPrefix(path, wVar) {
 			return true
 		}
 	}
 	return false
 }
+
+// queryPaths returns the paths of the context that are referenced by the query, the query is parsed
+// and all of its root expressions are walked, e.g. the function arguments and the multi-select elements.
+// The expressions relative to a projection, a filter or a pipe are skipped.
+// An error is returned if the parsed query cannot be read, so the query is not allowed.
+func queryPaths(query string) ([]string, error) {
+	ast, err := gojmespath.NewParser().Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// the fields of gojmespath.ASTNode are not exported, the tree is read from its printed form
+	node, err := parseASTNode(ast.PrettyPrint(0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the query %s: %v", query, err)
+	}
+
+	return node.paths()
+}
+
+// astNode is a node of the abstract syntax tree of a JMESPath query
+type astNode struct {
+	nodeType string
+	value    string
+	children []astNode
+}
+
+// paths returns the root paths of the node, an error is returned for an unknown node type
+func (node astNode) paths() ([]string, error) {
+	if path, ok := node.fieldPath(); ok {
+		return []string{path}, nil
+	}
+
+	switch node.nodeType {
+	case gojmespath.ASTCurrentNode.String(), gojmespath.ASTIdentity.String():
+		// the current node of a root expression is the whole context
+		return []string{""}, nil
+	case gojmespath.ASTSubexpression.String(), gojmespath.ASTIndexExpression.String(), gojmespath.ASTPipe.String(),
+		gojmespath.ASTProjection.String(), gojmespath.ASTValueProjection.String(), gojmespath.ASTFilterProjection.String():
+		// the right side is evaluated against the result of the left side
+		if len(node.children) == 0 {
+			return nil, fmt.Errorf("%s has no children", node.nodeType)
+		}
+		return node.children[0].paths()
+	case gojmespath.ASTFlatten.String(), gojmespath.ASTFunctionExpression.String(), gojmespath.ASTMultiSelectList.String(),
+		gojmespath.ASTMultiSelectHash.String(), gojmespath.ASTKeyValPair.String(), gojmespath.ASTComparator.String(),
+		gojmespath.ASTOrExpression.String(), gojmespath.ASTAndExpression.String(), gojmespath.ASTNotExpression.String():
+		var paths []string
+		for _, child := range node.children {
+			childPaths, err := child.paths()
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, childPaths...)
+		}
+		return paths, nil
+	case gojmespath.ASTLiteral.String(), gojmespath.ASTIndex.String(), gojmespath.ASTSlice.String(), gojmespath.ASTExpRef.String():
+		// literals, indexes, slices and expression references (&expr) do not read the context
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown node type %s", node.nodeType)
+	}
+}
+
+// fieldPath returns the dotted path of a field or a chain of fields, an index expression
+// returns the path of the indexed field
+func (node astNode) fieldPath() (string, bool) {
+	switch node.nodeType {
+	case gojmespath.ASTField.String():
+		field, err := strconv.Unquote(node.value)
+		return field, err == nil
+	case gojmespath.ASTIndexExpression.String():
+		if len(node.children) == 0 {
+			return "", false
+		}
+		return node.children[0].fieldPath()
+	case gojmespath.ASTSubexpression.String():
+		if len(node.children) != 2 {
+			return "", false
+		}
+		left, ok := node.children[0].fieldPath()
+		if !ok {
+			return "", false
+		}
+		// the right side may be a projection or a multi-select, relative to the left side
+		right, ok := node.children[1].fieldPath()
+		if !ok {
+			return left, true
+		}
+		return left + "." + right, true
+	default:
+		return "", false
+	}
+}
+
+// parseASTNode parses the output of gojmespath.ASTNode.PrettyPrint, a node is printed as
+//   <type> {
+//     value: <value>
+//     children: {
+//       <child nodes>
+//   }
+func parseASTNode(printed string) (astNode, error) {
+	parser := &astParser{lines: strings.Split(strings.TrimSuffix(printed, "\n"), "\n")}
+	node, err := parser.node(0)
+	if err != nil {
+		return astNode{}, err
+	}
+	if parser.pos != len(parser.lines) {
+		return astNode{}, fmt.Errorf("unexpected line %q", parser.lines[parser.pos])
+	}
+	return node, nil
+}
+
+type astParser struct {
+	lines []string
+	pos   int
+}
+
+func (p *astParser) peek() string {
+	if p.pos < len(p.lines) {
+		return p.lines[p.pos]
+	}
+	return ""
+}
+
+func (p *astParser) node(indent int) (astNode, error) {
+	var node astNode
+	prefix := strings.Repeat(" ", indent)
+	line := p.peek()
+	if !strings.HasPrefix(line, prefix) || !strings.HasSuffix(line, " {") {
+		return node, fmt.Errorf("unexpected line %q", line)
+	}
+	node.nodeType = strings.TrimSuffix(strings.TrimPrefix(line, prefix), " {")
+	if node.nodeType == "" || strings.ContainsAny(node.nodeType, " {}") {
+		return node, fmt.Errorf("unexpected line %q", line)
+	}
+	p.pos++
+
+	if value := strings.TrimPrefix(p.peek(), prefix+"  value: "); value != p.peek() {
+		node.value = value
+		p.pos++
+	}
+
+	if p.peek() == prefix+"  children: {" {
+		p.pos++
+		for p.pos < len(p.lines) && p.peek() != prefix+"}" {
+			child, err := p.node(indent + 4)
+			if err != nil {
+				return node, err
+			}
+			node.children = append(node.children, child)
+		}
+	}
+
+	if p.peek() != prefix+"}" {
+		return node, fmt.Errorf("unexpected line %q", p.peek())
+	}
+	p.pos++
+	return node, nil
+}
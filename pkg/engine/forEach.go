@@ -0,0 +1,238 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/mutate"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"github.com/nirmata/kyverno/pkg/engine/validate"
+	"github.com/nirmata/kyverno/pkg/engine/variables"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// evaluateList returns the elements selected by the JMESPath expression of a foreach block
+func evaluateList(jmesPath string, ctx context.EvalInterface) ([]interface{}, error) {
+	result, err := ctx.Query(jmesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// a missing list has no elements
+	if result == nil {
+		return nil, nil
+	}
+
+	elements, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a list, found %T", result)
+	}
+	return elements, nil
+}
+
+// loadResource replaces request.object in the context with the resource,
+// the old and new resources of an update are validated separately
+func loadResource(ctx context.Interface, resource unstructured.Unstructured) error {
+	// the object is removed first as the context merges the data
+	if err := ctx.AddJSON([]byte(`{"request":{"object":null}}`)); err != nil {
+		return err
+	}
+
+	raw, err := resource.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return ctx.AddResource(raw)
+}
+
+// validateForEach applies the foreach validation of the rule to each element of the list,
+// the list is evaluated against the resource and the validation stops at the first element that fails
+func validateForEach(log logr.Logger, ctx context.Interface, resource unstructured.Unstructured, rule kyverno.Rule) (resp response.RuleResponse) {
+	startTime := time.Now()
+	logger := log.WithValues("rule", rule.Name)
+	logger.V(4).Info("start processing rule", "startTime", startTime)
+	resp.Name = rule.Name
+	resp.Type = utils.Validation.String()
+	defer func() {
+		resp.RuleStats.ProcessingTime = time.Since(startTime)
+		logger.V(4).Info("finished processing rule", "processingTime", resp.RuleStats.ProcessingTime.String())
+	}()
+
+	// the resource and the elements are only available while the rule is processed
+	ctx.Checkpoint()
+	defer ctx.Restore()
+
+	if err := loadResource(ctx, resource); err != nil {
+		resp.Success = false
+		resp.Message = fmt.Sprintf("failed to add the resource to the context: %v", err)
+		return resp
+	}
+
+	foreach := rule.Validation.ForEachValidation
+	elements, err := evaluateList(foreach.List, ctx)
+	if err != nil {
+		resp.Success = false
+		resp.Message = fmt.Sprintf("failed to evaluate list %s: %v", foreach.List, err)
+		return resp
+	}
+
+	var applied int
+	for idx, element := range elements {
+		if err := ctx.AddElement(element, idx); err != nil {
+			resp.Success = false
+			resp.Message = fmt.Sprintf("failed to add element %d to the context: %v", idx, err)
+			return resp
+		}
+
+		if !variables.EvaluateConditions(logger, ctx, copyConditions(foreach.Preconditions)) {
+			logger.V(4).Info("element fails the preconditions", "elementIndex", idx)
+			continue
+		}
+
+		if err := validateElement(logger, ctx, element, foreach); err != nil {
+			if err == validate.ErrGlobalAnchorNotSatisfied {
+				logger.V(4).Info("element does not satisfy the global anchors", "elementIndex", idx)
+				continue
+			}
+
+			// the message can refer to the element, so it is substituted before the element is removed
+			message := rule.Validation.Message
+			if substituted, err := substituteString(logger, ctx, message); err == nil {
+				message = substituted
+			}
+
+			resp.Success = false
+			resp.Message = fmt.Sprintf("Validation error: %s; Validation rule %s failed for element %d: %v", message, rule.Name, idx, err)
+			return resp
+		}
+		applied++
+	}
+
+	logger.V(4).Info("successfully processed rule", "elements", applied)
+	resp.Success = true
+	resp.Message = fmt.Sprintf("Validation rule '%s' succeeded for %d elements.", rule.Name, applied)
+	return resp
+}
+
+// validateElement validates a single element with the pattern, anyPattern or deny conditions of the foreach block
+func validateElement(log logr.Logger, ctx context.EvalInterface, element interface{}, foreach *kyverno.ForEachValidation) error {
+	if foreach.Pattern != nil {
+		pattern, err := variables.SubstituteVars(log, ctx, foreach.Pattern)
+		if err != nil {
+			return err
+		}
+
+		if path, err := validate.ValidateResourceWithPattern(log, element, pattern); err != nil {
+			if err == validate.ErrGlobalAnchorNotSatisfied {
+				return err
+			}
+			return fmt.Errorf("validation failed at path %s", path)
+		}
+		return nil
+	}
+
+	if len(foreach.AnyPattern) != 0 {
+		var failedPatterns []string
+		for idx, pattern := range foreach.AnyPattern {
+			pattern, err := variables.SubstituteVars(log, ctx, pattern)
+			if err != nil {
+				return err
+			}
+
+			_, err = validate.ValidateResourceWithPattern(log, element, pattern)
+			if err == nil {
+				return nil
+			}
+			if err != validate.ErrGlobalAnchorNotSatisfied {
+				failedPatterns = append(failedPatterns, fmt.Sprintf("anyPattern[%d]", idx))
+			}
+		}
+		if len(failedPatterns) == 0 {
+			return validate.ErrGlobalAnchorNotSatisfied
+		}
+		return fmt.Errorf("validation failed for %v", failedPatterns)
+	}
+
+	if foreach.Deny != nil {
+		denyConditions := copyConditions(foreach.Deny.Conditions)
+		denied, messages := variables.EvaluateConditionsWithMessages(log, ctx, denyConditions)
+		if len(denyConditions) == 0 || denied {
+			if len(messages) > 0 {
+				return fmt.Errorf("denied: %s", strings.Join(messages, "; "))
+			}
+			return fmt.Errorf("denied")
+		}
+	}
+	return nil
+}
+
+// mutateForEach applies the foreach patches of the rule for each element of the list,
+// the patches are applied in order and the resource is left unchanged if any of them fails
+func mutateForEach(log logr.Logger, ctx context.Interface, rule kyverno.Rule, resource unstructured.Unstructured) (resp response.RuleResponse, patchedResource unstructured.Unstructured) {
+	startTime := time.Now()
+	logger := log.WithValues("rule", rule.Name)
+	logger.V(4).Info("start processing rule", "startTime", startTime)
+	resp.Name = rule.Name
+	resp.Type = utils.Mutation.String()
+	defer func() {
+		resp.RuleStats.ProcessingTime = time.Since(startTime)
+		logger.V(4).Info("finished processing rule", "processingTime", resp.RuleStats.ProcessingTime.String())
+	}()
+
+	foreach := rule.Mutation.ForEachMutation
+	elements, err := evaluateList(foreach.List, ctx)
+	if err != nil {
+		resp.Success = false
+		resp.Message = fmt.Sprintf("failed to evaluate list %s: %v", foreach.List, err)
+		return resp, resource
+	}
+
+	ctx.Checkpoint()
+	defer ctx.Restore()
+
+	patchedResource = resource
+	var applied int
+	for idx, element := range elements {
+		if err := ctx.AddElement(element, idx); err != nil {
+			resp.Success = false
+			resp.Message = fmt.Sprintf("failed to add element %d to the context: %v", idx, err)
+			return resp, resource
+		}
+
+		if !variables.EvaluateConditions(logger, ctx, copyConditions(foreach.Preconditions)) {
+			logger.V(4).Info("element fails the preconditions", "elementIndex", idx)
+			continue
+		}
+
+		mutation := &kyverno.Mutation{PatchStrategicMerge: foreach.PatchStrategicMerge}
+		if foreach.PatchesJSON6902 != "" {
+			// JSON patches usually refer to the element index in their paths
+			if mutation.PatchesJSON6902, err = substituteString(logger, ctx, foreach.PatchesJSON6902); err != nil {
+				resp.Success = false
+				resp.Message = fmt.Sprintf("failed to mutate element %d: %v", idx, err)
+				return resp, resource
+			}
+		}
+
+		mutateHandler := mutate.CreateMutateHandler(rule.Name, mutation, patchedResource, ctx, logger)
+		elementResp, elementResource := mutateHandler.Handle()
+		if !elementResp.Success {
+			resp.Success = false
+			resp.Message = fmt.Sprintf("failed to mutate element %d: %s", idx, elementResp.Message)
+			return resp, resource
+		}
+
+		patchedResource = elementResource
+		resp.Patches = append(resp.Patches, elementResp.Patches...)
+		applied++
+	}
+
+	resp.Success = true
+	resp.Message = fmt.Sprintf("successfully mutated %d elements", applied)
+	return resp, patchedResource
+}
@@ -0,0 +1,205 @@
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var forEachPod = []byte(`{
+	"apiVersion": "v1",
+	"kind": "Pod",
+	"metadata": {
+		"name": "nginx"
+	},
+	"spec": {
+		"containers": [
+			{
+				"name": "nginx",
+				"image": "gcr.io/nginx:1.19"
+			},
+			{
+				"name": "sidecar",
+				"image": "docker.io/envoy:latest"
+			}
+		]
+	}
+}`)
+
+func newForEachPolicyContext(t *testing.T, rawPolicy []byte) PolicyContext {
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal(rawPolicy, &policy))
+
+	resource, err := utils.ConvertToUnstructured(forEachPod)
+	assert.NilError(t, err)
+
+	ctx := context.NewContext()
+	assert.NilError(t, ctx.AddResource(forEachPod))
+
+	return PolicyContext{
+		Policy:      policy,
+		NewResource: *resource,
+		Context:     ctx,
+	}
+}
+
+func Test_ValidateForEach(t *testing.T) {
+	testcases := []struct {
+		name    string
+		foreach string
+		success bool
+		message string
+	}{
+		{
+			name:    "pattern fails for the second element",
+			foreach: `{"list": "request.object.spec.containers", "pattern": {"image": "gcr.io/*"}}`,
+			success: false,
+			message: "failed for element 1",
+		},
+		{
+			name:    "preconditions skip the second element",
+			foreach: `{"list": "request.object.spec.containers", "preconditions": [{"key": "{{element.name}}", "operator": "Equals", "value": "nginx"}], "pattern": {"image": "gcr.io/*"}}`,
+			success: true,
+		},
+		{
+			name:    "deny conditions use the element",
+			foreach: `{"list": "request.object.spec.containers", "deny": {"conditions": [{"key": "{{ends_with(element.image, ':latest')}}", "operator": "Equals", "value": true}]}}`,
+			success: false,
+			message: "failed for element 1",
+		},
+		{
+			name:    "anyPattern",
+			foreach: `{"list": "request.object.spec.containers", "anyPattern": [{"image": "gcr.io/*"}, {"image": "docker.io/*"}]}`,
+			success: true,
+		},
+		{
+			name:    "missing list",
+			foreach: `{"list": "request.object.spec.initContainers", "pattern": {"image": "gcr.io/*"}}`,
+			success: true,
+		},
+		{
+			name:    "not a list",
+			foreach: `{"list": "request.object.metadata.name", "pattern": {"image": "gcr.io/*"}}`,
+			success: false,
+			message: "expected a list",
+		},
+	}
+
+	for _, tc := range testcases {
+		rawPolicy := []byte(`{
+			"apiVersion": "kyverno.io/v1",
+			"kind": "ClusterPolicy",
+			"metadata": {"name": "check-images"},
+			"spec": {
+				"rules": [
+					{
+						"name": "check-images",
+						"match": {"resources": {"kinds": ["Pod"]}},
+						"validate": {
+							"message": "image {{element.image}} is not allowed",
+							"foreach": ` + tc.foreach + `
+						}
+					}
+				]
+			}
+		}`)
+
+		er := Validate(newForEachPolicyContext(t, rawPolicy))
+		assert.Equal(t, len(er.PolicyResponse.Rules), 1, tc.name)
+		rule := er.PolicyResponse.Rules[0]
+		assert.Equal(t, rule.Success, tc.success, tc.name)
+		assert.Assert(t, strings.Contains(rule.Message, tc.message), "%s: %s", tc.name, rule.Message)
+		if !tc.success && tc.message == "failed for element 1" {
+			assert.Assert(t, strings.Contains(rule.Message, "image docker.io/envoy:latest is not allowed"), "%s: %s", tc.name, rule.Message)
+		}
+	}
+}
+
+func Test_ValidateForEach_Update(t *testing.T) {
+	rawPolicy := []byte(`{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {"name": "check-images"},
+		"spec": {
+			"rules": [
+				{
+					"name": "check-images",
+					"match": {"resources": {"kinds": ["Pod"]}},
+					"validate": {
+						"message": "image {{element.image}} is not allowed",
+						"foreach": {"list": "request.object.spec.containers", "pattern": {"image": "gcr.io/*"}}
+					}
+				}
+			]
+		}
+	}`)
+
+	oldPod := []byte(`{
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {"name": "nginx"},
+		"spec": {"containers": [{"name": "nginx", "image": "gcr.io/nginx:1.19"}]}
+	}`)
+
+	// the update adds a container with an image that is not allowed
+	policyContext := newForEachPolicyContext(t, rawPolicy)
+	oldResource, err := utils.ConvertToUnstructured(oldPod)
+	assert.NilError(t, err)
+	policyContext.OldResource = *oldResource
+
+	er := Validate(policyContext)
+	assert.Equal(t, len(er.PolicyResponse.Rules), 1)
+	assert.Assert(t, !er.PolicyResponse.Rules[0].Success)
+	assert.Assert(t, strings.Contains(er.PolicyResponse.Rules[0].Message, "image docker.io/envoy:latest is not allowed"), er.PolicyResponse.Rules[0].Message)
+
+	// the context is not changed by the validation of the old resource
+	image, err := policyContext.Context.Query("request.object.spec.containers[1].image")
+	assert.NilError(t, err)
+	assert.Equal(t, image, "docker.io/envoy:latest")
+}
+
+func Test_MutateForEach(t *testing.T) {
+	rawPolicy := []byte(`{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {"name": "set-pull-policy"},
+		"spec": {
+			"rules": [
+				{
+					"name": "set-pull-policy",
+					"match": {"resources": {"kinds": ["Pod"]}},
+					"mutate": {
+						"foreach": {
+							"list": "request.object.spec.containers",
+							"preconditions": [{"key": "{{ends_with(element.image, ':latest')}}", "operator": "Equals", "value": true}],
+							"patchesJson6902": "- op: add\n  path: /spec/containers/{{elementIndex}}/imagePullPolicy\n  value: Always"
+						}
+					}
+				}
+			]
+		}
+	}`)
+
+	policyContext := newForEachPolicyContext(t, rawPolicy)
+	er := Mutate(policyContext)
+	assert.Equal(t, len(er.PolicyResponse.Rules), 1)
+	assert.Assert(t, er.PolicyResponse.Rules[0].Success, er.PolicyResponse.Rules[0].Message)
+	assert.Equal(t, len(er.PolicyResponse.Rules[0].Patches), 1)
+
+	containers, _, err := unstructured.NestedSlice(er.PatchedResource.Object, "spec", "containers")
+	assert.NilError(t, err)
+	_, ok := containers[0].(map[string]interface{})["imagePullPolicy"]
+	assert.Assert(t, !ok)
+	assert.Equal(t, containers[1].(map[string]interface{})["imagePullPolicy"], "Always")
+
+	// the elements are removed from the context after the rule is processed
+	result, err := policyContext.Context.Query("element")
+	assert.NilError(t, err)
+	assert.Assert(t, result == nil)
+}
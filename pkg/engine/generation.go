@@ -8,6 +8,7 @@ import (
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	"github.com/nirmata/kyverno/pkg/engine/context"
 	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
 	"github.com/nirmata/kyverno/pkg/engine/variables"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -25,18 +26,25 @@ func Generate(policyContext PolicyContext) (resp response.EngineResponse) {
 
 	logger := log.Log.WithName("Generate").WithValues("policy", policy.Name, "kind", resource.GetKind(), "namespace", resource.GetNamespace(), "name", resource.GetName())
 
-	return filterRules(policy, resource, admissionInfo, ctx, logger, policyContext.ExcludeGroupRole)
+	return filterRules(policy, resource, admissionInfo, ctx, logger, policyContext.ExcludeGroupRole, policyContext)
 }
 
-func filterRule(rule kyverno.Rule, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo, ctx context.EvalInterface, log logr.Logger, excludeGroupRole []string) *response.RuleResponse {
+func filterRule(rule kyverno.Rule, policyNamespace string, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo, ctx context.Interface, log logr.Logger, excludeGroupRole []string, policyContext PolicyContext) *response.RuleResponse {
 	if !rule.HasGenerate() {
 		return nil
 	}
 
 	startTime := time.Now()
-	if err := MatchesResourceDescription(resource, rule, admissionInfo, excludeGroupRole); err != nil {
+	if err := MatchesResourceDescription(resource, rule, admissionInfo, excludeGroupRole, policyNamespace, policyContext.NamespaceLabels); err != nil {
 		return nil
 	}
+
+	if err := LoadContext(log, rule.Context, policyContext); err != nil {
+		log.V(4).Info("failed to load context", "rule", rule.Name, "reason", err.Error())
+		ruleResp := contextFailureResponse(rule, utils.Generation, err)
+		return &ruleResp
+	}
+
 	// operate on the copy of the conditions, as we perform variable substitution
 	copyConditions := copyConditions(rule.Conditions)
 
@@ -56,10 +64,11 @@ func filterRule(rule kyverno.Rule, resource unstructured.Unstructured, admission
 	}
 }
 
-func filterRules(policy kyverno.ClusterPolicy, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo, ctx context.EvalInterface, log logr.Logger, excludeGroupRole []string) response.EngineResponse {
+func filterRules(policy kyverno.ClusterPolicy, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo, ctx context.Interface, log logr.Logger, excludeGroupRole []string, policyContext PolicyContext) response.EngineResponse {
 	resp := response.EngineResponse{
 		PolicyResponse: response.PolicyResponse{
-			Policy: policy.Name,
+			Policy:          policy.Name,
+			PolicyNamespace: policy.Namespace,
 			Resource: response.ResourceSpec{
 				Kind:      resource.GetKind(),
 				Name:      resource.GetName(),
@@ -68,7 +77,7 @@ func filterRules(policy kyverno.ClusterPolicy, resource unstructured.Unstructure
 		},
 	}
 	for _, rule := range policy.Spec.Rules {
-		if ruleResp := filterRule(rule, resource, admissionInfo, ctx, log, excludeGroupRole); ruleResp != nil {
+		if ruleResp := filterRule(rule, policy.Namespace, resource, admissionInfo, ctx, log, excludeGroupRole, policyContext); ruleResp != nil {
 			resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, *ruleResp)
 		}
 	}
@@ -0,0 +1,522 @@
+package jmespath
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	gojmespath "github.com/jmespath/go-jmespath"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// function names
+const (
+	regexMatch    = "regex_match"
+	regexReplace  = "regex_replace"
+	split         = "split"
+	toUpper       = "to_upper"
+	toLower       = "to_lower"
+	trim          = "trim"
+	base64Encode  = "base64_encode"
+	base64Decode  = "base64_decode"
+	parseJSON     = "parse_json"
+	parseYAML     = "parse_yaml"
+	labelMatch    = "label_match"
+	timeNow       = "time_now"
+	timeSince     = "time_since"
+	divide        = "divide"
+	multiply      = "multiply"
+	parseImageRef = "parse_image"
+)
+
+const errorPrefix = "JMESPath function '%s': "
+const invalidArgumentTypeError = errorPrefix + "argument #%d is not of type %s"
+const genericError = errorPrefix + "%s"
+
+// New compiles the JMESPath query and registers the custom functions
+func New(query string) (*gojmespath.JMESPath, error) {
+	jp, err := gojmespath.Compile(query)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, function := range GetFunctions() {
+		jp.Register(function)
+	}
+
+	return jp, nil
+}
+
+// GetFunctions returns the custom functions available to JMESPath queries
+func GetFunctions() []*gojmespath.FunctionEntry {
+	return []*gojmespath.FunctionEntry{
+		{
+			Name: regexMatch,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+				{Types: []gojmespath.JpType{gojmespath.JpString, gojmespath.JpNumber}},
+			},
+			Handler: jpRegexMatch,
+		},
+		{
+			Name: regexReplace,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+				{Types: []gojmespath.JpType{gojmespath.JpString, gojmespath.JpNumber}},
+				{Types: []gojmespath.JpType{gojmespath.JpString, gojmespath.JpNumber}},
+			},
+			Handler: jpRegexReplace,
+		},
+		{
+			Name: split,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+			},
+			Handler: jpSplit,
+		},
+		{
+			Name: toUpper,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+			},
+			Handler: jpToUpper,
+		},
+		{
+			Name: toLower,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+			},
+			Handler: jpToLower,
+		},
+		{
+			Name: trim,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+			},
+			Handler: jpTrim,
+		},
+		{
+			Name: base64Encode,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+			},
+			Handler: jpBase64Encode,
+		},
+		{
+			Name: base64Decode,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+			},
+			Handler: jpBase64Decode,
+		},
+		{
+			Name: parseJSON,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+			},
+			Handler: jpParseJSON,
+		},
+		{
+			Name: parseYAML,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+			},
+			Handler: jpParseYAML,
+		},
+		{
+			Name: labelMatch,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpObject}},
+				{Types: []gojmespath.JpType{gojmespath.JpObject}},
+			},
+			Handler: jpLabelMatch,
+		},
+		{
+			Name:    timeNow,
+			Handler: jpTimeNow,
+		},
+		{
+			Name: timeSince,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+			},
+			Handler: jpTimeSince,
+		},
+		{
+			Name: divide,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString, gojmespath.JpNumber}},
+				{Types: []gojmespath.JpType{gojmespath.JpString, gojmespath.JpNumber}},
+			},
+			Handler: jpDivide,
+		},
+		{
+			Name: multiply,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString, gojmespath.JpNumber}},
+				{Types: []gojmespath.JpType{gojmespath.JpString, gojmespath.JpNumber}},
+			},
+			Handler: jpMultiply,
+		},
+		{
+			Name: parseImageRef,
+			Arguments: []gojmespath.ArgSpec{
+				{Types: []gojmespath.JpType{gojmespath.JpString}},
+			},
+			Handler: jpParseImage,
+		},
+	}
+}
+
+func jpRegexMatch(arguments []interface{}) (interface{}, error) {
+	regex, err := validateArg(regexMatch, arguments, 0, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+
+	src := ifaceToString(arguments[1])
+	return regexp.MatchString(regex, src)
+}
+
+func jpRegexReplace(arguments []interface{}) (interface{}, error) {
+	regex, err := validateArg(regexReplace, arguments, 0, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+
+	reg, err := regexp.Compile(regex)
+	if err != nil {
+		return nil, fmt.Errorf(genericError, regexReplace, err.Error())
+	}
+
+	src := ifaceToString(arguments[1])
+	replacement := ifaceToString(arguments[2])
+	return reg.ReplaceAllString(src, replacement), nil
+}
+
+func jpSplit(arguments []interface{}) (interface{}, error) {
+	str, err := validateArg(split, arguments, 0, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+
+	sep, err := validateArg(split, arguments, 1, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(str, sep)
+	result := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		result = append(result, part)
+	}
+	return result, nil
+}
+
+func jpToUpper(arguments []interface{}) (interface{}, error) {
+	str, err := validateArg(toUpper, arguments, 0, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ToUpper(str), nil
+}
+
+func jpToLower(arguments []interface{}) (interface{}, error) {
+	str, err := validateArg(toLower, arguments, 0, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ToLower(str), nil
+}
+
+func jpTrim(arguments []interface{}) (interface{}, error) {
+	str, err := validateArg(trim, arguments, 0, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+
+	cutset, err := validateArg(trim, arguments, 1, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Trim(str, cutset), nil
+}
+
+func jpBase64Encode(arguments []interface{}) (interface{}, error) {
+	str, err := validateArg(base64Encode, arguments, 0, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.EncodeToString([]byte(str)), nil
+}
+
+func jpBase64Decode(arguments []interface{}) (interface{}, error) {
+	str, err := validateArg(base64Decode, arguments, 0, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf(genericError, base64Decode, err.Error())
+	}
+	return string(decoded), nil
+}
+
+func jpParseJSON(arguments []interface{}) (interface{}, error) {
+	str, err := validateArg(parseJSON, arguments, 0, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(str), &data); err != nil {
+		return nil, fmt.Errorf(genericError, parseJSON, err.Error())
+	}
+	return data, nil
+}
+
+func jpParseYAML(arguments []interface{}) (interface{}, error) {
+	str, err := validateArg(parseYAML, arguments, 0, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, err := yaml.YAMLToJSON([]byte(str))
+	if err != nil {
+		return nil, fmt.Errorf(genericError, parseYAML, err.Error())
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(jsonData, &data); err != nil {
+		return nil, fmt.Errorf(genericError, parseYAML, err.Error())
+	}
+	return data, nil
+}
+
+// jpLabelMatch checks if all the labels of the selector are set on the object labels
+func jpLabelMatch(arguments []interface{}) (interface{}, error) {
+	selector, ok := arguments[0].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf(invalidArgumentTypeError, labelMatch, 1, "object")
+	}
+
+	objectLabels, ok := arguments[1].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf(invalidArgumentTypeError, labelMatch, 2, "object")
+	}
+
+	selectorSet := labels.Set{}
+	for k, v := range selector {
+		selectorSet[k] = ifaceToString(v)
+	}
+
+	objectSet := labels.Set{}
+	for k, v := range objectLabels {
+		objectSet[k] = ifaceToString(v)
+	}
+
+	return labels.SelectorFromSet(selectorSet).Matches(objectSet), nil
+}
+
+func jpTimeNow(arguments []interface{}) (interface{}, error) {
+	return time.Now().UTC().Format(time.RFC3339), nil
+}
+
+// jpTimeSince returns the duration between two timestamps, the layout defaults to RFC3339
+// and the second timestamp defaults to the current time
+func jpTimeSince(arguments []interface{}) (interface{}, error) {
+	layout, err := validateArg(timeSince, arguments, 0, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	ts1, err := validateArg(timeSince, arguments, 1, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+
+	ts2, err := validateArg(timeSince, arguments, 2, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+
+	t1, err := time.Parse(layout, ts1)
+	if err != nil {
+		return nil, fmt.Errorf(genericError, timeSince, err.Error())
+	}
+
+	t2 := time.Now()
+	if ts2 != "" {
+		if t2, err = time.Parse(layout, ts2); err != nil {
+			return nil, fmt.Errorf(genericError, timeSince, err.Error())
+		}
+	}
+
+	return t2.Sub(t1).String(), nil
+}
+
+// jpDivide divides numbers and quantities, dividing two quantities returns a number
+func jpDivide(arguments []interface{}) (interface{}, error) {
+	op1, op1IsNumber, err := parseOperand(divide, arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	op2, op2IsNumber, err := parseOperand(divide, arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if op2.IsZero() {
+		return nil, fmt.Errorf(genericError, divide, "division by zero")
+	}
+
+	result := float64(op1.MilliValue()) / float64(op2.MilliValue())
+	if op1IsNumber || !op2IsNumber {
+		return result, nil
+	}
+	return formatQuantity(result, op1.Format), nil
+}
+
+// jpMultiply multiplies numbers and quantities, at most one of the operands can be a quantity
+func jpMultiply(arguments []interface{}) (interface{}, error) {
+	op1, op1IsNumber, err := parseOperand(multiply, arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	op2, op2IsNumber, err := parseOperand(multiply, arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	result := float64(op1.MilliValue()) / 1000 * float64(op2.MilliValue()) / 1000
+	switch {
+	case op1IsNumber && op2IsNumber:
+		return result, nil
+	case op1IsNumber:
+		return formatQuantity(result, op2.Format), nil
+	case op2IsNumber:
+		return formatQuantity(result, op1.Format), nil
+	default:
+		return nil, fmt.Errorf(genericError, multiply, "cannot multiply two quantities")
+	}
+}
+
+// jpParseImage splits an image reference into registry, path, name, tag and digest
+func jpParseImage(arguments []interface{}) (interface{}, error) {
+	image, err := validateArg(parseImageRef, arguments, 0, gojmespath.JpString)
+	if err != nil {
+		return nil, err
+	}
+
+	if image == "" {
+		return nil, fmt.Errorf(genericError, parseImageRef, "empty image reference")
+	}
+
+	var digest string
+	if idx := strings.Index(image, "@"); idx != -1 {
+		image, digest = image[:idx], image[idx+1:]
+	}
+
+	registry := "docker.io"
+	path := image
+	if idx := strings.Index(image, "/"); idx != -1 {
+		host := image[:idx]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			registry, path = host, image[idx+1:]
+		}
+	}
+
+	var tag string
+	if idx := strings.LastIndex(path, ":"); idx != -1 && !strings.Contains(path[idx:], "/") {
+		path, tag = path[:idx], path[idx+1:]
+	}
+	if tag == "" && digest == "" {
+		tag = "latest"
+	}
+
+	name := path
+	if idx := strings.LastIndex(path, "/"); idx != -1 {
+		name = path[idx+1:]
+	}
+
+	return map[string]interface{}{
+		"registry": registry,
+		"path":     path,
+		"name":     name,
+		"tag":      tag,
+		"digest":   digest,
+	}, nil
+}
+
+func validateArg(f string, arguments []interface{}, index int, expectedType gojmespath.JpType) (string, error) {
+	if index >= len(arguments) {
+		return "", fmt.Errorf(genericError, f, "missing argument")
+	}
+
+	arg, ok := arguments[index].(string)
+	if !ok {
+		return "", fmt.Errorf(invalidArgumentTypeError, f, index+1, expectedType)
+	}
+	return arg, nil
+}
+
+// parseOperand converts a number or a quantity string to a quantity,
+// it returns true if the argument is a plain number
+func parseOperand(f string, arguments []interface{}, index int) (apiresource.Quantity, bool, error) {
+	switch typedArg := arguments[index].(type) {
+	case float64:
+		quantity, err := apiresource.ParseQuantity(strconv.FormatFloat(typedArg, 'f', -1, 64))
+		if err != nil {
+			return apiresource.Quantity{}, false, fmt.Errorf(genericError, f, err.Error())
+		}
+		return quantity, true, nil
+	case string:
+		if number, err := strconv.ParseFloat(typedArg, 64); err == nil {
+			quantity, _ := apiresource.ParseQuantity(strconv.FormatFloat(number, 'f', -1, 64))
+			return quantity, true, nil
+		}
+		quantity, err := apiresource.ParseQuantity(typedArg)
+		if err != nil {
+			return apiresource.Quantity{}, false, fmt.Errorf(genericError, f, err.Error())
+		}
+		return quantity, false, nil
+	default:
+		return apiresource.Quantity{}, false, fmt.Errorf(invalidArgumentTypeError, f, index+1, "number or quantity")
+	}
+}
+
+// formatQuantity converts the value to a quantity string using the given format,
+// values are rounded to milli units
+func formatQuantity(value float64, format apiresource.Format) string {
+	quantity := apiresource.NewMilliQuantity(int64(value*1000+0.5), format)
+	return quantity.String()
+}
+
+func ifaceToString(iface interface{}) string {
+	switch typedValue := iface.(type) {
+	case string:
+		return typedValue
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(typedValue)
+	default:
+		return fmt.Sprint(iface)
+	}
+}
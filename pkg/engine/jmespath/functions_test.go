@@ -0,0 +1,89 @@
+package jmespath
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func Test_CustomFunctions(t *testing.T) {
+	testCases := []struct {
+		query    string
+		data     interface{}
+		expected interface{}
+	}{
+		{query: "regex_match('^nginx:[0-9.]+$', image)", data: map[string]interface{}{"image": "nginx:1.19"}, expected: true},
+		{query: "regex_match('^[0-9]+$', `8080`)", expected: true},
+		{query: "regex_replace('-[a-z0-9]+$', 'nginx-5d8f', '')", expected: "nginx"},
+		{query: "split('a,b,c', ',')", expected: []interface{}{"a", "b", "c"}},
+		{query: "to_upper('abc')", expected: "ABC"},
+		{query: "to_lower('ABC')", expected: "abc"},
+		{query: "trim('  abc ', ' ')", expected: "abc"},
+		{query: "base64_encode('kyverno')", expected: "a3l2ZXJubw=="},
+		{query: "base64_decode('a3l2ZXJubw==')", expected: "kyverno"},
+		{query: "parse_json('{\"a\": [1, 2]}').a[1]", expected: 2.0},
+		{query: "parse_yaml('a:\n  b: c').a.b", expected: "c"},
+		{query: "label_match(selector, labels)",
+			data: map[string]interface{}{
+				"selector": map[string]interface{}{"app": "nginx"},
+				"labels":   map[string]interface{}{"app": "nginx", "tier": "web"},
+			},
+			expected: true},
+		{query: "label_match(selector, labels)",
+			data: map[string]interface{}{
+				"selector": map[string]interface{}{"app": "nginx", "tier": "db"},
+				"labels":   map[string]interface{}{"app": "nginx", "tier": "web"},
+			},
+			expected: false},
+		{query: "time_since('', '2021-01-01T00:00:00Z', '2021-01-02T01:00:00Z')", expected: "25h0m0s"},
+		{query: "divide('1Gi', '256Mi')", expected: 4.0},
+		{query: "divide('2Gi', `4`)", expected: "512Mi"},
+		{query: "divide(`10`, `4`)", expected: 2.5},
+		{query: "multiply('250m', `4`)", expected: "1"},
+		{query: "multiply(`3`, '512Mi')", expected: "1536Mi"},
+		{query: "multiply(`3`, `1.5`)", expected: 4.5},
+		{query: "parse_image('nginx').registry", expected: "docker.io"},
+		{query: "parse_image('nginx').tag", expected: "latest"},
+		{query: "parse_image('localhost:5000/team/app:v1').registry", expected: "localhost:5000"},
+		{query: "parse_image('localhost:5000/team/app:v1').path", expected: "team/app"},
+		{query: "parse_image('localhost:5000/team/app:v1').name", expected: "app"},
+		{query: "parse_image('localhost:5000/team/app:v1').tag", expected: "v1"},
+		{query: "parse_image('gcr.io/app@sha256:abcd').digest", expected: "sha256:abcd"},
+		{query: "parse_image('gcr.io/app@sha256:abcd').tag", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		jp, err := New(tc.query)
+		assert.NilError(t, err, tc.query)
+
+		result, err := jp.Search(tc.data)
+		assert.NilError(t, err, tc.query)
+		assert.DeepEqual(t, result, tc.expected)
+	}
+}
+
+func Test_CustomFunctionErrors(t *testing.T) {
+	queries := []string{
+		"base64_decode('not-base64!')",
+		"parse_json('{')",
+		"divide('1Gi', `0`)",
+		"multiply('1Gi', '2Gi')",
+		"time_since('', 'yesterday', '')",
+	}
+
+	for _, query := range queries {
+		jp, err := New(query)
+		assert.NilError(t, err, query)
+
+		_, err = jp.Search(nil)
+		assert.Assert(t, err != nil, query)
+	}
+}
+
+func Test_TimeNow(t *testing.T) {
+	jp, err := New("time_since('', time_now(), '')")
+	assert.NilError(t, err)
+
+	_, err = jp.Search(nil)
+	assert.NilError(t, err)
+}
@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/jmespath"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"github.com/nirmata/kyverno/pkg/engine/variables"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+)
+
+// defaultAPICallTimeout is used for API calls when the policy context does not set a timeout
+const defaultAPICallTimeout = 10 * time.Second
+
+// LoadContext loads the data from the rule context entries into the JSON context
+func LoadContext(logger logr.Logger, contextEntries []kyverno.ContextEntry, policyContext PolicyContext) error {
+	if len(contextEntries) == 0 {
+		return nil
+	}
+
+	ctx := policyContext.Context
+	for _, entry := range contextEntries {
+		var data []byte
+		var err error
+		switch {
+		case entry.ConfigMap != nil:
+			data, err = loadConfigMap(logger, entry, policyContext.ConfigMapLister, ctx)
+		case entry.APICall != nil:
+			data, err = loadAPIData(logger, entry, policyContext, ctx)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.AddJSON(data); err != nil {
+			return fmt.Errorf("failed to add context entry %s: %v", entry.Name, err)
+		}
+	}
+	return nil
+}
+
+// loadAPIData returns the response of the API call as JSON, stored under the name of the context entry,
+// the responses are cached in the context so that each URL path is only requested once per admission request
+func loadAPIData(logger logr.Logger, entry kyverno.ContextEntry, policyContext PolicyContext, ctx context.Interface) ([]byte, error) {
+	if policyContext.Client == nil {
+		return nil, fmt.Errorf("client is not initialized")
+	}
+
+	urlPath, err := substituteString(logger, ctx, entry.APICall.URLPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to substitute variables in urlPath %s: %v", entry.APICall.URLPath, err)
+	}
+
+	raw, ok := ctx.GetAPICallResult(urlPath)
+	if !ok {
+		timeout := policyContext.APICallTimeout
+		if timeout == 0 {
+			timeout = defaultAPICallTimeout
+		}
+
+		if raw, err = policyContext.Client.RawAbsPath(urlPath, timeout); err != nil {
+			return nil, fmt.Errorf("failed to call API %s: %v", urlPath, err)
+		}
+		ctx.AddAPICallResult(urlPath, raw)
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode the response of API %s: %v", urlPath, err)
+	}
+
+	if entry.APICall.JMESPath != "" {
+		jmesPath, err := substituteString(logger, ctx, entry.APICall.JMESPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to substitute variables in jmesPath %s: %v", entry.APICall.JMESPath, err)
+		}
+
+		jp, err := jmespath.New(jmesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse jmesPath %s: %v", jmesPath, err)
+		}
+
+		if result, err = jp.Search(result); err != nil {
+			return nil, fmt.Errorf("failed to apply jmesPath %s on the response of API %s: %v", jmesPath, urlPath, err)
+		}
+	}
+
+	return json.Marshal(map[string]interface{}{entry.Name: result})
+}
+
+// loadConfigMap returns the configmap as JSON, stored under the name of the context entry
+func loadConfigMap(logger logr.Logger, entry kyverno.ContextEntry, cmLister listerv1.ConfigMapLister, ctx context.EvalInterface) ([]byte, error) {
+	if cmLister == nil {
+		return nil, fmt.Errorf("configmap lister is not initialized")
+	}
+
+	// the name and the namespace of the configmap can contain variables
+	name, err := substituteString(logger, ctx, entry.ConfigMap.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to substitute variables in configmap name %s: %v", entry.ConfigMap.Name, err)
+	}
+
+	namespace, err := substituteString(logger, ctx, entry.ConfigMap.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to substitute variables in configmap namespace %s: %v", entry.ConfigMap.Namespace, err)
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	obj, err := cmLister.ConfigMaps(namespace).Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configmap %s/%s: %v", namespace, name, err)
+	}
+
+	contextData := map[string]interface{}{
+		entry.Name: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":        obj.GetName(),
+				"namespace":   obj.GetNamespace(),
+				"labels":      obj.GetLabels(),
+				"annotations": obj.GetAnnotations(),
+			},
+			"data": obj.Data,
+		},
+	}
+
+	return json.Marshal(contextData)
+}
+
+func substituteString(logger logr.Logger, ctx context.EvalInterface, value string) (string, error) {
+	substituted, err := variables.SubstituteVars(logger, ctx, value)
+	if err != nil {
+		return "", err
+	}
+
+	result, ok := substituted.(string)
+	if !ok {
+		return "", fmt.Errorf("expected a string, found %T", substituted)
+	}
+	return result, nil
+}
+
+// contextFailureResponse returns a failed rule response when the rule context cannot be loaded
+func contextFailureResponse(rule kyverno.Rule, ruleType utils.RuleType, err error) response.RuleResponse {
+	return response.RuleResponse{
+		Name:    rule.Name,
+		Type:    ruleType.String(),
+		Message: fmt.Sprintf("failed to load context: %v", err),
+		Success: false,
+	}
+}
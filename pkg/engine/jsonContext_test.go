@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	dclient "github.com/nirmata/kyverno/pkg/dclient"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"gotest.tools/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newConfigMapLister(t *testing.T, configMaps ...*v1.ConfigMap) listerv1.ConfigMapLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, cm := range configMaps {
+		assert.NilError(t, indexer.Add(cm))
+	}
+	return listerv1.NewConfigMapLister(indexer)
+}
+
+func Test_LoadContext(t *testing.T) {
+	cmLister := newConfigMapLister(t, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "teams"},
+		Data:       map[string]string{"allowedRegistries": "gcr.io/team-a"},
+	})
+
+	ctx := context.NewContext()
+	err := ctx.AddResource([]byte(`{"metadata":{"name":"nginx","namespace":"team-a"}}`))
+	assert.NilError(t, err)
+
+	entries := []kyverno.ContextEntry{
+		{
+			Name: "teamconfig",
+			ConfigMap: &kyverno.ConfigMapReference{
+				Name:      "{{request.object.metadata.namespace}}",
+				Namespace: "teams",
+			},
+		},
+	}
+
+	policyContext := PolicyContext{Context: ctx, ConfigMapLister: cmLister}
+	err = LoadContext(log.Log, entries, policyContext)
+	assert.NilError(t, err)
+
+	result, err := ctx.Query("teamconfig.data.allowedRegistries")
+	assert.NilError(t, err)
+	assert.Equal(t, result, "gcr.io/team-a")
+
+	result, err = ctx.Query("teamconfig.metadata.namespace")
+	assert.NilError(t, err)
+	assert.Equal(t, result, "teams")
+
+	// the configmap does not exist
+	entries[0].ConfigMap.Name = "team-b"
+	err = LoadContext(log.Log, entries, policyContext)
+	assert.Assert(t, err != nil)
+
+	// no lister available
+	policyContext.ConfigMapLister = nil
+	err = LoadContext(log.Log, entries, policyContext)
+	assert.Assert(t, err != nil)
+}
+
+func Test_LoadContext_APICall(t *testing.T) {
+	client, err := dclient.NewMockClient(runtime.NewScheme())
+	assert.NilError(t, err)
+
+	ctx := context.NewContext()
+	err = ctx.AddResource([]byte(`{"metadata":{"name":"nginx","namespace":"team-a"}}`))
+	assert.NilError(t, err)
+
+	// the cached response is used instead of calling the API server
+	ctx.AddAPICallResult("/api/v1/namespaces/team-a/services", []byte(`{
+		"items": [
+			{"metadata": {"name": "web"}, "spec": {"type": "LoadBalancer"}},
+			{"metadata": {"name": "db"}, "spec": {"type": "ClusterIP"}}
+		]
+	}`))
+
+	entries := []kyverno.ContextEntry{
+		{
+			Name: "lbcount",
+			APICall: &kyverno.APICall{
+				URLPath:  "/api/v1/namespaces/{{request.object.metadata.namespace}}/services",
+				JMESPath: "items[?spec.type=='LoadBalancer'] | length(@)",
+			},
+		},
+		{
+			Name: "services",
+			APICall: &kyverno.APICall{
+				URLPath: "/api/v1/namespaces/team-a/services",
+			},
+		},
+	}
+
+	policyContext := PolicyContext{Context: ctx, Client: client}
+	err = LoadContext(log.Log, entries, policyContext)
+	assert.NilError(t, err)
+
+	result, err := ctx.Query("lbcount")
+	assert.NilError(t, err)
+	assert.Equal(t, result, 1.0)
+
+	result, err = ctx.Query("services.items[1].metadata.name")
+	assert.NilError(t, err)
+	assert.Equal(t, result, "db")
+
+	// invalid jmesPath
+	entries[0].APICall.JMESPath = "items[?"
+	err = LoadContext(log.Log, entries, policyContext)
+	assert.Assert(t, err != nil)
+
+	// no client available
+	policyContext.Client = nil
+	err = LoadContext(log.Log, entries[1:], policyContext)
+	assert.Assert(t, err != nil)
+}
+
+func Test_ValidateWithConfigMapContext(t *testing.T) {
+	rawPolicy := []byte(`{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {
+			"name": "allowed-registries"
+		},
+		"spec": {
+			"validationFailureAction": "enforce",
+			"rules": [
+				{
+					"name": "check-registries",
+					"context": [
+						{
+							"name": "teamconfig",
+							"configMap": {
+								"name": "team-config",
+								"namespace": "{{request.object.metadata.namespace}}"
+							}
+						}
+					],
+					"match": {
+						"resources": {
+							"kinds": ["Pod"]
+						}
+					},
+					"validate": {
+						"message": "images must be pulled from {{teamconfig.data.allowedRegistry}}",
+						"pattern": {
+							"spec": {
+								"containers": [
+									{
+										"image": "{{teamconfig.data.allowedRegistry}}/*"
+									}
+								]
+							}
+						}
+					}
+				}
+			]
+		}
+	}`)
+
+	rawResource := []byte(`{
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {
+			"name": "nginx",
+			"namespace": "team-a"
+		},
+		"spec": {
+			"containers": [
+				{
+					"name": "nginx",
+					"image": "docker.io/nginx"
+				}
+			]
+		}
+	}`)
+
+	cmLister := newConfigMapLister(t, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-config", Namespace: "team-a"},
+		Data:       map[string]string{"allowedRegistry": "gcr.io"},
+	})
+
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal(rawPolicy, &policy))
+
+	resource, err := utils.ConvertToUnstructured(rawResource)
+	assert.NilError(t, err)
+
+	ctx := context.NewContext()
+	assert.NilError(t, ctx.AddResource(rawResource))
+
+	policyContext := PolicyContext{
+		Policy:          policy,
+		NewResource:     *resource,
+		Context:         ctx,
+		ConfigMapLister: cmLister,
+	}
+
+	er := Validate(policyContext)
+	assert.Equal(t, len(er.PolicyResponse.Rules), 1)
+	assert.Assert(t, !er.PolicyResponse.Rules[0].Success)
+
+	// the resource is valid once the registry is allowed
+	cmLister = newConfigMapLister(t, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-config", Namespace: "team-a"},
+		Data:       map[string]string{"allowedRegistry": "docker.io"},
+	})
+
+	ctx = context.NewContext()
+	assert.NilError(t, ctx.AddResource(rawResource))
+	policyContext.Context = ctx
+	policyContext.ConfigMapLister = cmLister
+
+	er = Validate(policyContext)
+	assert.Equal(t, len(er.PolicyResponse.Rules), 1)
+	assert.Assert(t, er.PolicyResponse.Rules[0].Success)
+
+	// the rule fails when the context cannot be loaded
+	policyContext.ConfigMapLister = newConfigMapLister(t)
+	er = Validate(policyContext)
+	assert.Equal(t, len(er.PolicyResponse.Rules), 1)
+	assert.Assert(t, !er.PolicyResponse.Rules[0].Success)
+}
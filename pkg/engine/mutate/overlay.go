@@ -192,7 +192,7 @@ func applyOverlayToMap(resourceMap, overlayMap map[string]interface{}, path stri
 	for key, value := range overlayMap {
 		// skip anchor element because it has condition, not
 		// the value that must replace resource value
-		if anchor.IsConditionAnchor(key) {
+		if anchor.IsConditionAnchor(key) || anchor.IsGlobalAnchor(key) {
 			continue
 		}
 
@@ -84,8 +84,8 @@ func checkConditionOnArray(resource, overlay []interface{}, path string) (string
 
 func validateConditionAnchorMap(resourceMap, anchors map[string]interface{}, path string) (string, overlayError) {
 	for key, overlayValue := range anchors {
-		// skip if key does not have condition anchor
-		if !anchor.IsConditionAnchor(key) {
+		// skip if key does not have condition or global anchor
+		if !anchor.IsConditionAnchor(key) && !anchor.IsGlobalAnchor(key) {
 			continue
 		}
 
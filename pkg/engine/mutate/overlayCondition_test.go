@@ -689,3 +689,29 @@ func TestMeetConditions_AtleastOneExist(t *testing.T) {
 	assert.Assert(t, reflect.DeepEqual(err, overlayError{}))
 	assert.Assert(t, len(path) == 0)
 }
+
+func TestMeetConditions_GlobalAnchor(t *testing.T) {
+	overlayRaw := []byte(`{
+		"spec": {
+			"containers": [
+				{
+					"<(image)": "*:latest",
+					"imagePullPolicy": "Always"
+				}
+			]
+		}
+	}`)
+	matchingRaw := []byte(`{"spec":{"containers":[{"image":"nginx:1.19"},{"image":"busybox:latest"}]}}`)
+	otherRaw := []byte(`{"spec":{"containers":[{"image":"nginx:1.19"}]}}`)
+
+	var overlay, matching, other interface{}
+	assert.NilError(t, json.Unmarshal(overlayRaw, &overlay))
+	assert.NilError(t, json.Unmarshal(matchingRaw, &matching))
+	assert.NilError(t, json.Unmarshal(otherRaw, &other))
+
+	_, err := meetConditions(log.Log, matching, overlay)
+	assert.Assert(t, reflect.DeepEqual(err, overlayError{}))
+
+	_, err = meetConditions(log.Log, other, overlay)
+	assert.Equal(t, err.statusCode, conditionFailure)
+}
@@ -26,7 +26,7 @@ func removeAnchor(key string) string {
 		return key[1 : len(key)-1]
 	}
 
-	if anchor.IsExistenceAnchor(key) || anchor.IsAddingAnchor(key) || anchor.IsEqualityAnchor(key) || anchor.IsNegationAnchor(key) {
+	if anchor.IsExistenceAnchor(key) || anchor.IsAddingAnchor(key) || anchor.IsEqualityAnchor(key) || anchor.IsNegationAnchor(key) || anchor.IsGlobalAnchor(key) {
 		return key[2 : len(key)-1]
 	}
 
@@ -40,19 +40,19 @@ func getRawKeyIfWrappedWithAttributes(str string) string {
 
 	if str[0] == '(' && str[len(str)-1] == ')' {
 		return str[1 : len(str)-1]
-	} else if (str[0] == '$' || str[0] == '^' || str[0] == '+' || str[0] == '=') && (str[1] == '(' && str[len(str)-1] == ')') {
+	} else if (str[0] == '$' || str[0] == '^' || str[0] == '+' || str[0] == '=' || str[0] == '<') && (str[1] == '(' && str[len(str)-1] == ')') {
 		return str[2 : len(str)-1]
 	} else {
 		return str
 	}
 }
 
-// getAnchorAndElementsFromMap gets the condition anchor map and resource map without anchor
+// getAnchorAndElementsFromMap gets the condition and global anchor map and resource map without anchor
 func getAnchorAndElementsFromMap(anchorsMap map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
 	anchors := make(map[string]interface{})
 	elementsWithoutanchor := make(map[string]interface{})
 	for key, value := range anchorsMap {
-		if anchor.IsConditionAnchor(key) {
+		if anchor.IsConditionAnchor(key) || anchor.IsGlobalAnchor(key) {
 			anchors[key] = value
 		} else if !anchor.IsAddingAnchor(key) {
 			elementsWithoutanchor[key] = value
@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/minio/pkg/wildcard"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/mutate"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/nirmata/kyverno/pkg/engine/variables"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// MutateTargets applies the mutate rule with targets to the resources it selects for the trigger resource,
+// the trigger is the NewResource of the policy context. It returns an engine response per target,
+// or none if the trigger does not satisfy the rule.
+func MutateTargets(policyContext PolicyContext, ruleName string) ([]response.EngineResponse, error) {
+	policy := policyContext.Policy
+	trigger := policyContext.NewResource
+	logger := log.Log.WithName("EngineMutateTargets").WithValues("policy", policy.Name, "rule", ruleName, "kind", trigger.GetKind(),
+		"namespace", trigger.GetNamespace(), "name", trigger.GetName())
+
+	rule, ok := getRule(policy, ruleName)
+	if !ok || !rule.HasMutateTargets() {
+		return nil, fmt.Errorf("policy %s has no mutate rule %s with targets", policy.Name, ruleName)
+	}
+
+	if err := MatchesResourceDescription(trigger, rule, policyContext.AdmissionInfo, policyContext.ExcludeGroupRole, policy.Namespace, policyContext.NamespaceLabels); err != nil {
+		logger.V(3).Info("trigger not matched", "reason", err.Error())
+		return nil, nil
+	}
+
+	if err := LoadContext(logger, rule.Context, policyContext); err != nil {
+		return nil, fmt.Errorf("failed to load context: %v", err)
+	}
+
+	if !variables.EvaluateConditions(logger, policyContext.Context, copyConditions(rule.Conditions)) {
+		logger.V(3).Info("trigger fails the preconditions")
+		return nil, nil
+	}
+
+	targets, err := getTargets(logger, policyContext, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	// the targets are only available while the rule is processed
+	ctx := policyContext.Context
+	ctx.Checkpoint()
+	defer ctx.Restore()
+
+	var engineResponses []response.EngineResponse
+	for _, target := range targets {
+		if err := ctx.AddTarget(target.Object); err != nil {
+			return nil, fmt.Errorf("failed to add target %s/%s to the context: %v", target.GetNamespace(), target.GetName(), err)
+		}
+
+		engineResponses = append(engineResponses, mutateTarget(logger, ctx, policy, rule, target))
+	}
+
+	return engineResponses, nil
+}
+
+// mutateTarget applies the patches of the rule to a single target
+func mutateTarget(log logr.Logger, ctx context.EvalInterface, policy kyverno.ClusterPolicy, rule kyverno.Rule, target unstructured.Unstructured) (resp response.EngineResponse) {
+	startTime := time.Now()
+	logger := log.WithValues("targetKind", target.GetKind(), "targetNamespace", target.GetNamespace(), "targetName", target.GetName())
+
+	startMutateResultResponse(&resp, policy, target)
+	defer endMutateResultResponse(logger, &resp, startTime)
+
+	mutation := rule.Mutation.DeepCopy()
+	mutation.Targets = nil
+
+	mutateHandler := mutate.CreateMutateHandler(rule.Name, mutation, target, ctx, logger)
+	ruleResponse, patchedResource := mutateHandler.Handle()
+
+	resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, ruleResponse)
+	incrementAppliedRuleCount(&resp)
+	resp.PatchedResource = patchedResource
+	return resp
+}
+
+// getTargets substitutes the variables of the target selectors and lists the selected resources,
+// the targets of a namespaced policy are limited to its namespace
+func getTargets(log logr.Logger, policyContext PolicyContext, rule kyverno.Rule) ([]unstructured.Unstructured, error) {
+	if policyContext.Client == nil {
+		return nil, fmt.Errorf("a client is required to list the targets")
+	}
+
+	selectors, err := substituteTargets(log, policyContext.Context, rule.Mutation.Targets)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []unstructured.Unstructured
+	for _, selector := range selectors {
+		namespace := selector.Namespace
+		if policyContext.Policy.Namespace != "" {
+			namespace = policyContext.Policy.Namespace
+		}
+
+		list, err := policyContext.Client.ListResource(selector.APIVersion, selector.Kind, namespace, selector.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list targets of kind %s: %v", selector.Kind, err)
+		}
+
+		for _, item := range list.Items {
+			if selector.Name != "" && !wildcard.Match(selector.Name, item.GetName()) {
+				continue
+			}
+			targets = append(targets, item)
+		}
+	}
+
+	return targets, nil
+}
+
+// substituteTargets substitutes the variables of the trigger in the target selectors
+func substituteTargets(log logr.Logger, ctx context.EvalInterface, targets []kyverno.TargetSelector) ([]kyverno.TargetSelector, error) {
+	raw, err := json.Marshal(targets)
+	if err != nil {
+		return nil, err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	substituted, err := variables.SubstituteVars(log, ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to substitute variables in targets: %v", err)
+	}
+
+	if raw, err = json.Marshal(substituted); err != nil {
+		return nil, err
+	}
+
+	var result []kyverno.TargetSelector
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func getRule(policy kyverno.ClusterPolicy, name string) (kyverno.Rule, bool) {
+	for _, rule := range policy.Spec.Rules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return kyverno.Rule{}, false
+}
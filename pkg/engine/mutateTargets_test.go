@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"gotest.tools/assert"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var targetsPolicy = []byte(`{
+	"apiVersion": "kyverno.io/v1",
+	"kind": "ClusterPolicy",
+	"metadata": {
+		"name": "restart-on-secret-change"
+	},
+	"spec": {
+		"rules": [
+			{
+				"name": "annotate-deployments",
+				"match": {
+					"resources": {
+						"kinds": ["Secret"]
+					}
+				},
+				"mutate": {
+					"targets": [
+						{
+							"apiVersion": "apps/v1",
+							"kind": "Deployment",
+							"namespace": "{{request.object.metadata.namespace}}",
+							"name": "{{request.object.metadata.labels.app}}-*"
+						}
+					],
+					"patchStrategicMerge": {
+						"metadata": {
+							"annotations": {
+								"secret-version": "{{request.object.metadata.resourceVersion}}",
+								"target": "{{target.metadata.name}}"
+							}
+						}
+					}
+				}
+			}
+		]
+	}
+}`)
+
+var targetsTrigger = []byte(`{
+	"apiVersion": "v1",
+	"kind": "Secret",
+	"metadata": {
+		"name": "credentials",
+		"namespace": "prod",
+		"resourceVersion": "42",
+		"labels": {
+			"app": "web"
+		}
+	}
+}`)
+
+var targetsDeployment = []byte(`{
+	"apiVersion": "apps/v1",
+	"kind": "Deployment",
+	"metadata": {
+		"name": "web-frontend",
+		"namespace": "prod",
+		"resourceVersion": "7"
+	}
+}`)
+
+func newTargetsPolicyContext(t *testing.T) PolicyContext {
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal(targetsPolicy, &policy))
+
+	trigger, err := utils.ConvertToUnstructured(targetsTrigger)
+	assert.NilError(t, err)
+
+	ctx := context.NewContext()
+	assert.NilError(t, ctx.AddResource(targetsTrigger))
+
+	return PolicyContext{
+		Policy:      policy,
+		NewResource: *trigger,
+		Context:     ctx,
+	}
+}
+
+func Test_Mutate_SkipsTargets(t *testing.T) {
+	policyContext := newTargetsPolicyContext(t)
+
+	er := Mutate(policyContext)
+	assert.Equal(t, len(er.PolicyResponse.Rules), 0)
+	assert.DeepEqual(t, er.PatchedResource, policyContext.NewResource)
+}
+
+func Test_SubstituteTargets(t *testing.T) {
+	policyContext := newTargetsPolicyContext(t)
+
+	targets, err := substituteTargets(log.Log, policyContext.Context, policyContext.Policy.Spec.Rules[0].Mutation.Targets)
+	assert.NilError(t, err)
+	assert.Equal(t, len(targets), 1)
+	assert.Equal(t, targets[0].Namespace, "prod")
+	assert.Equal(t, targets[0].Name, "web-*")
+	assert.Equal(t, targets[0].Kind, "Deployment")
+}
+
+func Test_MutateTarget(t *testing.T) {
+	policyContext := newTargetsPolicyContext(t)
+
+	target, err := utils.ConvertToUnstructured(targetsDeployment)
+	assert.NilError(t, err)
+
+	ctx := policyContext.Context
+	ctx.Checkpoint()
+	defer ctx.Restore()
+	assert.NilError(t, ctx.AddTarget(target.Object))
+
+	er := mutateTarget(log.Log, ctx, policyContext.Policy, policyContext.Policy.Spec.Rules[0], *target)
+	assert.Assert(t, er.IsSuccessful())
+	assert.Equal(t, len(er.PolicyResponse.Rules), 1)
+	assert.Equal(t, er.PolicyResponse.Resource.Name, "web-frontend")
+
+	annotations := er.PatchedResource.GetAnnotations()
+	assert.Equal(t, annotations["secret-version"], "42")
+	assert.Equal(t, annotations["target"], "web-frontend")
+	assert.Equal(t, er.PatchedResource.GetResourceVersion(), "7")
+}
@@ -7,7 +7,9 @@ import (
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	"github.com/nirmata/kyverno/pkg/engine/mutate"
 	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
 	"github.com/nirmata/kyverno/pkg/engine/variables"
+	"github.com/nirmata/kyverno/pkg/metrics"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -48,6 +50,11 @@ func Mutate(policyContext PolicyContext) (resp response.EngineResponse) {
 			continue
 		}
 
+		// rules with targets mutate other resources, they are applied asynchronously
+		if rule.HasMutateTargets() {
+			continue
+		}
+
 		// check if the resource satisfies the filter conditions defined in the rule
 		//TODO: this needs to be extracted, to filter the resource so that we can avoid passing resources that
 		// dont satisfy a policy rule resource description
@@ -55,24 +62,36 @@ func Mutate(policyContext PolicyContext) (resp response.EngineResponse) {
 		if len(policyContext.ExcludeGroupRole) > 0 {
 			excludeResource = policyContext.ExcludeGroupRole
 		}
-		if err := MatchesResourceDescription(patchedResource, rule, policyContext.AdmissionInfo, excludeResource); err != nil {
+		if err := MatchesResourceDescription(patchedResource, rule, policyContext.AdmissionInfo, excludeResource, policy.Namespace, policyContext.NamespaceLabels); err != nil {
 			logger.V(3).Info("resource not matched", "reason", err.Error())
 			continue
 		}
 
+		if err := LoadContext(logger, rule.Context, policyContext); err != nil {
+			logger.V(3).Info("failed to load context", "reason", err.Error())
+			resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, contextFailureResponse(rule, utils.Mutation, err))
+			incrementAppliedRuleCount(&resp)
+			continue
+		}
+
 		// operate on the copy of the conditions, as we perform variable substitution
 		copyConditions := copyConditions(rule.Conditions)
 		// evaluate pre-conditions
 		// - handle variable substitutions
 		if !variables.EvaluateConditions(logger, ctx, copyConditions) {
 			logger.V(3).Info("resource fails the preconditions")
+			resp.PolicyResponse.RulesSkipped = append(resp.PolicyResponse.RulesSkipped, rule.Name)
 			continue
 		}
 
-		mutation := rule.Mutation.DeepCopy()
+		if rule.Mutation.ForEachMutation != nil {
+			ruleResponse, patchedResource = mutateForEach(logger, ctx, rule, patchedResource)
+		} else {
+			mutation := rule.Mutation.DeepCopy()
 
-		mutateHandler := mutate.CreateMutateHandler(rule.Name, mutation, patchedResource, ctx, logger)
-		ruleResponse, patchedResource = mutateHandler.Handle()
+			mutateHandler := mutate.CreateMutateHandler(rule.Name, mutation, patchedResource, ctx, logger)
+			ruleResponse, patchedResource = mutateHandler.Handle()
+		}
 		if ruleResponse.Success {
 			// - overlay pattern does not match the resource conditions
 			if ruleResponse.Patches == nil {
@@ -96,6 +115,7 @@ func incrementAppliedRuleCount(resp *response.EngineResponse) {
 func startMutateResultResponse(resp *response.EngineResponse, policy kyverno.ClusterPolicy, resource unstructured.Unstructured) {
 	// set policy information
 	resp.PolicyResponse.Policy = policy.Name
+	resp.PolicyResponse.PolicyNamespace = policy.Namespace
 	// resource details
 	resp.PolicyResponse.Resource.Name = resource.GetName()
 	resp.PolicyResponse.Resource.Namespace = resource.GetNamespace()
@@ -107,4 +127,5 @@ func startMutateResultResponse(resp *response.EngineResponse, policy kyverno.Clu
 func endMutateResultResponse(logger logr.Logger, resp *response.EngineResponse, startTime time.Time) {
 	resp.PolicyResponse.ProcessingTime = time.Since(startTime)
 	logger.V(4).Info("finished processing policy", "processingTime", resp.PolicyResponse.ProcessingTime.String(), "mutationRulesApplied", resp.PolicyResponse.RulesAppliedCount)
+	metrics.RecordEngineResponse(utils.Mutation.String(), *resp)
 }
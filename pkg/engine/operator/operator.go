@@ -1,5 +1,10 @@
 package operator
 
+import (
+	"regexp"
+	"strings"
+)
+
 // Operator is string alias that represents selection operators enum
 type Operator string
 
@@ -16,17 +21,44 @@ const (
 	More Operator = ">"
 	// Less stands for <
 	Less Operator = "<"
+	// InRange stands for an inclusive range, e.g. range:1-10
+	InRange Operator = "range:"
+	// NotInRange stands for a value outside of a range, e.g. !range:1-10
+	NotInRange Operator = "!range:"
+	// Regex stands for a regular expression, e.g. regex:^[a-z]+$
+	Regex Operator = "regex:"
+	// NotRegex stands for a negated regular expression, e.g. !regex:^[a-z]+$
+	NotRegex Operator = "!regex:"
 )
 
 //ReferenceSign defines the operator for anchor reference
 const ReferenceSign Operator = "$()"
 
+// rangeBounds matches the bounds of a range of numbers, quantities or durations, e.g. 1-10, 100Mi-1Gi or 10s-1m
+var rangeBounds = regexp.MustCompile(`^(\d+(\.\d+)?[a-zA-Zµ]*)-(\d+(\.\d+)?[a-zA-Zµ]*)$`)
+
 // GetOperatorFromStringPattern parses opeartor from pattern
 func GetOperatorFromStringPattern(pattern string) Operator {
 	if len(pattern) < 2 {
 		return Equal
 	}
 
+	if strings.HasPrefix(pattern, string(Regex)) {
+		return Regex
+	}
+
+	if strings.HasPrefix(pattern, string(NotRegex)) {
+		return NotRegex
+	}
+
+	if strings.HasPrefix(pattern, string(InRange)) {
+		return InRange
+	}
+
+	if strings.HasPrefix(pattern, string(NotInRange)) {
+		return NotInRange
+	}
+
 	if pattern[:len(MoreEqual)] == string(MoreEqual) {
 		return MoreEqual
 	}
@@ -49,3 +81,15 @@ func GetOperatorFromStringPattern(pattern string) Operator {
 
 	return Equal
 }
+
+// SplitRange returns the lower and upper bounds of a range pattern
+// such as range:1-10 or !range:100Mi-1Gi, ok is false if the bounds are invalid
+func SplitRange(pattern string) (min, max string, ok bool) {
+	pattern = strings.TrimPrefix(pattern, string(NotInRange))
+	pattern = strings.TrimPrefix(pattern, string(InRange))
+	bounds := rangeBounds.FindStringSubmatch(strings.TrimSpace(pattern))
+	if bounds == nil {
+		return "", "", false
+	}
+	return bounds[1], bounds[3], true
+}
@@ -16,3 +16,36 @@ func TestGetOperatorFromStringPattern_EmptyString(t *testing.T) {
 func TestGetOperatorFromStringPattern_OnlyOperator(t *testing.T) {
 	assert.Equal(t, GetOperatorFromStringPattern(">="), MoreEqual)
 }
+
+func TestGetOperatorFromStringPattern_Range(t *testing.T) {
+	assert.Equal(t, GetOperatorFromStringPattern("range:1-10"), InRange)
+	assert.Equal(t, GetOperatorFromStringPattern("range:100Mi-1Gi"), InRange)
+	assert.Equal(t, GetOperatorFromStringPattern("!range:100Mi-1Gi"), NotInRange)
+	assert.Equal(t, GetOperatorFromStringPattern("1-10"), Equal)
+	assert.Equal(t, GetOperatorFromStringPattern("2020-01"), Equal)
+	assert.Equal(t, GetOperatorFromStringPattern("!1-2"), NotEqual)
+	assert.Equal(t, GetOperatorFromStringPattern("!my-app"), NotEqual)
+	assert.Equal(t, GetOperatorFromStringPattern("my-app"), Equal)
+}
+
+func TestGetOperatorFromStringPattern_Regex(t *testing.T) {
+	assert.Equal(t, GetOperatorFromStringPattern("regex:^a|b$"), Regex)
+	assert.Equal(t, GetOperatorFromStringPattern("!regex:^a|b$"), NotRegex)
+}
+
+func TestSplitRange(t *testing.T) {
+	min, max, ok := SplitRange("!range:100Mi-1Gi")
+	assert.Assert(t, ok)
+	assert.Equal(t, min, "100Mi")
+	assert.Equal(t, max, "1Gi")
+
+	min, max, ok = SplitRange("range:10s-1m")
+	assert.Assert(t, ok)
+	assert.Equal(t, min, "10s")
+	assert.Equal(t, max, "1m")
+
+	_, _, ok = SplitRange("range:1-")
+	assert.Assert(t, !ok)
+	_, _, ok = SplitRange("range:a-b")
+	assert.Assert(t, !ok)
+}
@@ -1,10 +1,13 @@
 package engine
 
 import (
+	"time"
+
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	client "github.com/nirmata/kyverno/pkg/dclient"
 	"github.com/nirmata/kyverno/pkg/engine/context"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	listerv1 "k8s.io/client-go/listers/core/v1"
 )
 
 // PolicyContext contains the contexts for engine to process
@@ -19,7 +22,13 @@ type PolicyContext struct {
 	// Dynamic client - used by generate
 	Client *client.Client
 	// Contexts to store resources
-	Context context.EvalInterface
+	Context context.Interface
 	// Config handler
 	ExcludeGroupRole []string
+	// ConfigMap lister - used to load the rule context
+	ConfigMapLister listerv1.ConfigMapLister
+	// Timeout of the API calls made to load the rule context
+	APICallTimeout time.Duration
+	// Labels of the namespace of the resource - used by namespace selectors and ValidationFailureAction overrides
+	NamespaceLabels map[string]string
 }
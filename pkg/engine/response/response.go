@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -19,16 +20,25 @@ type EngineResponse struct {
 type PolicyResponse struct {
 	// policy name
 	Policy string `json:"policy"`
+	// policy namespace, empty for cluster policies
+	PolicyNamespace string `json:"policyNamespace,omitempty"`
 	// resource details
 	Resource ResourceSpec `json:"resource"`
 	// policy statistics
 	PolicyStats `json:",inline"`
 	// rule response
 	Rules []RuleResponse `json:"rules"`
+	// rules skipped as the resource does not satisfy their preconditions
+	RulesSkipped []string `json:"rulesSkipped,omitempty"`
 	// ValidationFailureAction: audit(default if not set),enforce
 	ValidationFailureAction string
 }
 
+//GetPolicyKey returns the policy name for a cluster policy and namespace/name for a namespaced policy
+func (pr PolicyResponse) GetPolicyKey() string {
+	return kyverno.PolicyKey(pr.PolicyNamespace, pr.Policy)
+}
+
 //ResourceSpec resource action applied on
 type ResourceSpec struct {
 	//TODO: support ApiVersion
@@ -63,6 +73,8 @@ type RuleResponse struct {
 	Patches [][]byte `json:"patches,omitempty"`
 	// success/fail
 	Success bool `json:"success"`
+	// ValidationFailureAction of the rule for the resource namespace, for validation rules
+	ValidationFailureAction string `json:"validationFailureAction,omitempty"`
 	// statistics
 	RuleStats `json:",inline"`
 }
@@ -16,6 +16,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 //EngineStats stores in the statistics for a single application of resource
@@ -26,11 +27,21 @@ type EngineStats struct {
 	RulesAppliedCount int
 }
 
-func checkKind(kinds []string, resourceKind string) bool {
+// checkKind checks if the kind of the resource, or for subresource requests
+// the kind of the parent resource and the subresource, matches one of the kinds
+func checkKind(kinds []string, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo) bool {
+	gvk := resource.GroupVersionKind()
 	for _, kind := range kinds {
-		if resourceKind == kind {
+		if utils.MatchKindSelector(kind, gvk, "") {
 			return true
 		}
+
+		if admissionInfo.SubResource != "" && admissionInfo.ParentKind != nil {
+			parentGVK := schema.GroupVersionKind(*admissionInfo.ParentKind)
+			if utils.MatchKindSelector(kind, parentGVK, admissionInfo.SubResource) {
+				return true
+			}
+		}
 	}
 
 	return false
@@ -49,6 +60,38 @@ func checkNameSpace(namespaces []string, resourceNameSpace string) bool {
 	return false
 }
 
+// checkAnnotations checks that the resource has all the annotations, wildcards are supported in keys and values
+func checkAnnotations(annotations map[string]string, resourceAnnotations map[string]string) bool {
+	for key, value := range annotations {
+		var found bool
+		for resourceKey, resourceValue := range resourceAnnotations {
+			if wildcard.Match(key, resourceKey) && wildcard.Match(value, resourceValue) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// checkOperation checks the operation of the admission request,
+// background processing has no operation and is handled like a CREATE request
+func checkOperation(operations []kyverno.AdmissionOperation, operation kyverno.AdmissionOperation) bool {
+	if operation == "" {
+		operation = kyverno.Create
+	}
+
+	for _, op := range operations {
+		if op == operation {
+			return true
+		}
+	}
+	return false
+}
+
 func checkSelector(labelSelector *metav1.LabelSelector, resourceLabels map[string]string) (bool, error) {
 	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
 	if err != nil {
@@ -69,7 +112,10 @@ func checkSelector(labelSelector *metav1.LabelSelector, resourceLabels map[strin
 // 		Kinds      []string
 // 		Name       string
 // 		Namespaces []string
+// 		Annotations
 // 		Selector
+// 		NamespaceSelector
+// 		Operations
 // UserInfo:
 // 		Roles        []string
 // 		ClusterRoles []string
@@ -78,10 +124,10 @@ func checkSelector(labelSelector *metav1.LabelSelector, resourceLabels map[strin
 // should be: AND across attibutes but an OR inside attributes that of type list
 // To filter out the targeted resources with UserInfo, the check
 // should be: OR (accross & inside) attributes
-func doesResourceMatchConditionBlock(conditionBlock kyverno.ResourceDescription, userInfo kyverno.UserInfo, admissionInfo kyverno.RequestInfo, resource unstructured.Unstructured, dynamicConfig []string) []error {
+func doesResourceMatchConditionBlock(conditionBlock kyverno.ResourceDescription, userInfo kyverno.UserInfo, admissionInfo kyverno.RequestInfo, resource unstructured.Unstructured, dynamicConfig []string, namespaceLabels map[string]string) []error {
 	var errs []error
 	if len(conditionBlock.Kinds) > 0 {
-		if !checkKind(conditionBlock.Kinds, resource.GetKind()) {
+		if !checkKind(conditionBlock.Kinds, resource, admissionInfo) {
 			errs = append(errs, fmt.Errorf("kind does not match"))
 		}
 	}
@@ -95,6 +141,16 @@ func doesResourceMatchConditionBlock(conditionBlock kyverno.ResourceDescription,
 			errs = append(errs, fmt.Errorf("namespace does not match"))
 		}
 	}
+	if len(conditionBlock.Annotations) > 0 {
+		if !checkAnnotations(conditionBlock.Annotations, resource.GetAnnotations()) {
+			errs = append(errs, fmt.Errorf("annotations do not match"))
+		}
+	}
+	if len(conditionBlock.Operations) > 0 {
+		if !checkOperation(conditionBlock.Operations, admissionInfo.Operation) {
+			errs = append(errs, fmt.Errorf("operation does not match"))
+		}
+	}
 	if conditionBlock.Selector != nil {
 		hasPassed, err := checkSelector(conditionBlock.Selector, resource.GetLabels())
 		if err != nil {
@@ -105,6 +161,18 @@ func doesResourceMatchConditionBlock(conditionBlock kyverno.ResourceDescription,
 			}
 		}
 	}
+	if conditionBlock.NamespaceSelector != nil {
+		// a namespace is selected by its own labels
+		if resource.GetKind() == "Namespace" {
+			namespaceLabels = resource.GetLabels()
+		}
+		hasPassed, err := checkSelector(conditionBlock.NamespaceSelector, namespaceLabels)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse namespace selector: %v", err))
+		} else if !hasPassed {
+			errs = append(errs, fmt.Errorf("namespace selector does not match"))
+		}
+	}
 
 	keys := append(admissionInfo.AdmissionUserInfo.Groups, admissionInfo.AdmissionUserInfo.Username)
 	var userInfoErrors []error
@@ -180,31 +248,59 @@ func matchSubjects(ruleSubjects []rbacv1.Subject, userInfo authenticationv1.User
 }
 
 //MatchesResourceDescription checks if the resource matches resource description of the rule or not
-func MatchesResourceDescription(resourceRef unstructured.Unstructured, ruleRef kyverno.Rule, admissionInfoRef kyverno.RequestInfo, dynamicConfig []string) error {
+// policyNamespace is the namespace of a namespaced Policy, a non-empty value restricts the rule to resources in that namespace
+// namespaceLabels are the labels of the resource namespace, used by namespace selectors
+func MatchesResourceDescription(resourceRef unstructured.Unstructured, ruleRef kyverno.Rule, admissionInfoRef kyverno.RequestInfo, dynamicConfig []string, policyNamespace string, namespaceLabels map[string]string) error {
 
 	rule := *ruleRef.DeepCopy()
 	resource := *resourceRef.DeepCopy()
 	admissionInfo := *admissionInfoRef.DeepCopy()
 
 	var reasonsForFailure []error
+	if policyNamespace != "" && policyNamespace != resource.GetNamespace() {
+		return fmt.Errorf("resource namespace %s does not match policy namespace %s", resource.GetNamespace(), policyNamespace)
+	}
 
 	if reflect.DeepEqual(admissionInfo, kyverno.RequestInfo{}) {
 		rule.MatchResources.UserInfo = kyverno.UserInfo{}
+		for i := range rule.MatchResources.Any {
+			rule.MatchResources.Any[i].UserInfo = kyverno.UserInfo{}
+		}
+
+		for i := range rule.MatchResources.All {
+			rule.MatchResources.All[i].UserInfo = kyverno.UserInfo{}
+		}
 	}
 
 	// checking if resource matches the rule
-	if !reflect.DeepEqual(rule.MatchResources.ResourceDescription, kyverno.ResourceDescription{}) ||
+	if len(rule.MatchResources.Any) > 0 {
+		matchErrs := doesResourceMatchFilters(rule.MatchResources.Any, true, admissionInfo, resource, dynamicConfig, namespaceLabels)
+		reasonsForFailure = append(reasonsForFailure, matchErrs...)
+	} else if len(rule.MatchResources.All) > 0 {
+		matchErrs := doesResourceMatchFilters(rule.MatchResources.All, false, admissionInfo, resource, dynamicConfig, namespaceLabels)
+		reasonsForFailure = append(reasonsForFailure, matchErrs...)
+	} else if !reflect.DeepEqual(rule.MatchResources.ResourceDescription, kyverno.ResourceDescription{}) ||
 		!reflect.DeepEqual(rule.MatchResources.UserInfo, kyverno.UserInfo{}) {
-		matchErrs := doesResourceMatchConditionBlock(rule.MatchResources.ResourceDescription, rule.MatchResources.UserInfo, admissionInfo, resource, dynamicConfig)
+		matchErrs := doesResourceMatchConditionBlock(rule.MatchResources.ResourceDescription, rule.MatchResources.UserInfo, admissionInfo, resource, dynamicConfig, namespaceLabels)
 		reasonsForFailure = append(reasonsForFailure, matchErrs...)
 	} else {
 		reasonsForFailure = append(reasonsForFailure, fmt.Errorf("match cannot be empty"))
 	}
 
 	// checking if resource has been excluded
-	if !reflect.DeepEqual(rule.ExcludeResources.ResourceDescription, kyverno.ResourceDescription{}) ||
+	if len(rule.ExcludeResources.Any) > 0 {
+		excludeErrs := doesResourceMatchFilters(rule.ExcludeResources.Any, true, admissionInfo, resource, dynamicConfig, namespaceLabels)
+		if excludeErrs == nil {
+			reasonsForFailure = append(reasonsForFailure, fmt.Errorf("resource excluded"))
+		}
+	} else if len(rule.ExcludeResources.All) > 0 {
+		excludeErrs := doesResourceMatchFilters(rule.ExcludeResources.All, false, admissionInfo, resource, dynamicConfig, namespaceLabels)
+		if excludeErrs == nil {
+			reasonsForFailure = append(reasonsForFailure, fmt.Errorf("resource excluded"))
+		}
+	} else if !reflect.DeepEqual(rule.ExcludeResources.ResourceDescription, kyverno.ResourceDescription{}) ||
 		!reflect.DeepEqual(rule.ExcludeResources.UserInfo, kyverno.UserInfo{}) {
-		excludeErrs := doesResourceMatchConditionBlock(rule.ExcludeResources.ResourceDescription, rule.ExcludeResources.UserInfo, admissionInfo, resource, dynamicConfig)
+		excludeErrs := doesResourceMatchConditionBlock(rule.ExcludeResources.ResourceDescription, rule.ExcludeResources.UserInfo, admissionInfo, resource, dynamicConfig, namespaceLabels)
 		if excludeErrs == nil {
 			reasonsForFailure = append(reasonsForFailure, fmt.Errorf("resource excluded"))
 		}
@@ -224,6 +320,25 @@ func MatchesResourceDescription(resourceRef unstructured.Unstructured, ruleRef k
 
 	return nil
 }
+
+// doesResourceMatchFilters checks the resource against a list of resource filters,
+// a single matching filter is sufficient if matchAny is set, otherwise all filters must match
+func doesResourceMatchFilters(filters kyverno.ResourceFilters, matchAny bool, admissionInfo kyverno.RequestInfo, resource unstructured.Unstructured, dynamicConfig []string, namespaceLabels map[string]string) []error {
+	var errs []error
+	for i, filter := range filters {
+		filterErrs := doesResourceMatchConditionBlock(filter.ResourceDescription, filter.UserInfo, admissionInfo, resource, dynamicConfig, namespaceLabels)
+		if matchAny && len(filterErrs) == 0 {
+			return nil
+		}
+
+		for _, err := range filterErrs {
+			errs = append(errs, fmt.Errorf("filter %d: %v", i, err))
+		}
+	}
+
+	return errs
+}
+
 func copyConditions(original []kyverno.Condition) []kyverno.Condition {
 	var copy []kyverno.Condition
 	for _, condition := range original {
@@ -110,12 +110,12 @@ func ConvertToUnstructured(data []byte) (*unstructured.Unstructured, error) {
 	return resource, nil
 }
 
-// GetAnchorsFromMap gets the conditional anchor map
+// GetAnchorsFromMap gets the conditional and global anchor map
 func GetAnchorsFromMap(anchorsMap map[string]interface{}) map[string]interface{} {
 	result := make(map[string]interface{})
 
 	for key, value := range anchorsMap {
-		if anchor.IsConditionAnchor(key) {
+		if anchor.IsConditionAnchor(key) || anchor.IsGlobalAnchor(key) {
 			result[key] = value
 		}
 	}
@@ -70,7 +70,7 @@ func TestMatchesResourceDescription(t *testing.T) {
 		resource, _ := utils.ConvertToUnstructured(tc.Resource)
 
 		for _, rule := range policy.Spec.Rules {
-			err := MatchesResourceDescription(*resource, rule, tc.AdmissionInfo, []string{})
+			err := MatchesResourceDescription(*resource, rule, tc.AdmissionInfo, []string{}, "", nil)
 			if err != nil {
 				if !tc.areErrorsExpected {
 					t.Errorf("Testcase %d Unexpected error: %v", i+1, err)
@@ -138,7 +138,7 @@ func TestResourceDescriptionMatch_MultipleKind(t *testing.T) {
 	}
 	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription}}
 
-	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}); err != nil {
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, "", nil); err != nil {
 		t.Errorf("Testcase has failed due to the following:%v", err)
 	}
 
@@ -199,7 +199,7 @@ func TestResourceDescriptionMatch_Name(t *testing.T) {
 	}
 	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription}}
 
-	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}); err != nil {
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, "", nil); err != nil {
 		t.Errorf("Testcase has failed due to the following:%v", err)
 	}
 }
@@ -259,7 +259,7 @@ func TestResourceDescriptionMatch_Name_Regex(t *testing.T) {
 	}
 	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription}}
 
-	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}); err != nil {
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, "", nil); err != nil {
 		t.Errorf("Testcase has failed due to the following:%v", err)
 	}
 }
@@ -327,7 +327,7 @@ func TestResourceDescriptionMatch_Label_Expression_NotMatch(t *testing.T) {
 	}
 	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription}}
 
-	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}); err != nil {
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, "", nil); err != nil {
 		t.Errorf("Testcase has failed due to the following:%v", err)
 	}
 }
@@ -396,7 +396,7 @@ func TestResourceDescriptionMatch_Label_Expression_Match(t *testing.T) {
 	}
 	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription}}
 
-	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}); err != nil {
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, "", nil); err != nil {
 		t.Errorf("Testcase has failed due to the following:%v", err)
 	}
 }
@@ -476,7 +476,271 @@ func TestResourceDescriptionExclude_Label_Expression_Match(t *testing.T) {
 	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription},
 		ExcludeResources: kyverno.ExcludeResources{ResourceDescription: resourceDescriptionExclude}}
 
-	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}); err == nil {
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, "", nil); err == nil {
 		t.Errorf("Testcase has failed due to the following:\n Function has returned no error, even though it was suposed to fail")
 	}
 }
+
+// A namespaced policy only matches resources in its own namespace
+func TestResourceDescriptionMatch_PolicyNamespace(t *testing.T) {
+	rawResource := []byte(`{
+		"apiVersion": "v1",
+		"kind": "ConfigMap",
+		"metadata": {
+		   "name": "game-config",
+		   "namespace": "test"
+		}
+	 }`)
+	resource, err := utils.ConvertToUnstructured(rawResource)
+	if err != nil {
+		t.Errorf("unable to convert raw resource to unstructured: %v", err)
+	}
+	resourceDescription := kyverno.ResourceDescription{
+		Kinds: []string{"ConfigMap"},
+	}
+	rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: resourceDescription}}
+
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, "test", nil); err != nil {
+		t.Errorf("Testcase has failed due to the following:%v", err)
+	}
+
+	if err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, "other", nil); err == nil {
+		t.Errorf("Testcase has failed due to the following:\n Function has returned no error, even though it was suposed to fail")
+	}
+}
+
+// Operations are matched against the operation of the admission request,
+// background processing is handled like a CREATE request
+func TestResourceDescriptionMatch_Operations(t *testing.T) {
+	rawResource := []byte(`{
+		"apiVersion": "v1",
+		"kind": "Namespace",
+		"metadata": {
+		   "name": "kube-system"
+		}
+	 }`)
+	resource, err := utils.ConvertToUnstructured(rawResource)
+	if err != nil {
+		t.Errorf("unable to convert raw resource to unstructured: %v", err)
+	}
+
+	testcases := []struct {
+		match     []kyverno.AdmissionOperation
+		exclude   []kyverno.AdmissionOperation
+		operation kyverno.AdmissionOperation
+		matches   bool
+	}{
+		{match: []kyverno.AdmissionOperation{kyverno.Delete}, operation: kyverno.Delete, matches: true},
+		{match: []kyverno.AdmissionOperation{kyverno.Delete}, operation: kyverno.Update, matches: false},
+		{match: []kyverno.AdmissionOperation{kyverno.Delete}, operation: "", matches: false},
+		{match: []kyverno.AdmissionOperation{kyverno.Create}, operation: "", matches: true},
+		{exclude: []kyverno.AdmissionOperation{kyverno.Update}, operation: kyverno.Update, matches: false},
+		{exclude: []kyverno.AdmissionOperation{kyverno.Update}, operation: kyverno.Create, matches: true},
+	}
+
+	for i, tc := range testcases {
+		rule := kyverno.Rule{
+			MatchResources:   kyverno.MatchResources{ResourceDescription: kyverno.ResourceDescription{Kinds: []string{"Namespace"}, Operations: tc.match}},
+			ExcludeResources: kyverno.ExcludeResources{ResourceDescription: kyverno.ResourceDescription{Operations: tc.exclude}},
+		}
+
+		err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{Operation: tc.operation}, []string{}, "", nil)
+		if (err == nil) != tc.matches {
+			t.Errorf("Testcase %d has failed, expected match %v, got error: %v", i, tc.matches, err)
+		}
+	}
+}
+
+// Namespace selectors are matched against the labels of the resource namespace,
+// and against the labels of the resource itself for a Namespace
+func TestResourceDescriptionMatch_NamespaceSelector(t *testing.T) {
+	rawPod := []byte(`{
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {"name": "nginx", "namespace": "shop"}
+	}`)
+	rawNamespace := []byte(`{
+		"apiVersion": "v1",
+		"kind": "Namespace",
+		"metadata": {"name": "shop", "labels": {"env": "prod"}}
+	}`)
+
+	testcases := []struct {
+		rawResource     []byte
+		namespaceLabels map[string]string
+		exclude         bool
+		matches         bool
+	}{
+		{rawResource: rawPod, namespaceLabels: map[string]string{"env": "prod", "team": "shop"}, matches: true},
+		{rawResource: rawPod, namespaceLabels: map[string]string{"env": "dev"}, matches: false},
+		{rawResource: rawPod, namespaceLabels: nil, matches: false},
+		{rawResource: rawPod, namespaceLabels: map[string]string{"env": "prod"}, exclude: true, matches: false},
+		{rawResource: rawPod, namespaceLabels: map[string]string{"env": "dev"}, exclude: true, matches: true},
+		{rawResource: rawNamespace, namespaceLabels: nil, matches: true},
+	}
+
+	for i, tc := range testcases {
+		resource, err := utils.ConvertToUnstructured(tc.rawResource)
+		if err != nil {
+			t.Errorf("unable to convert raw resource to unstructured: %v", err)
+		}
+
+		selector := &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+		rule := kyverno.Rule{MatchResources: kyverno.MatchResources{ResourceDescription: kyverno.ResourceDescription{Kinds: []string{"Pod", "Namespace"}}}}
+		if tc.exclude {
+			rule.ExcludeResources.NamespaceSelector = selector
+		} else {
+			rule.MatchResources.NamespaceSelector = selector
+		}
+
+		err = MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, "", tc.namespaceLabels)
+		if (err == nil) != tc.matches {
+			t.Errorf("Testcase %d has failed, expected match %v, got error: %v", i, tc.matches, err)
+		}
+	}
+}
+
+func TestResourceDescriptionMatch_Annotations(t *testing.T) {
+	rawResource := []byte(`{
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {
+			"name": "nginx",
+			"annotations": {"example.com/owner": "team-a", "example.com/tier": "web"}
+		}
+	}`)
+	resource, err := utils.ConvertToUnstructured(rawResource)
+	if err != nil {
+		t.Errorf("unable to convert raw resource to unstructured: %v", err)
+	}
+
+	testcases := []struct {
+		annotations map[string]string
+		matches     bool
+	}{
+		{annotations: map[string]string{"example.com/owner": "team-a"}, matches: true},
+		{annotations: map[string]string{"example.com/owner": "team-*", "example.com/tier": "web"}, matches: true},
+		{annotations: map[string]string{"example.com/*": "web"}, matches: true},
+		{annotations: map[string]string{"example.com/owner": "team-b"}, matches: false},
+		{annotations: map[string]string{"example.com/owner": "team-a", "example.com/zone": "*"}, matches: false},
+	}
+
+	for i, tc := range testcases {
+		rule := kyverno.Rule{
+			MatchResources: kyverno.MatchResources{ResourceDescription: kyverno.ResourceDescription{Kinds: []string{"Pod"}, Annotations: tc.annotations}},
+		}
+
+		err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, "", nil)
+		if (err == nil) != tc.matches {
+			t.Errorf("Testcase %d has failed, expected match %v, got error: %v", i, tc.matches, err)
+		}
+	}
+}
+
+func TestResourceDescriptionMatch_AnyAll(t *testing.T) {
+	rawResource := []byte(`{
+		"apiVersion": "apps/v1",
+		"kind": "Deployment",
+		"metadata": {
+			"name": "nginx",
+			"namespace": "prod",
+			"labels": {"app": "nginx"}
+		}
+	}`)
+	resource, err := utils.ConvertToUnstructured(rawResource)
+	if err != nil {
+		t.Errorf("unable to convert raw resource to unstructured: %v", err)
+	}
+
+	deploymentsInProd := kyverno.ResourceFilter{ResourceDescription: kyverno.ResourceDescription{Kinds: []string{"Deployment"}, Namespaces: []string{"prod"}}}
+	statefulSets := kyverno.ResourceFilter{ResourceDescription: kyverno.ResourceDescription{Kinds: []string{"StatefulSet"}}}
+	nginx := kyverno.ResourceFilter{ResourceDescription: kyverno.ResourceDescription{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}}}}
+
+	testcases := []struct {
+		match   kyverno.MatchResources
+		exclude kyverno.ExcludeResources
+		matches bool
+	}{
+		{match: kyverno.MatchResources{Any: kyverno.ResourceFilters{statefulSets, deploymentsInProd}}, matches: true},
+		{match: kyverno.MatchResources{Any: kyverno.ResourceFilters{statefulSets}}, matches: false},
+		{match: kyverno.MatchResources{All: kyverno.ResourceFilters{deploymentsInProd, nginx}}, matches: true},
+		{match: kyverno.MatchResources{All: kyverno.ResourceFilters{deploymentsInProd, statefulSets}}, matches: false},
+		{
+			match:   kyverno.MatchResources{Any: kyverno.ResourceFilters{deploymentsInProd}},
+			exclude: kyverno.ExcludeResources{Any: kyverno.ResourceFilters{statefulSets, nginx}},
+			matches: false,
+		},
+		{
+			match:   kyverno.MatchResources{Any: kyverno.ResourceFilters{deploymentsInProd}},
+			exclude: kyverno.ExcludeResources{All: kyverno.ResourceFilters{statefulSets, nginx}},
+			matches: true,
+		},
+		{
+			match:   kyverno.MatchResources{ResourceDescription: kyverno.ResourceDescription{Kinds: []string{"Deployment"}}},
+			exclude: kyverno.ExcludeResources{All: kyverno.ResourceFilters{deploymentsInProd, nginx}},
+			matches: false,
+		},
+	}
+
+	for i, tc := range testcases {
+		rule := kyverno.Rule{MatchResources: tc.match, ExcludeResources: tc.exclude}
+		err := MatchesResourceDescription(*resource, rule, kyverno.RequestInfo{}, []string{}, "", nil)
+		if (err == nil) != tc.matches {
+			t.Errorf("Testcase %d has failed, expected match %v, got error: %v", i, tc.matches, err)
+		}
+	}
+}
+
+func TestResourceDescriptionMatch_KindSelectors(t *testing.T) {
+	rawResource := []byte(`{
+		"apiVersion": "autoscaling/v1",
+		"kind": "Scale",
+		"metadata": {
+			"name": "nginx",
+			"namespace": "prod"
+		}
+	}`)
+	resource, err := utils.ConvertToUnstructured(rawResource)
+	if err != nil {
+		t.Errorf("unable to convert raw resource to unstructured: %v", err)
+	}
+
+	scaleRequest := kyverno.RequestInfo{
+		Operation:   kyverno.Update,
+		SubResource: "scale",
+		ParentKind:  &metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}
+
+	testcases := []struct {
+		kinds         []string
+		admissionInfo kyverno.RequestInfo
+		matches       bool
+	}{
+		{kinds: []string{"Scale"}, matches: true},
+		{kinds: []string{"autoscaling/v1/Scale"}, matches: true},
+		{kinds: []string{"autoscaling/*/Scale"}, matches: true},
+		{kinds: []string{"v1/Scale"}, matches: true},
+		{kinds: []string{"apps/v1/Scale"}, matches: false},
+		{kinds: []string{"v2/Scale"}, matches: false},
+		{kinds: []string{"Sc*"}, matches: true},
+		{kinds: []string{"*"}, matches: true},
+		{kinds: []string{"Deployment/scale"}, matches: false},
+		{kinds: []string{"Deployment/scale"}, admissionInfo: scaleRequest, matches: true},
+		{kinds: []string{"apps/v1/Deployment/scale"}, admissionInfo: scaleRequest, matches: true},
+		{kinds: []string{"Deployment/*"}, admissionInfo: scaleRequest, matches: true},
+		{kinds: []string{"StatefulSet/scale"}, admissionInfo: scaleRequest, matches: false},
+		{kinds: []string{"Deployment/status"}, admissionInfo: scaleRequest, matches: false},
+		{kinds: []string{"Deployment"}, admissionInfo: scaleRequest, matches: false},
+	}
+
+	for i, tc := range testcases {
+		rule := kyverno.Rule{
+			MatchResources: kyverno.MatchResources{ResourceDescription: kyverno.ResourceDescription{Kinds: tc.kinds}},
+		}
+
+		err := MatchesResourceDescription(*resource, rule, tc.admissionInfo, []string{}, "", nil)
+		if (err == nil) != tc.matches {
+			t.Errorf("Testcase %d has failed, expected match %v, got error: %v", i, tc.matches, err)
+		}
+	}
+}
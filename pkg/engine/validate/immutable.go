@@ -0,0 +1,76 @@
+package validate
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidateImmutablePaths returns the paths that are set in the old resource and changed or removed in the new resource.
+// Paths use '/' as separator and '*' matches any key or list index, e.g. /spec/containers/*/image.
+// Paths that are not set in the old resource can be added. Keys containing '/' are escaped as '~1', like in JSON patches.
+func ValidateImmutablePaths(oldResource, newResource interface{}, paths []string) []string {
+	var changed []string
+	for _, path := range paths {
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		changed = append(changed, changedPaths(oldResource, newResource, segments, "")...)
+	}
+	return changed
+}
+
+func changedPaths(oldElement, newElement interface{}, segments []string, path string) []string {
+	if len(segments) == 0 || (len(segments) == 1 && segments[0] == "") {
+		if !reflect.DeepEqual(oldElement, newElement) {
+			return []string{path}
+		}
+		return nil
+	}
+
+	segment := segments[0]
+	var changed []string
+	switch typedOld := oldElement.(type) {
+	case map[string]interface{}:
+		typedNew, _ := newElement.(map[string]interface{})
+		keys := []string{strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")}
+		if segment == "*" {
+			keys = nil
+			for key := range typedOld {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+		}
+
+		for _, key := range keys {
+			oldValue, ok := typedOld[key]
+			if !ok {
+				continue
+			}
+			changed = append(changed, changedPaths(oldValue, typedNew[key], segments[1:], path+"/"+escapeKey(key))...)
+		}
+	case []interface{}:
+		typedNew, _ := newElement.([]interface{})
+		var indexes []int
+		if segment == "*" {
+			for idx := range typedOld {
+				indexes = append(indexes, idx)
+			}
+		} else if idx, err := strconv.Atoi(segment); err == nil && idx >= 0 && idx < len(typedOld) {
+			indexes = append(indexes, idx)
+		}
+
+		for _, idx := range indexes {
+			var newValue interface{}
+			if idx < len(typedNew) {
+				newValue = typedNew[idx]
+			}
+			changed = append(changed, changedPaths(typedOld[idx], newValue, segments[1:], path+"/"+strconv.Itoa(idx))...)
+		}
+	}
+	// the path does not exist in the old resource
+	return changed
+}
+
+func escapeKey(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,77 @@
+package validate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestValidateImmutablePaths(t *testing.T) {
+	rawOld := []byte(`{
+		"metadata": {
+			"labels": {"owner": "team-a"},
+			"annotations": {"example.com/team": "a"}
+		},
+		"spec": {
+			"selector": {"app": "nginx"},
+			"containers": [
+				{"name": "nginx", "image": "nginx:1.19"},
+				{"name": "sidecar", "image": "envoy:1.16"}
+			]
+		}
+	}`)
+
+	testcases := []struct {
+		description string
+		rawNew      []byte
+		paths       []string
+		changed     []string
+	}{
+		{
+			description: "unchanged",
+			rawNew:      rawOld,
+			paths:       []string{"/spec/selector", "/metadata/labels/owner"},
+		},
+		{
+			description: "changed selector",
+			rawNew:      []byte(`{"metadata": {"labels": {"owner": "team-a"}}, "spec": {"selector": {"app": "web"}}}`),
+			paths:       []string{"/spec/selector", "/metadata/labels/owner"},
+			changed:     []string{"/spec/selector"},
+		},
+		{
+			description: "removed label",
+			rawNew:      []byte(`{"metadata": {"labels": {}}, "spec": {"selector": {"app": "nginx"}}}`),
+			paths:       []string{"/metadata/labels/owner"},
+			changed:     []string{"/metadata/labels/owner"},
+		},
+		{
+			description: "added label",
+			rawNew:      []byte(`{"metadata": {"labels": {"owner": "team-a", "tier": "web"}}}`),
+			paths:       []string{"/metadata/labels/tier"},
+		},
+		{
+			description: "wildcard list index",
+			rawNew:      []byte(`{"spec": {"containers": [{"name": "nginx", "image": "nginx:1.19"}, {"name": "sidecar", "image": "envoy:1.17"}]}}`),
+			paths:       []string{"/spec/containers/*/image"},
+			changed:     []string{"/spec/containers/1/image"},
+		},
+		{
+			description: "escaped key",
+			rawNew:      []byte(`{"metadata": {"annotations": {"example.com/team": "b"}}}`),
+			paths:       []string{"/metadata/annotations/example.com~1team"},
+			changed:     []string{"/metadata/annotations/example.com~1team"},
+		},
+	}
+
+	var oldResource interface{}
+	assert.NilError(t, json.Unmarshal(rawOld, &oldResource))
+
+	for _, tc := range testcases {
+		var newResource interface{}
+		assert.NilError(t, json.Unmarshal(tc.rawNew, &newResource))
+
+		changed := ValidateImmutablePaths(oldResource, newResource, tc.paths)
+		assert.DeepEqual(t, changed, tc.changed)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/minio/minio/pkg/wildcard"
@@ -21,6 +23,16 @@ const (
 	greaterThan quantity = 1
 )
 
+// maxRegexCacheSize limits the compiled regular expressions held in the cache,
+// patterns with variables may result in a different expression for each request
+const maxRegexCacheSize = 1000
+
+// regexCache holds the compiled regular expressions of the patterns
+var regexCache = struct {
+	sync.Mutex
+	regexps map[string]*regexp.Regexp
+}{regexps: make(map[string]*regexp.Regexp)}
+
 // ValidateValueWithPattern validates value with operators and wildcards
 func ValidateValueWithPattern(log logr.Logger, value, pattern interface{}) bool {
 	switch typedPattern := pattern.(type) {
@@ -154,6 +166,12 @@ func validateValueWithNilPattern(log logr.Logger, value interface{}) bool {
 
 // Handler for pattern values during validation process
 func validateValueWithStringPatterns(log logr.Logger, value interface{}, pattern string) bool {
+	// regular expressions may contain "|", so they are not split into statements
+	switch operator.GetOperatorFromStringPattern(pattern) {
+	case operator.Regex, operator.NotRegex:
+		return validateValueWithStringPattern(log, value, pattern)
+	}
+
 	statements := strings.Split(pattern, "|")
 	for _, statement := range statements {
 		statement = strings.Trim(statement, " ")
@@ -168,15 +186,145 @@ func validateValueWithStringPatterns(log logr.Logger, value interface{}, pattern
 // Handler for single pattern value during validation process
 // Detects if pattern has a number
 func validateValueWithStringPattern(log logr.Logger, value interface{}, pattern string) bool {
-	operator := operator.GetOperatorFromStringPattern(pattern)
-	pattern = pattern[len(operator):]
+	operatorVariable := operator.GetOperatorFromStringPattern(pattern)
+	switch operatorVariable {
+	case operator.InRange, operator.NotInRange:
+		return validateRange(log, value, pattern, operatorVariable)
+	case operator.Regex, operator.NotRegex:
+		return validateRegex(log, value, pattern[len(operatorVariable):], operatorVariable)
+	}
+
+	pattern = pattern[len(operatorVariable):]
 	number, str := getNumberAndStringPartsFromPattern(pattern)
 
 	if "" == number {
-		return validateString(log, value, str, operator)
+		return validateString(log, value, str, operatorVariable)
+	}
+
+	return validateNumberWithStr(log, value, pattern, operatorVariable)
+}
+
+// validateRange checks if the value lies within the inclusive range (e.g. range:1-10),
+// or outside of it for negated ranges (e.g. !range:100Mi-1Gi)
+func validateRange(log logr.Logger, value interface{}, pattern string, operatorVariable operator.Operator) bool {
+	min, max, ok := operator.SplitRange(pattern)
+	if !ok {
+		log.Info("invalid range", "pattern", pattern)
+		return false
+	}
+
+	inRange := validateNumberWithStr(log, value, min, operator.MoreEqual) &&
+		validateNumberWithStr(log, value, max, operator.LessEqual)
+
+	if operatorVariable == operator.NotInRange {
+		return !inRange
+	}
+
+	return inRange
+}
+
+// validateRegex matches the value against a regular expression
+func validateRegex(log logr.Logger, value interface{}, pattern string, operatorVariable operator.Operator) bool {
+	strValue, err := convertToString(value)
+	if err != nil {
+		log.Error(err, "failed to convert to string")
+		return false
+	}
+
+	re, err := compileRegex(pattern)
+	if err != nil {
+		log.Error(err, "invalid regular expression", "pattern", pattern)
+		return false
+	}
+
+	matched := re.MatchString(strValue)
+	if operatorVariable == operator.NotRegex {
+		return !matched
+	}
+
+	return matched
+}
+
+// compileRegex returns the compiled regular expression from the cache, or compiles and caches it
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	regexCache.Lock()
+	defer regexCache.Unlock()
+
+	if re, ok := regexCache.regexps[pattern]; ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
 	}
 
-	return validateNumberWithStr(log, value, pattern, operator)
+	if len(regexCache.regexps) >= maxRegexCacheSize {
+		regexCache.regexps = make(map[string]*regexp.Regexp)
+	}
+	regexCache.regexps[pattern] = re
+	return re, nil
+}
+
+// CheckStringPattern checks the regular expressions and ranges of a pattern value,
+// the values with variables are checked after the substitution
+func CheckStringPattern(pattern string) error {
+	if strings.Contains(pattern, "{{") {
+		return nil
+	}
+
+	switch operatorVariable := operator.GetOperatorFromStringPattern(pattern); operatorVariable {
+	case operator.Regex, operator.NotRegex:
+		if _, err := regexp.Compile(pattern[len(operatorVariable):]); err != nil {
+			return fmt.Errorf("invalid regular expression %s: %v", pattern, err)
+		}
+		return nil
+	}
+
+	for _, statement := range strings.Split(pattern, "|") {
+		statement = strings.Trim(statement, " ")
+		switch operator.GetOperatorFromStringPattern(statement) {
+		case operator.InRange, operator.NotInRange:
+			min, max, ok := operator.SplitRange(statement)
+			if !ok {
+				return fmt.Errorf("invalid range %s, expected range:<min>-<max>", statement)
+			}
+			if !rangeBoundsOrdered(min, max) {
+				return fmt.Errorf("invalid range %s, the minimum %s is greater than the maximum %s", statement, min, max)
+			}
+		}
+	}
+	return nil
+}
+
+// rangeBoundsOrdered returns true if the minimum of a range is not greater than the maximum,
+// the bounds are compared as durations or quantities, plain numbers are seconds for durations
+func rangeBoundsOrdered(min, max string) bool {
+	minDuration, minErr := time.ParseDuration(min)
+	maxDuration, maxErr := time.ParseDuration(max)
+	if minErr == nil && maxErr == nil {
+		return minDuration <= maxDuration
+	}
+
+	minQuan, minQuanErr := apiresource.ParseQuantity(min)
+	maxQuan, maxQuanErr := apiresource.ParseQuantity(max)
+	if minQuanErr == nil && maxQuanErr == nil {
+		return minQuan.Cmp(maxQuan) <= 0
+	}
+
+	if maxErr == nil {
+		if seconds, err := strconv.ParseFloat(min, 64); err == nil {
+			return time.Duration(seconds*float64(time.Second)) <= maxDuration
+		}
+	}
+
+	if minErr == nil {
+		if seconds, err := strconv.ParseFloat(max, 64); err == nil {
+			return minDuration <= time.Duration(seconds*float64(time.Second))
+		}
+	}
+
+	return false
 }
 
 // Handler for string values
@@ -200,8 +348,8 @@ func validateString(log logr.Logger, value interface{}, pattern string, operator
 	return false
 }
 
-// validateNumberWithStr compares quantity if pattern type is quantity
-//  or a wildcard match to pattern string
+// validateNumberWithStr compares duration if pattern type is duration,
+//  quantity if pattern type is quantity or a wildcard match to pattern string
 func validateNumberWithStr(log logr.Logger, value interface{}, pattern string, operator operator.Operator) bool {
 	typedValue, err := convertToString(value)
 	if err != nil {
@@ -209,9 +357,30 @@ func validateNumberWithStr(log logr.Logger, value interface{}, pattern string, o
 		return false
 	}
 
-	patternQuan, err := apiresource.ParseQuantity(pattern)
-	// 1. nil error - quantity comparison
-	if err == nil {
+	patternQuan, quantityErr := apiresource.ParseQuantity(pattern)
+
+	// 1. duration comparison
+	// patterns such as 1m are valid durations as well as quantities, durations are
+	// only compared if the value is a duration or the pattern is not a quantity
+	if patternDuration, err := time.ParseDuration(pattern); err == nil {
+		if valueDuration, err := time.ParseDuration(typedValue); err == nil {
+			return compareDuration(valueDuration, patternDuration, operator)
+		}
+
+		if quantityErr != nil {
+			// plain numbers are interpreted as seconds
+			seconds, err := strconv.ParseFloat(typedValue, 64)
+			if err != nil {
+				log.Error(err, "invalid duration in resource", "type", fmt.Sprintf("%T", typedValue), "value", typedValue)
+				return false
+			}
+
+			return compareDuration(time.Duration(seconds*float64(time.Second)), patternDuration, operator)
+		}
+	}
+
+	// 2. quantity comparison
+	if quantityErr == nil {
 		valueQuan, err := apiresource.ParseQuantity(typedValue)
 		if err != nil {
 			log.Error(err, "invalid quantity in resource", "type", fmt.Sprintf("%T", typedValue), "value", typedValue)
@@ -221,7 +390,7 @@ func validateNumberWithStr(log logr.Logger, value interface{}, pattern string, o
 		return compareQuantity(valueQuan, patternQuan, operator)
 	}
 
-	// 2. wildcard match
+	// 3. wildcard match
 	if !wildcard.Match(pattern, typedValue) {
 		log.Info("value failed wildcard check", "type", fmt.Sprintf("%T", typedValue), "value", typedValue, "check", pattern)
 		return false
@@ -249,6 +418,25 @@ func compareQuantity(value, pattern apiresource.Quantity, op operator.Operator)
 	return false
 }
 
+func compareDuration(value, pattern time.Duration, op operator.Operator) bool {
+	switch op {
+	case operator.Equal:
+		return value == pattern
+	case operator.NotEqual:
+		return value != pattern
+	case operator.More:
+		return value > pattern
+	case operator.Less:
+		return value < pattern
+	case operator.MoreEqual:
+		return value >= pattern
+	case operator.LessEqual:
+		return value <= pattern
+	}
+
+	return false
+}
+
 // detects numerical and string parts in pattern and returns them
 func getNumberAndStringPartsFromPattern(pattern string) (number, str string) {
 	regexpStr := `^(\d*(\.\d+)?)(.*)`
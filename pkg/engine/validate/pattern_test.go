@@ -322,6 +322,60 @@ func TestValidateQuantity_Operation(t *testing.T) {
 	assert.Assert(t, validateNumberWithStr(log.Log, "0.2", ".5", operator.NotEqual))
 }
 
+func TestValidateDuration_Operation(t *testing.T) {
+	assert.Assert(t, validateNumberWithStr(log.Log, "10s", "30s", operator.LessEqual))
+	assert.Assert(t, !validateNumberWithStr(log.Log, "1m", "30s", operator.LessEqual))
+	assert.Assert(t, validateNumberWithStr(log.Log, "1h", "30m", operator.More))
+	assert.Assert(t, validateNumberWithStr(log.Log, "90s", "1m30s", operator.Equal))
+	assert.Assert(t, validateNumberWithStr(log.Log, 10, "30s", operator.LessEqual))
+	assert.Assert(t, !validateNumberWithStr(log.Log, 60, "30s", operator.LessEqual))
+	assert.Assert(t, !validateNumberWithStr(log.Log, "abc", "30s", operator.LessEqual))
+	// 1m is a quantity as well as a duration
+	assert.Assert(t, validateNumberWithStr(log.Log, 1, "500m", operator.MoreEqual))
+}
+
+func TestValidateValueWithPattern_Range(t *testing.T) {
+	assert.Assert(t, ValidateValueWithPattern(log.Log, 5, "range:1-10"))
+	assert.Assert(t, ValidateValueWithPattern(log.Log, 1, "range:1-10"))
+	assert.Assert(t, ValidateValueWithPattern(log.Log, 10, "range:1-10"))
+	assert.Assert(t, !ValidateValueWithPattern(log.Log, 11, "range:1-10"))
+	assert.Assert(t, ValidateValueWithPattern(log.Log, "512Mi", "range:100Mi-1Gi"))
+	assert.Assert(t, !ValidateValueWithPattern(log.Log, "512Mi", "!range:100Mi-1Gi"))
+	assert.Assert(t, ValidateValueWithPattern(log.Log, "2Gi", "!range:100Mi-1Gi"))
+	assert.Assert(t, ValidateValueWithPattern(log.Log, "50Mi", "!range:100Mi-1Gi"))
+	assert.Assert(t, ValidateValueWithPattern(log.Log, "45s", "range:10s-1m"))
+	assert.Assert(t, !ValidateValueWithPattern(log.Log, "2m", "range:10s-1m"))
+	assert.Assert(t, ValidateValueWithPattern(log.Log, "20", "range:1-10 | range:20-30"))
+	// values shaped like ranges are compared literally
+	assert.Assert(t, ValidateValueWithPattern(log.Log, "2020-01", "2020-01"))
+	assert.Assert(t, !ValidateValueWithPattern(log.Log, "5", "1-10"))
+	assert.Assert(t, !ValidateValueWithPattern(log.Log, "5", "range:a-b"))
+}
+
+func TestCheckStringPattern(t *testing.T) {
+	assert.NilError(t, CheckStringPattern("range:1-10"))
+	assert.NilError(t, CheckStringPattern("!range:100Mi-1Gi | range:10s-1m"))
+	assert.NilError(t, CheckStringPattern("range:1-1h"))
+	assert.NilError(t, CheckStringPattern("regex:^(dev|test|prod)$"))
+	assert.NilError(t, CheckStringPattern("2020-01"))
+	assert.NilError(t, CheckStringPattern("range:{{min}}-{{max}}"))
+	assert.ErrorContains(t, CheckStringPattern("range:10-1"), "greater than the maximum")
+	assert.ErrorContains(t, CheckStringPattern("range:1Gi-100Mi"), "greater than the maximum")
+	assert.ErrorContains(t, CheckStringPattern("range:1m-10s"), "greater than the maximum")
+	assert.ErrorContains(t, CheckStringPattern("range:1"), "invalid range")
+	assert.ErrorContains(t, CheckStringPattern("!regex:^(abc"), "invalid regular expression")
+}
+
+func TestValidateValueWithPattern_Regex(t *testing.T) {
+	assert.Assert(t, ValidateValueWithPattern(log.Log, "my-app", "regex:^[a-z]+(-[a-z]+)*$"))
+	assert.Assert(t, !ValidateValueWithPattern(log.Log, "My_App", "regex:^[a-z]+(-[a-z]+)*$"))
+	assert.Assert(t, ValidateValueWithPattern(log.Log, "prod", "regex:^(dev|test|prod)$"))
+	assert.Assert(t, !ValidateValueWithPattern(log.Log, "prod", "!regex:^(dev|test|prod)$"))
+	assert.Assert(t, ValidateValueWithPattern(log.Log, "stage", "!regex:^(dev|test|prod)$"))
+	assert.Assert(t, ValidateValueWithPattern(log.Log, 8080, "regex:^80[0-9]{2}$"))
+	assert.Assert(t, !ValidateValueWithPattern(log.Log, "abc", "regex:^(abc"))
+}
+
 func TestGetOperatorFromStringPattern_OneChar(t *testing.T) {
 	assert.Equal(t, operator.GetOperatorFromStringPattern("f"), operator.Equal)
 }
@@ -13,9 +13,18 @@ import (
 	"github.com/nirmata/kyverno/pkg/engine/operator"
 )
 
+// ErrGlobalAnchorNotSatisfied is returned when none of the resource elements satisfy
+// a global anchor of the pattern, in which case the rule is skipped
+var ErrGlobalAnchorNotSatisfied = errors.New("global anchor condition is not satisfied")
+
 // ValidateResourceWithPattern is a start of element-by-element validation process
 // It assumes that validation is started from root, so "/" is passed
 func ValidateResourceWithPattern(log logr.Logger, resource, pattern interface{}) (string, error) {
+	if !meetsGlobalConditions(log, resource, pattern, pattern, "/") {
+		log.V(4).Info("global anchor condition is not satisfied, skipping the pattern")
+		return "", ErrGlobalAnchorNotSatisfied
+	}
+
 	path, err := validateResourceElement(log, resource, pattern, pattern, "/")
 	if err != nil {
 		return path, err
@@ -89,8 +98,8 @@ func validateMap(log logr.Logger, resourceMap, patternMap map[string]interface{}
 		// if there are resource values at same level, then anchor acts as conditional instead of a strict check
 		// but if there are non then its a if then check
 		if err != nil {
-			// If Conditional anchor fails then we dont process the resources
-			if anchor.IsConditionAnchor(key) {
+			// If Conditional or Global anchor fails then we dont process the resources
+			if anchor.IsConditionAnchor(key) || anchor.IsGlobalAnchor(key) {
 				log.Error(err, "condition anchor did not satisfy, wont process the resource")
 				return "", nil
 			}
@@ -269,6 +278,74 @@ func validateArrayOfMaps(log logr.Logger, resourceMapArray []interface{}, patter
 	return "", nil
 }
 
+// meetsGlobalConditions checks that every global anchor in the pattern is satisfied
+// by at least one of the resource elements it applies to
+func meetsGlobalConditions(log logr.Logger, resourceElement, patternElement, originPattern interface{}, path string) bool {
+	if !hasGlobalAnchors(patternElement) {
+		return true
+	}
+
+	switch typedPatternElement := patternElement.(type) {
+	case map[string]interface{}:
+		resourceMap, ok := resourceElement.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		for key, value := range typedPatternElement {
+			if anchor.IsGlobalAnchor(key) {
+				handler := anchor.CreateElementHandler(key, value, path)
+				if _, err := handler.Handle(validateResourceElement, resourceMap, originPattern); err != nil {
+					log.V(4).Info("global anchor is not satisfied", "path", path+key, "reason", err.Error())
+					return false
+				}
+				continue
+			}
+
+			rawKey := getRawKeyIfWrappedWithAttributes(key)
+			if !meetsGlobalConditions(log, resourceMap[rawKey], value, originPattern, path+rawKey+"/") {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		resourceArray, ok := resourceElement.([]interface{})
+		if !ok || len(typedPatternElement) == 0 {
+			return false
+		}
+
+		// a single resource element satisfying the conditions is sufficient
+		for i, resourceElement := range resourceArray {
+			currentPath := path + strconv.Itoa(i) + "/"
+			if meetsGlobalConditions(log, resourceElement, typedPatternElement[0], originPattern, currentPath) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// hasGlobalAnchors checks if the pattern contains a global anchor at any level
+func hasGlobalAnchors(pattern interface{}) bool {
+	switch typed := pattern.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			if anchor.IsGlobalAnchor(key) || hasGlobalAnchors(value) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, value := range typed {
+			if hasGlobalAnchors(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func isStringIsReference(str string) bool {
 	if len(str) < len(operator.ReferenceSign) {
 		return false
@@ -1355,3 +1355,72 @@ func TestValidateMapElement_OneElementInArrayNotPass(t *testing.T) {
 	assert.Equal(t, path, "/0/object/0/key2/")
 	assert.Assert(t, err != nil)
 }
+
+func TestValidateResourceWithPattern_GlobalAnchor(t *testing.T) {
+	rawPattern := []byte(`{
+		"metadata": {
+			"labels": {
+				"tier": "?*"
+			}
+		},
+		"spec": {
+			"containers": [
+				{
+					"<(image)": "*:latest",
+					"imagePullPolicy": "Always"
+				}
+			]
+		}
+	}`)
+
+	testCases := []struct {
+		description string
+		resource    []byte
+		path        string
+		err         error
+		fails       bool
+	}{
+		{
+			description: "no container matches the global anchor, pattern is skipped",
+			resource:    []byte(`{"metadata":{"name":"test"},"spec":{"containers":[{"image":"nginx:1.19","imagePullPolicy":"IfNotPresent"}]}}`),
+			err:         ErrGlobalAnchorNotSatisfied,
+			fails:       true,
+		},
+		{
+			description: "container matches the global anchor, label is required",
+			resource:    []byte(`{"metadata":{"name":"test"},"spec":{"containers":[{"image":"nginx:latest","imagePullPolicy":"Always"}]}}`),
+			path:        "/metadata/labels/",
+			fails:       true,
+		},
+		{
+			description: "container matches the global anchor, image pull policy is required on that container",
+			resource:    []byte(`{"metadata":{"labels":{"tier":"web"}},"spec":{"containers":[{"image":"nginx:1.19","imagePullPolicy":"IfNotPresent"},{"image":"busybox:latest","imagePullPolicy":"IfNotPresent"}]}}`),
+			path:        "/spec/containers/1/imagePullPolicy/",
+			fails:       true,
+		},
+		{
+			description: "container matches the global anchor and satisfies the pattern",
+			resource:    []byte(`{"metadata":{"labels":{"tier":"web"}},"spec":{"containers":[{"image":"nginx:1.19","imagePullPolicy":"IfNotPresent"},{"image":"busybox:latest","imagePullPolicy":"Always"}]}}`),
+		},
+	}
+
+	var pattern interface{}
+	assert.NilError(t, json.Unmarshal(rawPattern, &pattern))
+
+	for _, tc := range testCases {
+		var resource interface{}
+		assert.NilError(t, json.Unmarshal(tc.resource, &resource))
+
+		path, err := ValidateResourceWithPattern(log.Log, resource, pattern)
+		if !tc.fails {
+			assert.NilError(t, err, tc.description)
+			continue
+		}
+
+		assert.Assert(t, err != nil, tc.description)
+		assert.Equal(t, path, tc.path, tc.description)
+		if tc.err != nil {
+			assert.Equal(t, err, tc.err, tc.description)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package engine
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -12,6 +13,7 @@ import (
 	"github.com/nirmata/kyverno/pkg/engine/utils"
 	"github.com/nirmata/kyverno/pkg/engine/validate"
 	"github.com/nirmata/kyverno/pkg/engine/variables"
+	"github.com/nirmata/kyverno/pkg/metrics"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -23,7 +25,6 @@ func Validate(policyContext PolicyContext) (resp response.EngineResponse) {
 	newR := policyContext.NewResource
 	oldR := policyContext.OldResource
 	ctx := policyContext.Context
-	admissionInfo := policyContext.AdmissionInfo
 	logger := log.Log.WithName("EngineValidate").WithValues("policy", policy.Name)
 
 	if reflect.DeepEqual(newR, unstructured.Unstructured{}) {
@@ -57,24 +58,25 @@ func Validate(policyContext PolicyContext) (resp response.EngineResponse) {
 		}
 		resp.PatchedResource = resource
 		startResultResponse(&resp, policy, resource)
+		setValidationFailureActions(&resp, policy, resource.GetNamespace(), policyContext.NamespaceLabels)
 		endResultResponse(logger, &resp, startTime)
 	}()
 
 	// If request is delete, newR will be empty
 	if reflect.DeepEqual(newR, unstructured.Unstructured{}) {
-		return *isRequestDenied(logger, ctx, policy, oldR, admissionInfo, policyContext.ExcludeGroupRole)
+		return *isRequestDenied(logger, policyContext, oldR)
 	}
 
-	if denyResp := isRequestDenied(logger, ctx, policy, newR, admissionInfo, policyContext.ExcludeGroupRole); !denyResp.IsSuccessful() {
+	if denyResp := isRequestDenied(logger, policyContext, newR); !denyResp.IsSuccessful() {
 		return *denyResp
 	}
 
 	if reflect.DeepEqual(oldR, unstructured.Unstructured{}) {
-		return *validateResource(logger, ctx, policy, newR, admissionInfo, policyContext.ExcludeGroupRole)
+		return *validateResource(logger, policyContext, newR)
 	}
 
-	oldResponse := validateResource(logger, ctx, policy, oldR, admissionInfo, policyContext.ExcludeGroupRole)
-	newResponse := validateResource(logger, ctx, policy, newR, admissionInfo, policyContext.ExcludeGroupRole)
+	oldResponse := validateResource(logger, policyContext, oldR)
+	newResponse := validateResource(logger, policyContext, newR)
 	if !isSameResponse(oldResponse, newResponse) {
 		return *newResponse
 	}
@@ -84,17 +86,54 @@ func Validate(policyContext PolicyContext) (resp response.EngineResponse) {
 func startResultResponse(resp *response.EngineResponse, policy kyverno.ClusterPolicy, newR unstructured.Unstructured) {
 	// set policy information
 	resp.PolicyResponse.Policy = policy.Name
+	resp.PolicyResponse.PolicyNamespace = policy.Namespace
 	// resource details
 	resp.PolicyResponse.Resource.Name = newR.GetName()
 	resp.PolicyResponse.Resource.Namespace = newR.GetNamespace()
 	resp.PolicyResponse.Resource.Kind = newR.GetKind()
 	resp.PolicyResponse.Resource.APIVersion = newR.GetAPIVersion()
-	resp.PolicyResponse.ValidationFailureAction = policy.Spec.ValidationFailureAction
+}
+
+// setValidationFailureActions sets the ValidationFailureAction of the policy and of each rule for the resource namespace
+func setValidationFailureActions(resp *response.EngineResponse, policy kyverno.ClusterPolicy, namespace string, namespaceLabels map[string]string) {
+	resp.PolicyResponse.ValidationFailureAction = getValidationFailureAction(policy, namespace, namespaceLabels)
+	for i, ruleResp := range resp.PolicyResponse.Rules {
+		resp.PolicyResponse.Rules[i].ValidationFailureAction = resp.PolicyResponse.ValidationFailureAction
+		for _, rule := range policy.Spec.Rules {
+			if rule.Name == ruleResp.Name && rule.ValidationFailureAction != "" {
+				resp.PolicyResponse.Rules[i].ValidationFailureAction = rule.ValidationFailureAction
+				break
+			}
+		}
+	}
+}
+
+// getValidationFailureAction returns the ValidationFailureAction of the first override that selects the namespace,
+// and the ValidationFailureAction of the policy otherwise. Overrides do not apply to cluster-wide resources.
+func getValidationFailureAction(policy kyverno.ClusterPolicy, namespace string, namespaceLabels map[string]string) string {
+	if namespace == "" {
+		return policy.Spec.ValidationFailureAction
+	}
+
+	for _, override := range policy.Spec.ValidationFailureActionOverrides {
+		if len(override.Namespaces) > 0 && !checkNameSpace(override.Namespaces, namespace) {
+			continue
+		}
+
+		if override.NamespaceSelector != nil {
+			if ok, err := checkSelector(override.NamespaceSelector, namespaceLabels); err != nil || !ok {
+				continue
+			}
+		}
+		return override.Action
+	}
+	return policy.Spec.ValidationFailureAction
 }
 
 func endResultResponse(log logr.Logger, resp *response.EngineResponse, startTime time.Time) {
 	resp.PolicyResponse.ProcessingTime = time.Since(startTime)
 	log.V(4).Info("finshed processing", "processingTime", resp.PolicyResponse.ProcessingTime.String(), "validationRulesApplied", resp.PolicyResponse.RulesAppliedCount)
+	metrics.RecordEngineResponse(utils.Validation.String(), *resp)
 }
 
 func incrementAppliedCount(resp *response.EngineResponse) {
@@ -102,40 +141,56 @@ func incrementAppliedCount(resp *response.EngineResponse) {
 	resp.PolicyResponse.RulesAppliedCount++
 }
 
-func isRequestDenied(log logr.Logger, ctx context.EvalInterface, policy kyverno.ClusterPolicy, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo, excludeGroupRole []string) *response.EngineResponse {
+// isRequestDenied evaluates the deny rules of the policy on the resource, which is either the new or the old resource of the policy context
+func isRequestDenied(log logr.Logger, policyContext PolicyContext, resource unstructured.Unstructured) *response.EngineResponse {
 	resp := &response.EngineResponse{}
+	policy := policyContext.Policy
+	ctx := policyContext.Context
 	if policy.HasAutoGenAnnotation() && excludePod(resource) {
 		log.V(5).Info("Skip applying policy, Pod has ownerRef set", "policy", policy.GetName())
 		return resp
 	}
 	excludeResource := []string{}
-	if len(excludeGroupRole) > 0 {
-		excludeResource = excludeGroupRole
+	if len(policyContext.ExcludeGroupRole) > 0 {
+		excludeResource = policyContext.ExcludeGroupRole
 	}
 	for _, rule := range policy.Spec.Rules {
 		if !rule.HasValidate() {
 			continue
 		}
 
-		if err := MatchesResourceDescription(resource, rule, admissionInfo, excludeResource); err != nil {
+		if err := MatchesResourceDescription(resource, rule, policyContext.AdmissionInfo, excludeResource, policy.Namespace, policyContext.NamespaceLabels); err != nil {
 			log.V(4).Info("resource fails the match description", "reason", err.Error())
 			continue
 		}
 
+		if err := LoadContext(log, rule.Context, policyContext); err != nil {
+			log.V(4).Info("failed to load context", "reason", err.Error())
+			resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, contextFailureResponse(rule, utils.Validation, err))
+			continue
+		}
+
 		preconditionsCopy := copyConditions(rule.Conditions)
 
-		if !variables.EvaluateConditions(log, ctx, preconditionsCopy) {
-			log.V(4).Info("resource fails the preconditions")
+		if pass, messages := variables.EvaluateConditionsWithMessages(log, ctx, preconditionsCopy); !pass {
+			log.V(4).Info("resource fails the preconditions", "messages", messages)
 			continue
 		}
 
 		if rule.Validation.Deny != nil {
 			denyConditionsCopy := copyConditions(rule.Validation.Deny.Conditions)
-			if len(rule.Validation.Deny.Conditions) == 0 || variables.EvaluateConditions(log, ctx, denyConditionsCopy) {
+			denied, messages := variables.EvaluateConditionsWithMessages(log, ctx, denyConditionsCopy)
+			if len(rule.Validation.Deny.Conditions) == 0 || denied {
+				// the messages of the conditions which denied the request are more specific than the rule message
+				message := rule.Validation.Message
+				if len(messages) > 0 {
+					message = strings.Join(messages, "; ")
+				}
+
 				ruleResp := response.RuleResponse{
 					Name:    rule.Name,
 					Type:    utils.Validation.String(),
-					Message: rule.Validation.Message,
+					Message: message,
 					Success: false,
 				}
 				resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, ruleResp)
@@ -147,8 +202,11 @@ func isRequestDenied(log logr.Logger, ctx context.EvalInterface, policy kyverno.
 	return resp
 }
 
-func validateResource(log logr.Logger, ctx context.EvalInterface, policy kyverno.ClusterPolicy, resource unstructured.Unstructured, admissionInfo kyverno.RequestInfo, excludeGroupRole []string) *response.EngineResponse {
+// validateResource applies the validation rules of the policy to the resource, which is either the new or the old resource of the policy context
+func validateResource(log logr.Logger, policyContext PolicyContext, resource unstructured.Unstructured) *response.EngineResponse {
 	resp := &response.EngineResponse{}
+	policy := policyContext.Policy
+	ctx := policyContext.Context
 
 	if policy.HasAutoGenAnnotation() && excludePod(resource) {
 		log.V(5).Info("Skip applying policy, Pod has ownerRef set", "policy", policy.GetName())
@@ -156,8 +214,8 @@ func validateResource(log logr.Logger, ctx context.EvalInterface, policy kyverno
 	}
 
 	excludeResource := []string{}
-	if len(excludeGroupRole) > 0 {
-		excludeResource = excludeGroupRole
+	if len(policyContext.ExcludeGroupRole) > 0 {
+		excludeResource = policyContext.ExcludeGroupRole
 	}
 
 	for _, rule := range policy.Spec.Rules {
@@ -168,22 +226,44 @@ func validateResource(log logr.Logger, ctx context.EvalInterface, policy kyverno
 		// check if the resource satisfies the filter conditions defined in the rule
 		// TODO: this needs to be extracted, to filter the resource so that we can avoid passing resources that
 		// dont satisfy a policy rule resource description
-		if err := MatchesResourceDescription(resource, rule, admissionInfo, excludeResource); err != nil {
+		if err := MatchesResourceDescription(resource, rule, policyContext.AdmissionInfo, excludeResource, policy.Namespace, policyContext.NamespaceLabels); err != nil {
 			log.V(4).Info("resource fails the match description", "reason", err.Error())
 			continue
 		}
 
+		if err := LoadContext(log, rule.Context, policyContext); err != nil {
+			log.V(4).Info("failed to load context", "reason", err.Error())
+			incrementAppliedCount(resp)
+			resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, contextFailureResponse(rule, utils.Validation, err))
+			continue
+		}
+
 		// operate on the copy of the conditions, as we perform variable substitution
 		preconditionsCopy := copyConditions(rule.Conditions)
 		// evaluate pre-conditions
 		// - handle variable subsitutions
-		if !variables.EvaluateConditions(log, ctx, preconditionsCopy) {
-			log.V(4).Info("resource fails the preconditions")
+		if pass, messages := variables.EvaluateConditionsWithMessages(log, ctx, preconditionsCopy); !pass {
+			log.V(4).Info("resource fails the preconditions", "messages", messages)
+			resp.PolicyResponse.RulesSkipped = append(resp.PolicyResponse.RulesSkipped, rule.Name)
 			continue
 		}
 
 		if rule.Validation.Pattern != nil || rule.Validation.AnyPattern != nil {
-			ruleResponse := validatePatterns(log, ctx, resource, rule)
+			if ruleResponse, skip := validatePatterns(log, ctx, resource, rule); !skip {
+				incrementAppliedCount(resp)
+				resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, ruleResponse)
+			}
+		}
+
+		if rule.Validation.ForEachValidation != nil {
+			ruleResponse := validateForEach(log, ctx, resource, rule)
+			incrementAppliedCount(resp)
+			resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, ruleResponse)
+		}
+
+		// immutable paths are only checked for updates
+		if len(rule.Validation.Immutable) > 0 && !reflect.DeepEqual(policyContext.OldResource, unstructured.Unstructured{}) {
+			ruleResponse := validateImmutable(log, policyContext.OldResource, resource, rule)
 			incrementAppliedCount(resp)
 			resp.PolicyResponse.Rules = append(resp.PolicyResponse.Rules, ruleResponse)
 		}
@@ -231,8 +311,9 @@ func isSameRules(oldRules []response.RuleResponse, newRules []response.RuleRespo
 	return true
 }
 
-// validatePatterns validate pattern and anyPattern
-func validatePatterns(log logr.Logger, ctx context.EvalInterface, resource unstructured.Unstructured, rule kyverno.Rule) (resp response.RuleResponse) {
+// validatePatterns validate pattern and anyPattern, the rule is skipped
+// if the global anchors of the patterns are not satisfied
+func validatePatterns(log logr.Logger, ctx context.EvalInterface, resource unstructured.Unstructured, rule kyverno.Rule) (resp response.RuleResponse, skip bool) {
 	startTime := time.Now()
 	logger := log.WithValues("rule", rule.Name)
 	logger.V(4).Info("start processing rule", "startTime", startTime)
@@ -256,26 +337,32 @@ func validatePatterns(log logr.Logger, ctx context.EvalInterface, resource unstr
 			resp.Success = false
 			resp.Message = fmt.Sprintf("Validation error: %s; Validation rule '%s' failed. '%s'",
 				rule.Validation.Message, rule.Name, err)
-			return resp
+			return resp, false
 		}
 
 		if path, err := validate.ValidateResourceWithPattern(logger, resource.Object, pattern); err != nil {
+			if err == validate.ErrGlobalAnchorNotSatisfied {
+				logger.V(4).Info("global anchor not satisfied, skipping rule")
+				return resp, true
+			}
+
 			// validation failed
 			resp.Success = false
 			resp.Message = fmt.Sprintf("Validation error: %s; Validation rule %s failed at path %s",
 				rule.Validation.Message, rule.Name, path)
-			return resp
+			return resp, false
 		}
 		// rule application successful
 		logger.V(4).Info("successfully processed rule")
 		resp.Success = true
 		resp.Message = fmt.Sprintf("Validation rule '%s' succeeded.", rule.Name)
-		return resp
+		return resp, false
 	}
 
 	if validationRule.AnyPattern != nil {
 		var failedSubstitutionsErrors []error
 		var failedAnyPatternsErrors []error
+		var skippedPatterns int
 		var err error
 		for idx, pattern := range validationRule.AnyPattern {
 			if pattern, err = variables.SubstituteVars(logger, ctx, pattern); err != nil {
@@ -287,7 +374,12 @@ func validatePatterns(log logr.Logger, ctx context.EvalInterface, resource unstr
 			if err == nil {
 				resp.Success = true
 				resp.Message = fmt.Sprintf("Validation rule '%s' anyPattern[%d] succeeded.", rule.Name, idx)
-				return resp
+				return resp, false
+			}
+			if err == validate.ErrGlobalAnchorNotSatisfied {
+				logger.V(4).Info(fmt.Sprintf("global anchor not satisfied, skipping anyPattern[%d]", idx))
+				skippedPatterns++
+				continue
 			}
 			logger.V(4).Info(fmt.Sprintf("validation rule failed for anyPattern[%d]", idx), "message", rule.Validation.Message)
 			patternErr := fmt.Errorf("anyPattern[%d] failed; %s", idx, err)
@@ -298,7 +390,13 @@ func validatePatterns(log logr.Logger, ctx context.EvalInterface, resource unstr
 		if len(failedSubstitutionsErrors) > 0 {
 			resp.Success = false
 			resp.Message = fmt.Sprintf("Substitutions failed: %v", failedSubstitutionsErrors)
-			return resp
+			return resp, false
+		}
+
+		// skip the rule if none of the patterns apply to the resource
+		if skippedPatterns == len(validationRule.AnyPattern) {
+			logger.V(4).Info("global anchors not satisfied, skipping rule")
+			return resp, true
 		}
 
 		// Any Pattern validation errors
@@ -314,8 +412,33 @@ func validatePatterns(log logr.Logger, ctx context.EvalInterface, resource unstr
 			} else {
 				resp.Message = rule.Validation.Message
 			}
-			return resp
+			return resp, false
 		}
 	}
-	return response.RuleResponse{}
+	return response.RuleResponse{}, false
+}
+
+// validateImmutable checks that the immutable paths of the old resource are not changed or removed
+func validateImmutable(log logr.Logger, oldResource, resource unstructured.Unstructured, rule kyverno.Rule) (resp response.RuleResponse) {
+	startTime := time.Now()
+	logger := log.WithValues("rule", rule.Name)
+	logger.V(4).Info("start processing rule", "startTime", startTime)
+	resp.Name = rule.Name
+	resp.Type = utils.Validation.String()
+	defer func() {
+		resp.RuleStats.ProcessingTime = time.Since(startTime)
+		logger.V(4).Info("finished processing rule", "processingTime", resp.RuleStats.ProcessingTime.String())
+	}()
+
+	if changed := validate.ValidateImmutablePaths(oldResource.Object, resource.Object, rule.Validation.Immutable); len(changed) > 0 {
+		resp.Success = false
+		resp.Message = fmt.Sprintf("Validation error: %s; Validation rule %s failed, immutable paths changed: %s",
+			rule.Validation.Message, rule.Name, strings.Join(changed, ", "))
+		return resp
+	}
+
+	logger.V(4).Info("successfully processed rule")
+	resp.Success = true
+	resp.Message = fmt.Sprintf("Validation rule '%s' succeeded.", rule.Name)
+	return resp
 }
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"k8s.io/api/admission/v1beta1"
@@ -1721,3 +1722,242 @@ func Test_denyFeatureIssue744(t *testing.T) {
 		t.Errorf("Testcase has failed, policy: %v", policy.Name)
 	}
 }
+
+func TestValidate_Immutable(t *testing.T) {
+	rawPolicy := []byte(`{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {"name": "immutable-selector"},
+		"spec": {
+			"rules": [
+				{
+					"name": "immutable-selector",
+					"match": {"resources": {"kinds": ["Service"]}},
+					"validate": {
+						"message": "the selector cannot be changed",
+						"immutable": ["/spec/selector", "/metadata/labels/owner"]
+					}
+				}
+			]
+		}
+	}`)
+
+	rawOldResource := []byte(`{
+		"apiVersion": "v1",
+		"kind": "Service",
+		"metadata": {"name": "nginx", "labels": {"owner": "team-a"}},
+		"spec": {"selector": {"app": "nginx"}}
+	}`)
+
+	testcases := []struct {
+		description string
+		rawResource []byte
+		success     bool
+	}{
+		{
+			description: "label added",
+			rawResource: []byte(`{
+				"apiVersion": "v1",
+				"kind": "Service",
+				"metadata": {"name": "nginx", "labels": {"owner": "team-a", "tier": "web"}},
+				"spec": {"selector": {"app": "nginx"}}
+			}`),
+			success: true,
+		},
+		{
+			description: "selector changed",
+			rawResource: []byte(`{
+				"apiVersion": "v1",
+				"kind": "Service",
+				"metadata": {"name": "nginx", "labels": {"owner": "team-a"}},
+				"spec": {"selector": {"app": "web"}}
+			}`),
+			success: false,
+		},
+	}
+
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal(rawPolicy, &policy))
+	oldResource, err := utils.ConvertToUnstructured(rawOldResource)
+	assert.NilError(t, err)
+
+	for _, tc := range testcases {
+		resource, err := utils.ConvertToUnstructured(tc.rawResource)
+		assert.NilError(t, err)
+
+		er := Validate(PolicyContext{Policy: policy, NewResource: *resource, OldResource: *oldResource, Context: context.NewContext()})
+		assert.Equal(t, er.IsSuccessful(), tc.success, tc.description)
+		if !tc.success {
+			assert.Equal(t, er.PolicyResponse.Rules[0].Message,
+				"Validation error: the selector cannot be changed; Validation rule immutable-selector failed, immutable paths changed: /spec/selector", tc.description)
+		}
+	}
+}
+
+func TestValidate_ValidationFailureActionOverrides(t *testing.T) {
+	rawPolicy := []byte(`{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {"name": "require-labels"},
+		"spec": {
+			"validationFailureAction": "audit",
+			"validationFailureActionOverrides": [
+				{"action": "enforce", "namespaceSelector": {"matchLabels": {"env": "prod"}}}
+			],
+			"rules": [
+				{
+					"name": "require-app",
+					"match": {"resources": {"kinds": ["Pod"]}},
+					"validate": {"pattern": {"metadata": {"labels": {"app": "?*"}}}}
+				},
+				{
+					"name": "require-team",
+					"validationFailureAction": "audit",
+					"match": {"resources": {"kinds": ["Pod"]}},
+					"validate": {"pattern": {"metadata": {"labels": {"team": "?*"}}}}
+				}
+			]
+		}
+	}`)
+
+	rawResource := []byte(`{
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {"name": "nginx", "namespace": "web"},
+		"spec": {"containers": [{"name": "nginx", "image": "nginx"}]}
+	}`)
+
+	testcases := []struct {
+		namespaceLabels map[string]string
+		actions         []string
+	}{
+		{
+			namespaceLabels: map[string]string{"env": "prod"},
+			actions:         []string{"enforce", "audit"},
+		},
+		{
+			namespaceLabels: map[string]string{"env": "dev"},
+			actions:         []string{"audit", "audit"},
+		},
+	}
+
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal(rawPolicy, &policy))
+	resource, err := utils.ConvertToUnstructured(rawResource)
+	assert.NilError(t, err)
+
+	for _, tc := range testcases {
+		er := Validate(PolicyContext{Policy: policy, NewResource: *resource, Context: context.NewContext(), NamespaceLabels: tc.namespaceLabels})
+		assert.Equal(t, len(er.PolicyResponse.Rules), len(tc.actions))
+		assert.Equal(t, er.PolicyResponse.ValidationFailureAction, tc.actions[0])
+		for i, rule := range er.PolicyResponse.Rules {
+			assert.Assert(t, !rule.Success)
+			assert.Equal(t, rule.ValidationFailureAction, tc.actions[i], rule.Name)
+		}
+	}
+}
+
+func TestValidate_DenyConditionMessages(t *testing.T) {
+	rawPolicy := []byte(`{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {"name": "restrict-service-type"},
+		"spec": {
+			"rules": [
+				{
+					"name": "restrict-service-type",
+					"match": {"resources": {"kinds": ["Service"]}},
+					"validate": {
+						"message": "the service is not allowed",
+						"deny": {
+							"conditions": [
+								{
+									"any": [
+										{"key": "{{request.object.spec.type}}", "operator": "Equals", "value": "NodePort", "message": "service {{request.object.metadata.name}} uses a NodePort"},
+										{"key": "{{request.object.spec.type}}", "operator": "Equals", "value": "LoadBalancer", "message": "service {{request.object.metadata.name}} uses a LoadBalancer"}
+									]
+								}
+							]
+						}
+					}
+				}
+			]
+		}
+	}`)
+
+	testcases := []struct {
+		serviceType string
+		success     bool
+		message     string
+	}{
+		{serviceType: "ClusterIP", success: true},
+		{serviceType: "NodePort", success: false, message: "service nginx uses a NodePort"},
+		{serviceType: "LoadBalancer", success: false, message: "service nginx uses a LoadBalancer"},
+	}
+
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal(rawPolicy, &policy))
+
+	for _, tc := range testcases {
+		rawResource := []byte(fmt.Sprintf(`{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "nginx"}, "spec": {"type": "%s"}}`, tc.serviceType))
+		resource, err := utils.ConvertToUnstructured(rawResource)
+		assert.NilError(t, err)
+
+		ctx := context.NewContext()
+		assert.NilError(t, ctx.AddResource(rawResource))
+
+		er := Validate(PolicyContext{Policy: policy, NewResource: *resource, Context: ctx})
+		assert.Equal(t, er.IsSuccessful(), tc.success, tc.serviceType)
+		if !tc.success {
+			assert.Equal(t, er.PolicyResponse.Rules[0].Message, tc.message, tc.serviceType)
+		}
+	}
+}
+
+func TestValidate_GlobalAnchor(t *testing.T) {
+	rawPolicy := []byte(`{
+		"apiVersion": "kyverno.io/v1",
+		"kind": "ClusterPolicy",
+		"metadata": {"name": "latest-image-pull-policy"},
+		"spec": {
+			"rules": [
+				{
+					"name": "latest-image-pull-policy",
+					"match": {"resources": {"kinds": ["Pod"]}},
+					"validate": {
+						"message": "images with the latest tag must be pulled always",
+						"pattern": {
+							"spec": {
+								"containers": [
+									{"<(image)": "*:latest", "imagePullPolicy": "Always"}
+								]
+							}
+						}
+					}
+				}
+			]
+		}
+	}`)
+
+	testcases := []struct {
+		image   string
+		rules   int
+		success bool
+	}{
+		{image: "nginx:1.19", rules: 0, success: true},
+		{image: "nginx:latest", rules: 1, success: false},
+	}
+
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal(rawPolicy, &policy))
+
+	for _, tc := range testcases {
+		rawResource := []byte(fmt.Sprintf(`{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "nginx"}, "spec": {"containers": [{"name": "nginx", "image": "%s", "imagePullPolicy": "IfNotPresent"}]}}`, tc.image))
+		resource, err := utils.ConvertToUnstructured(rawResource)
+		assert.NilError(t, err)
+
+		er := Validate(PolicyContext{Policy: policy, NewResource: *resource, Context: context.NewContext()})
+		assert.Equal(t, len(er.PolicyResponse.Rules), tc.rules, tc.image)
+		assert.Equal(t, er.IsSuccessful(), tc.success, tc.image)
+	}
+}
@@ -19,11 +19,96 @@ func Evaluate(log logr.Logger, ctx context.EvalInterface, condition kyverno.Cond
 
 //EvaluateConditions evaluates multiple conditions
 func EvaluateConditions(log logr.Logger, ctx context.EvalInterface, conditions []kyverno.Condition) bool {
+	pass, _ := EvaluateConditionsWithMessages(log, ctx, conditions)
+	return pass
+}
+
+//EvaluateConditionsWithMessages evaluates multiple conditions, and returns the messages of the conditions
+//that determined the result: the passed conditions if all of them pass, the failed conditions otherwise
+func EvaluateConditionsWithMessages(log logr.Logger, ctx context.EvalInterface, conditions []kyverno.Condition) (bool, []string) {
 	// AND the conditions
+	return evaluateAllConditions(log, ctx, conditions)
+}
+
+func evaluateAllConditions(log logr.Logger, ctx context.EvalInterface, conditions []kyverno.Condition) (bool, []string) {
+	var passedMessages, failedMessages []string
+	pass := true
+	for _, condition := range conditions {
+		if ok, messages := evaluateCondition(log, ctx, condition); ok {
+			passedMessages = append(passedMessages, messages...)
+		} else {
+			pass = false
+			failedMessages = append(failedMessages, messages...)
+		}
+	}
+
+	if !pass {
+		return false, failedMessages
+	}
+	return true, passedMessages
+}
+
+func evaluateAnyConditions(log logr.Logger, ctx context.EvalInterface, conditions []kyverno.Condition) (bool, []string) {
+	var passedMessages, failedMessages []string
+	pass := false
 	for _, condition := range conditions {
-		if !Evaluate(log, ctx, condition) {
-			return false
+		if ok, messages := evaluateCondition(log, ctx, condition); ok {
+			pass = true
+			passedMessages = append(passedMessages, messages...)
+		} else {
+			failedMessages = append(failedMessages, messages...)
+		}
+	}
+
+	if pass {
+		return true, passedMessages
+	}
+	return false, failedMessages
+}
+
+// evaluateCondition evaluates a single condition or a group of any and all conditions,
+// the message of the condition replaces the messages of the conditions in its groups
+func evaluateCondition(log logr.Logger, ctx context.EvalInterface, condition kyverno.Condition) (bool, []string) {
+	var pass bool
+	var messages []string
+	if condition.HasConditionGroups() {
+		anyPass, anyMessages := true, []string(nil)
+		if len(condition.AnyConditions) > 0 {
+			anyPass, anyMessages = evaluateAnyConditions(log, ctx, condition.AnyConditions)
+		}
+
+		allPass, allMessages := true, []string(nil)
+		if len(condition.AllConditions) > 0 {
+			allPass, allMessages = evaluateAllConditions(log, ctx, condition.AllConditions)
+		}
+
+		pass = anyPass && allPass
+		if anyPass == allPass {
+			messages = append(anyMessages, allMessages...)
+		} else if !anyPass {
+			messages = anyMessages
+		} else {
+			messages = allMessages
 		}
+	} else {
+		pass = Evaluate(log, ctx, condition)
+	}
+
+	if condition.Message != "" {
+		messages = []string{substituteMessage(log, ctx, condition.Message)}
+	}
+	return pass, messages
+}
+
+func substituteMessage(log logr.Logger, ctx context.EvalInterface, message string) string {
+	substituted, err := SubstituteVars(log, ctx, message)
+	if err != nil {
+		log.V(4).Info("failed to substitute variables in condition message", "message", message, "error", err.Error())
+		return message
+	}
+
+	if substitutedMessage, ok := substituted.(string); ok {
+		return substitutedMessage
 	}
-	return true
+	return message
 }
@@ -2,6 +2,7 @@ package variables
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
@@ -581,3 +582,233 @@ func Test_Eval_Equal_Var_Fail(t *testing.T) {
 		t.Error("expected to fail")
 	}
 }
+
+func Test_Eval_Numeric_Operators(t *testing.T) {
+	testCases := []struct {
+		key      interface{}
+		operator kyverno.ConditionOperator
+		value    interface{}
+		expected bool
+	}{
+		{key: 11, operator: kyverno.GreaterThan, value: 10, expected: true},
+		{key: 10, operator: kyverno.GreaterThan, value: 10, expected: false},
+		{key: 10, operator: kyverno.GreaterThanOrEquals, value: 10.0, expected: true},
+		{key: 1.5, operator: kyverno.LessThan, value: "2", expected: true},
+		{key: "10", operator: kyverno.LessThanOrEquals, value: 9, expected: false},
+		{key: "100Mi", operator: kyverno.LessThan, value: "1Gi", expected: true},
+		{key: "2", operator: kyverno.GreaterThan, value: "1500m", expected: true},
+		{key: "1Gi", operator: kyverno.GreaterThanOrEquals, value: 1073741824, expected: true},
+		{key: "1.10.2", operator: kyverno.GreaterThan, value: "1.9.0", expected: true},
+		{key: "v1.18.0", operator: kyverno.LessThan, value: "1.16.1", expected: false},
+		{key: "abc", operator: kyverno.GreaterThan, value: 1, expected: false},
+	}
+
+	ctx := context.NewContext()
+	for i, tc := range testCases {
+		condition := kyverno.Condition{
+			Key:      tc.key,
+			Operator: tc.operator,
+			Value:    tc.value,
+		}
+		if Evaluate(log.Log, ctx, condition) != tc.expected {
+			t.Errorf("testcase %d: expected %v for %v %s %v", i, tc.expected, tc.key, tc.operator, tc.value)
+		}
+	}
+}
+
+func Test_Eval_Duration_Operators(t *testing.T) {
+	testCases := []struct {
+		key      interface{}
+		operator kyverno.ConditionOperator
+		value    interface{}
+		expected bool
+	}{
+		{key: "10m", operator: kyverno.DurationGreaterThan, value: "5m", expected: true},
+		{key: "5m", operator: kyverno.DurationGreaterThanOrEquals, value: "300s", expected: true},
+		{key: 30, operator: kyverno.DurationLessThan, value: "1m", expected: true},
+		{key: 600, operator: kyverno.DurationLessThanOrEquals, value: "5m", expected: false},
+		{key: "1h", operator: kyverno.DurationGreaterThan, value: 3600, expected: false},
+		{key: "abc", operator: kyverno.DurationGreaterThan, value: "1m", expected: false},
+	}
+
+	ctx := context.NewContext()
+	for i, tc := range testCases {
+		condition := kyverno.Condition{
+			Key:      tc.key,
+			Operator: tc.operator,
+			Value:    tc.value,
+		}
+		if Evaluate(log.Log, ctx, condition) != tc.expected {
+			t.Errorf("testcase %d: expected %v for %v %s %v", i, tc.expected, tc.key, tc.operator, tc.value)
+		}
+	}
+}
+
+func Test_Eval_Semver_Operators(t *testing.T) {
+	testCases := []struct {
+		key      interface{}
+		operator kyverno.ConditionOperator
+		value    interface{}
+		expected bool
+	}{
+		// in range
+		{key: "1.18.2", operator: kyverno.SemverInRange, value: ">=1.18.0 <1.20.0", expected: true},
+		{key: "v1.19.0", operator: kyverno.SemverInRange, value: ">=1.18.0 <1.20.0", expected: true},
+		{key: "1.15.0", operator: kyverno.SemverInRange, value: "<1.16.0 || >=1.18.0", expected: true},
+		{key: "1.21.1", operator: kyverno.SemverNotInRange, value: ">=1.18.0 <1.20.0", expected: true},
+		// out of range
+		{key: "1.20.0", operator: kyverno.SemverInRange, value: ">=1.18.0 <1.20.0", expected: false},
+		{key: "1.17.3", operator: kyverno.SemverInRange, value: "<1.16.0 || >=1.18.0", expected: false},
+		{key: "1.18.2", operator: kyverno.SemverNotInRange, value: ">=1.18.0 <1.20.0", expected: false},
+		// invalid ranges and versions
+		{key: "1.18.2", operator: kyverno.SemverInRange, value: ">=1.18.0 <abc", expected: false},
+		{key: "1.18.2", operator: kyverno.SemverNotInRange, value: "latest", expected: false},
+		{key: "1.18.2", operator: kyverno.SemverInRange, value: 1, expected: false},
+		{key: "abc", operator: kyverno.SemverInRange, value: ">=1.18.0", expected: false},
+		{key: 1, operator: kyverno.SemverNotInRange, value: ">=1.18.0", expected: false},
+	}
+
+	ctx := context.NewContext()
+	for i, tc := range testCases {
+		condition := kyverno.Condition{
+			Key:      tc.key,
+			Operator: tc.operator,
+			Value:    tc.value,
+		}
+		if Evaluate(log.Log, ctx, condition) != tc.expected {
+			t.Errorf("testcase %d: expected %v for %v %s %v", i, tc.expected, tc.key, tc.operator, tc.value)
+		}
+	}
+}
+
+func Test_Eval_GreaterThan_Var_Pass(t *testing.T) {
+	resourceRaw := []byte(`
+	{
+		"spec": {
+			"replicas": 12,
+			"terminationGracePeriodSeconds": 600
+		}
+	}
+		`)
+
+	ctx := context.NewContext()
+	err := ctx.AddResource(resourceRaw)
+	if err != nil {
+		t.Error(err)
+	}
+
+	conditions := []kyverno.Condition{
+		{
+			Key:      "{{request.object.spec.replicas}}",
+			Operator: kyverno.GreaterThan,
+			Value:    10,
+		},
+		{
+			Key:      "{{request.object.spec.terminationGracePeriodSeconds}}",
+			Operator: kyverno.DurationGreaterThan,
+			Value:    "5m",
+		},
+	}
+
+	if !EvaluateConditions(log.Log, ctx, conditions) {
+		t.Error("expected to pass")
+	}
+}
+
+func Test_Eval_Set_Operators(t *testing.T) {
+	testCases := []struct {
+		key      interface{}
+		operator kyverno.ConditionOperator
+		value    interface{}
+		expected bool
+	}{
+		{key: []interface{}{"NET_ADMIN", "CHOWN"}, operator: kyverno.AnyIn, value: []interface{}{"NET_ADMIN", "SYS_ADMIN"}, expected: true},
+		{key: []interface{}{"CHOWN"}, operator: kyverno.AnyIn, value: []interface{}{"NET_ADMIN", "SYS_ADMIN"}, expected: false},
+		{key: []interface{}{"gcr.io/a:1", "gcr.io/b:2"}, operator: kyverno.AllIn, value: []interface{}{"gcr.io/*"}, expected: true},
+		{key: []interface{}{"gcr.io/a:1", "docker.io/b:2"}, operator: kyverno.AllIn, value: []interface{}{"gcr.io/*"}, expected: false},
+		{key: []interface{}{"gcr.io/a:1", "docker.io/b:2"}, operator: kyverno.AnyNotIn, value: []interface{}{"gcr.io/*"}, expected: true},
+		{key: []interface{}{"gcr.io/a:1"}, operator: kyverno.AnyNotIn, value: []interface{}{"gcr.io/*"}, expected: false},
+		{key: []interface{}{"CHOWN", "KILL"}, operator: kyverno.AllNotIn, value: []interface{}{"NET_ADMIN", "SYS_*"}, expected: true},
+		{key: []interface{}{"CHOWN", "SYS_TIME"}, operator: kyverno.AllNotIn, value: []interface{}{"NET_ADMIN", "SYS_*"}, expected: false},
+		{key: "prod", operator: kyverno.AnyIn, value: []interface{}{"prod", "staging"}, expected: true},
+		{key: []interface{}{80.0, 443.0}, operator: kyverno.AllIn, value: []interface{}{80.0, 443.0, 8080.0}, expected: true},
+	}
+
+	ctx := context.NewContext()
+	for i, tc := range testCases {
+		condition := kyverno.Condition{
+			Key:      tc.key,
+			Operator: tc.operator,
+			Value:    tc.value,
+		}
+		if Evaluate(log.Log, ctx, condition) != tc.expected {
+			t.Errorf("testcase %d: expected %v for %v %s %v", i, tc.expected, tc.key, tc.operator, tc.value)
+		}
+	}
+}
+
+func Test_Eval_AnyNotIn_Var(t *testing.T) {
+	resourceRaw := []byte(`
+	{
+		"spec": {
+			"containers": [
+				{"name": "a", "image": "registry.corp.com/nginx:1.19"},
+				{"name": "b", "image": "docker.io/busybox"}
+			]
+		}
+	}
+		`)
+
+	ctx := context.NewContext()
+	err := ctx.AddResource(resourceRaw)
+	if err != nil {
+		t.Error(err)
+	}
+
+	condition := kyverno.Condition{
+		Key:      "{{request.object.spec.containers[].image}}",
+		Operator: kyverno.AnyNotIn,
+		Value:    []interface{}{"registry.corp.com/*"},
+	}
+
+	if !Evaluate(log.Log, ctx, condition) {
+		t.Error("expected to pass")
+	}
+}
+
+func Test_EvaluateConditionsWithMessages_AnyAll(t *testing.T) {
+	resourceRaw := []byte(`{"metadata": {"name": "nginx", "labels": {"app": "nginx"}}}`)
+	ctx := context.NewContext()
+	if err := ctx.AddResource(resourceRaw); err != nil {
+		t.Error(err)
+	}
+
+	isNginx := kyverno.Condition{Key: "{{request.object.metadata.name}}", Operator: kyverno.Equal, Value: "nginx", Message: "{{request.object.metadata.name}} is reserved"}
+	isApache := kyverno.Condition{Key: "{{request.object.metadata.name}}", Operator: kyverno.Equal, Value: "apache", Message: "apache is reserved"}
+	hasLabel := kyverno.Condition{Key: "{{request.object.metadata.labels.app}}", Operator: kyverno.Equal, Value: "nginx", Message: "app label is nginx"}
+
+	testCases := []struct {
+		conditions []kyverno.Condition
+		pass       bool
+		messages   []string
+	}{
+		{conditions: []kyverno.Condition{isNginx, hasLabel}, pass: true, messages: []string{"nginx is reserved", "app label is nginx"}},
+		{conditions: []kyverno.Condition{isNginx, isApache}, pass: false, messages: []string{"apache is reserved"}},
+		{conditions: []kyverno.Condition{{AnyConditions: []kyverno.Condition{isApache, isNginx}}}, pass: true, messages: []string{"nginx is reserved"}},
+		{conditions: []kyverno.Condition{{AnyConditions: []kyverno.Condition{isApache}}}, pass: false, messages: []string{"apache is reserved"}},
+		{conditions: []kyverno.Condition{{AllConditions: []kyverno.Condition{isNginx, isApache}}}, pass: false, messages: []string{"apache is reserved"}},
+		{
+			conditions: []kyverno.Condition{{AnyConditions: []kyverno.Condition{isApache, {AllConditions: []kyverno.Condition{isNginx, hasLabel}}}}},
+			pass:       true,
+			messages:   []string{"nginx is reserved", "app label is nginx"},
+		},
+		{conditions: []kyverno.Condition{{AnyConditions: []kyverno.Condition{isApache, isNginx}, Message: "reserved name"}}, pass: true, messages: []string{"reserved name"}},
+	}
+
+	for i, tc := range testCases {
+		pass, messages := EvaluateConditionsWithMessages(log.Log, ctx, tc.conditions)
+		if pass != tc.pass || !reflect.DeepEqual(messages, tc.messages) {
+			t.Errorf("testcase %d: expected %v %v, got %v %v", i, tc.pass, tc.messages, pass, messages)
+		}
+	}
+}
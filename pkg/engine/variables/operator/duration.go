@@ -0,0 +1,121 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+)
+
+//NewDurationOperatorHandler returns handler to manage the duration operations (>=, >, <=, <)
+func NewDurationOperatorHandler(log logr.Logger, ctx context.EvalInterface, op kyverno.ConditionOperator, subHandler VariableSubstitutionHandler) OperatorHandler {
+	return DurationOperatorHandler{
+		ctx:        ctx,
+		subHandler: subHandler,
+		log:        log,
+		condition:  op,
+	}
+}
+
+//DurationOperatorHandler provides implementation to handle the duration operators
+type DurationOperatorHandler struct {
+	ctx        context.EvalInterface
+	subHandler VariableSubstitutionHandler
+	log        logr.Logger
+	condition  kyverno.ConditionOperator
+}
+
+//Evaluate evaluates expression with a duration operator,
+// the key and value can be durations like "5m" or numbers in seconds
+func (doh DurationOperatorHandler) Evaluate(key, value interface{}) bool {
+	var err error
+	// substitute the variables
+	if key, err = doh.subHandler(doh.log, doh.ctx, key); err != nil {
+		doh.log.Error(err, "Failed to resolve variable", "variable", key)
+		return false
+	}
+	if value, err = doh.subHandler(doh.log, doh.ctx, value); err != nil {
+		doh.log.Error(err, "Failed to resolve variable", "variable", value)
+		return false
+	}
+
+	switch typedKey := key.(type) {
+	case int:
+		return doh.validateValuewithIntPattern(int64(typedKey), value)
+	case int64:
+		return doh.validateValuewithIntPattern(typedKey, value)
+	case float64:
+		return doh.validateValuewithFloatPattern(typedKey, value)
+	case string:
+		return doh.validateValuewithStringPattern(typedKey, value)
+	default:
+		doh.log.Info("Unsupported type", "value", typedKey, "type", fmt.Sprintf("%T", typedKey))
+		return false
+	}
+}
+
+func (doh DurationOperatorHandler) validateValuewithIntPattern(key int64, value interface{}) bool {
+	return doh.compare(time.Duration(key)*time.Second, value)
+}
+
+func (doh DurationOperatorHandler) validateValuewithFloatPattern(key float64, value interface{}) bool {
+	return doh.compare(time.Duration(key*float64(time.Second)), value)
+}
+
+func (doh DurationOperatorHandler) validateValuewithStringPattern(key string, value interface{}) bool {
+	keyDuration, ok := parseDuration(key)
+	if !ok {
+		doh.log.Info("Expected a duration", "key", key)
+		return false
+	}
+	return doh.compare(keyDuration, value)
+}
+
+func (doh DurationOperatorHandler) compare(key time.Duration, value interface{}) bool {
+	valueDuration, ok := parseDuration(value)
+	if !ok {
+		doh.log.Info("Expected a duration", "value", value, "type", fmt.Sprintf("%T", value))
+		return false
+	}
+
+	var result int
+	switch {
+	case key > valueDuration:
+		result = 1
+	case key < valueDuration:
+		result = -1
+	}
+	return compareByCondition(result, doh.condition)
+}
+
+func (doh DurationOperatorHandler) validateValuewithBoolPattern(key bool, value interface{}) bool {
+	return false
+}
+
+func (doh DurationOperatorHandler) validateValueWithMapPattern(key map[string]interface{}, value interface{}) bool {
+	return false
+}
+
+func (doh DurationOperatorHandler) validateValueWithSlicePattern(key []interface{}, value interface{}) bool {
+	return false
+}
+
+// parseDuration converts duration strings to time.Duration, numbers are interpreted as seconds
+func parseDuration(value interface{}) (time.Duration, bool) {
+	if seconds, ok := parseNumber(value); ok {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+
+	typedValue, ok := value.(string)
+	if !ok {
+		return 0, false
+	}
+
+	duration, err := time.ParseDuration(typedValue)
+	if err != nil {
+		return 0, false
+	}
+	return duration, true
+}
@@ -0,0 +1,186 @@
+package operator
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-logr/logr"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+//NewNumericOperatorHandler returns handler to manage the numeric operations (>=, >, <=, <)
+func NewNumericOperatorHandler(log logr.Logger, ctx context.EvalInterface, op kyverno.ConditionOperator, subHandler VariableSubstitutionHandler) OperatorHandler {
+	return NumericOperatorHandler{
+		ctx:        ctx,
+		subHandler: subHandler,
+		log:        log,
+		condition:  op,
+	}
+}
+
+//NumericOperatorHandler provides implementation to handle the numeric operators
+type NumericOperatorHandler struct {
+	ctx        context.EvalInterface
+	subHandler VariableSubstitutionHandler
+	log        logr.Logger
+	condition  kyverno.ConditionOperator
+}
+
+//Evaluate evaluates expression with a numeric operator,
+// the key and value can be numbers, quantities or semantic versions
+func (noh NumericOperatorHandler) Evaluate(key, value interface{}) bool {
+	var err error
+	// substitute the variables
+	if key, err = noh.subHandler(noh.log, noh.ctx, key); err != nil {
+		noh.log.Error(err, "Failed to resolve variable", "variable", key)
+		return false
+	}
+	if value, err = noh.subHandler(noh.log, noh.ctx, value); err != nil {
+		noh.log.Error(err, "Failed to resolve variable", "variable", value)
+		return false
+	}
+
+	switch typedKey := key.(type) {
+	case int:
+		return noh.validateValuewithIntPattern(int64(typedKey), value)
+	case int64:
+		return noh.validateValuewithIntPattern(typedKey, value)
+	case float64:
+		return noh.validateValuewithFloatPattern(typedKey, value)
+	case string:
+		return noh.validateValuewithStringPattern(typedKey, value)
+	default:
+		noh.log.Info("Unsupported type", "value", typedKey, "type", fmt.Sprintf("%T", typedKey))
+		return false
+	}
+}
+
+func (noh NumericOperatorHandler) validateValuewithIntPattern(key int64, value interface{}) bool {
+	return noh.validateValuewithFloatPattern(float64(key), value)
+}
+
+func (noh NumericOperatorHandler) validateValuewithFloatPattern(key float64, value interface{}) bool {
+	if typedValue, ok := parseNumber(value); ok {
+		return compareByCondition(compareFloat(key, typedValue), noh.condition)
+	}
+
+	// the value can be a quantity, e.g. 2 > 1500m
+	keyQuantity, _ := parseQuantity(key)
+	if valueQuantity, ok := parseQuantity(value); ok {
+		return compareByCondition(keyQuantity.Cmp(valueQuantity), noh.condition)
+	}
+
+	noh.log.Info("Expected type number or quantity", "value", value, "type", fmt.Sprintf("%T", value))
+	return false
+}
+
+func (noh NumericOperatorHandler) validateValuewithStringPattern(key string, value interface{}) bool {
+	// plain numbers
+	if keyNumber, ok := parseNumber(key); ok {
+		return noh.validateValuewithFloatPattern(keyNumber, value)
+	}
+
+	// quantities, e.g. 100Mi < 1Gi
+	if keyQuantity, ok := parseQuantity(key); ok {
+		if valueQuantity, ok := parseQuantity(value); ok {
+			return compareByCondition(keyQuantity.Cmp(valueQuantity), noh.condition)
+		}
+	}
+
+	// semantic versions, e.g. 1.10.2 > 1.9.0
+	if keyVersion, err := semver.ParseTolerant(key); err == nil {
+		if typedValue, ok := value.(string); ok {
+			if valueVersion, err := semver.ParseTolerant(typedValue); err == nil {
+				return compareByCondition(keyVersion.Compare(valueVersion), noh.condition)
+			}
+		}
+	}
+
+	noh.log.Info("Failed to compare values, expected numbers, quantities or versions", "key", key, "value", value)
+	return false
+}
+
+func (noh NumericOperatorHandler) validateValuewithBoolPattern(key bool, value interface{}) bool {
+	return false
+}
+
+func (noh NumericOperatorHandler) validateValueWithMapPattern(key map[string]interface{}, value interface{}) bool {
+	return false
+}
+
+func (noh NumericOperatorHandler) validateValueWithSlicePattern(key []interface{}, value interface{}) bool {
+	return false
+}
+
+// parseNumber converts ints, floats and numeric strings to float64
+func parseNumber(value interface{}) (float64, bool) {
+	switch typedValue := value.(type) {
+	case int:
+		return float64(typedValue), true
+	case int64:
+		return float64(typedValue), true
+	case float64:
+		return typedValue, true
+	case string:
+		number, err := strconv.ParseFloat(typedValue, 64)
+		if err != nil {
+			return 0, false
+		}
+		return number, true
+	default:
+		return 0, false
+	}
+}
+
+// parseQuantity converts numbers and quantity strings to a resource.Quantity
+func parseQuantity(value interface{}) (apiresource.Quantity, bool) {
+	switch typedValue := value.(type) {
+	case int:
+		return *apiresource.NewQuantity(int64(typedValue), apiresource.DecimalSI), true
+	case int64:
+		return *apiresource.NewQuantity(typedValue, apiresource.DecimalSI), true
+	case float64:
+		quantity, err := apiresource.ParseQuantity(strconv.FormatFloat(typedValue, 'f', -1, 64))
+		if err != nil {
+			return apiresource.Quantity{}, false
+		}
+		return quantity, true
+	case string:
+		quantity, err := apiresource.ParseQuantity(typedValue)
+		if err != nil {
+			return apiresource.Quantity{}, false
+		}
+		return quantity, true
+	default:
+		return apiresource.Quantity{}, false
+	}
+}
+
+func compareFloat(key, value float64) int {
+	switch {
+	case key > value:
+		return 1
+	case key < value:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// compareByCondition checks the result of a comparison (-1, 0, 1) against the operator
+func compareByCondition(result int, op kyverno.ConditionOperator) bool {
+	switch op {
+	case kyverno.GreaterThanOrEquals, kyverno.DurationGreaterThanOrEquals:
+		return result >= 0
+	case kyverno.GreaterThan, kyverno.DurationGreaterThan:
+		return result > 0
+	case kyverno.LessThanOrEquals, kyverno.DurationLessThanOrEquals:
+		return result <= 0
+	case kyverno.LessThan, kyverno.DurationLessThan:
+		return result < 0
+	}
+	return false
+}
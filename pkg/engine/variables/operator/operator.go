@@ -34,6 +34,24 @@ func CreateOperatorHandler(log logr.Logger, ctx context.EvalInterface, op kyvern
 		return NewInHandler(log, ctx, subHandler)
 	case kyverno.NotIn:
 		return NewNotInHandler(log, ctx, subHandler)
+	case kyverno.GreaterThanOrEquals,
+		kyverno.GreaterThan,
+		kyverno.LessThanOrEquals,
+		kyverno.LessThan:
+		return NewNumericOperatorHandler(log, ctx, op, subHandler)
+	case kyverno.DurationGreaterThanOrEquals,
+		kyverno.DurationGreaterThan,
+		kyverno.DurationLessThanOrEquals,
+		kyverno.DurationLessThan:
+		return NewDurationOperatorHandler(log, ctx, op, subHandler)
+	case kyverno.SemverInRange,
+		kyverno.SemverNotInRange:
+		return NewSemverOperatorHandler(log, ctx, op, subHandler)
+	case kyverno.AnyIn,
+		kyverno.AllIn,
+		kyverno.AnyNotIn,
+		kyverno.AllNotIn:
+		return NewSetOperatorHandler(log, ctx, op, subHandler)
 	default:
 		log.Info("operator not supported", "operator", string(op))
 	}
@@ -0,0 +1,96 @@
+package operator
+
+import (
+	"fmt"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-logr/logr"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+)
+
+//NewSemverOperatorHandler returns handler to manage the semantic version range operations
+func NewSemverOperatorHandler(log logr.Logger, ctx context.EvalInterface, op kyverno.ConditionOperator, subHandler VariableSubstitutionHandler) OperatorHandler {
+	return SemverOperatorHandler{
+		ctx:        ctx,
+		subHandler: subHandler,
+		log:        log,
+		condition:  op,
+	}
+}
+
+//SemverOperatorHandler provides implementation to handle the semantic version range operators
+type SemverOperatorHandler struct {
+	ctx        context.EvalInterface
+	subHandler VariableSubstitutionHandler
+	log        logr.Logger
+	condition  kyverno.ConditionOperator
+}
+
+//Evaluate evaluates expression with a semantic version range operator,
+// the key is a version like "1.18.2" and the value a range like ">=1.18.0 <1.20.0"
+func (soh SemverOperatorHandler) Evaluate(key, value interface{}) bool {
+	var err error
+	// substitute the variables
+	if key, err = soh.subHandler(soh.log, soh.ctx, key); err != nil {
+		soh.log.Error(err, "Failed to resolve variable", "variable", key)
+		return false
+	}
+	if value, err = soh.subHandler(soh.log, soh.ctx, value); err != nil {
+		soh.log.Error(err, "Failed to resolve variable", "variable", value)
+		return false
+	}
+
+	switch typedKey := key.(type) {
+	case string:
+		return soh.validateValuewithStringPattern(typedKey, value)
+	default:
+		soh.log.Info("Unsupported type", "value", typedKey, "type", fmt.Sprintf("%T", typedKey))
+		return false
+	}
+}
+
+func (soh SemverOperatorHandler) validateValuewithStringPattern(key string, value interface{}) bool {
+	version, err := semver.ParseTolerant(key)
+	if err != nil {
+		soh.log.Info("Expected a semantic version", "key", key)
+		return false
+	}
+
+	typedValue, ok := value.(string)
+	if !ok {
+		soh.log.Info("Expected a semantic version range", "value", value, "type", fmt.Sprintf("%T", value))
+		return false
+	}
+
+	versionRange, err := semver.ParseRange(typedValue)
+	if err != nil {
+		soh.log.Info("Invalid semantic version range", "value", typedValue, "reason", err.Error())
+		return false
+	}
+
+	if soh.condition == kyverno.SemverNotInRange {
+		return !versionRange(version)
+	}
+	return versionRange(version)
+}
+
+func (soh SemverOperatorHandler) validateValuewithIntPattern(key int64, value interface{}) bool {
+	return false
+}
+
+func (soh SemverOperatorHandler) validateValuewithFloatPattern(key float64, value interface{}) bool {
+	return false
+}
+
+func (soh SemverOperatorHandler) validateValuewithBoolPattern(key bool, value interface{}) bool {
+	return false
+}
+
+func (soh SemverOperatorHandler) validateValueWithMapPattern(key map[string]interface{}, value interface{}) bool {
+	return false
+}
+
+func (soh SemverOperatorHandler) validateValueWithSlicePattern(key []interface{}, value interface{}) bool {
+	return false
+}
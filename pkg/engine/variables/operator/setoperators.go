@@ -0,0 +1,113 @@
+package operator
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/minio/pkg/wildcard"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+)
+
+//NewSetOperatorHandler returns handler to manage the set operations (AnyIn, AllIn, AnyNotIn, AllNotIn)
+func NewSetOperatorHandler(log logr.Logger, ctx context.EvalInterface, op kyverno.ConditionOperator, subHandler VariableSubstitutionHandler) OperatorHandler {
+	return SetOperatorHandler{
+		ctx:        ctx,
+		subHandler: subHandler,
+		log:        log,
+		condition:  op,
+	}
+}
+
+//SetOperatorHandler provides implementation to handle the set operators,
+// it compares a list of keys against a list of values that can contain wildcards
+type SetOperatorHandler struct {
+	ctx        context.EvalInterface
+	subHandler VariableSubstitutionHandler
+	log        logr.Logger
+	condition  kyverno.ConditionOperator
+}
+
+//Evaluate evaluates expression with a set operator
+func (soh SetOperatorHandler) Evaluate(key, value interface{}) bool {
+	var err error
+	// substitute the variables
+	if key, err = soh.subHandler(soh.log, soh.ctx, key); err != nil {
+		soh.log.Error(err, "Failed to resolve variable", "variable", key)
+		return false
+	}
+	if value, err = soh.subHandler(soh.log, soh.ctx, value); err != nil {
+		soh.log.Error(err, "Failed to resolve variable", "variable", value)
+		return false
+	}
+
+	switch typedKey := key.(type) {
+	case []interface{}:
+		return soh.validateValueWithSlicePattern(typedKey, value)
+	case string, int, int64, float64, bool:
+		// a single key is handled as a list with one element
+		return soh.validateValueWithSlicePattern([]interface{}{typedKey}, value)
+	default:
+		soh.log.Info("Unsupported type", "value", typedKey, "type", fmt.Sprintf("%T", typedKey))
+		return false
+	}
+}
+
+func (soh SetOperatorHandler) validateValueWithSlicePattern(key []interface{}, value interface{}) bool {
+	var values []interface{}
+	switch typedValue := value.(type) {
+	case []interface{}:
+		values = typedValue
+	case string, int, int64, float64, bool:
+		values = []interface{}{typedValue}
+	default:
+		soh.log.Info("Expected type []interface{}", "value", value, "type", fmt.Sprintf("%T", value))
+		return false
+	}
+
+	var found int
+	for _, k := range key {
+		if setContains(values, k) {
+			found++
+		}
+	}
+
+	switch soh.condition {
+	case kyverno.AnyIn:
+		return found > 0
+	case kyverno.AllIn:
+		return found == len(key)
+	case kyverno.AnyNotIn:
+		return found < len(key)
+	case kyverno.AllNotIn:
+		return found == 0
+	}
+	return false
+}
+
+func (soh SetOperatorHandler) validateValuewithBoolPattern(key bool, value interface{}) bool {
+	return soh.validateValueWithSlicePattern([]interface{}{key}, value)
+}
+
+func (soh SetOperatorHandler) validateValuewithIntPattern(key int64, value interface{}) bool {
+	return soh.validateValueWithSlicePattern([]interface{}{key}, value)
+}
+
+func (soh SetOperatorHandler) validateValuewithFloatPattern(key float64, value interface{}) bool {
+	return soh.validateValueWithSlicePattern([]interface{}{key}, value)
+}
+
+func (soh SetOperatorHandler) validateValueWithMapPattern(key map[string]interface{}, value interface{}) bool {
+	return false
+}
+
+// setContains checks if the key matches any of the values, values can contain the wildcards '*' and '?'
+func setContains(values []interface{}, key interface{}) bool {
+	keyStr := fmt.Sprint(key)
+	for _, value := range values {
+		if wildcard.Match(fmt.Sprint(value), keyStr) {
+			return true
+		}
+	}
+	return false
+}
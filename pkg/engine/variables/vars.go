@@ -94,6 +94,9 @@ func subValR(ctx context.EvalInterface, valuePattern string, path string) (inter
 				}
 				if val, ok := substitutedVar.(string); ok {
 					valuePattern = strings.Replace(valuePattern, variable, val, -1)
+				} else if isScalar(substitutedVar) && originalPattern != variable {
+					// numbers and booleans can be embedded in a string, e.g. the index in a JSON patch path
+					valuePattern = strings.Replace(valuePattern, variable, fmt.Sprint(substitutedVar), -1)
 				} else {
 					if substitutedVar != nil {
 						if originalPattern == variable {
@@ -114,3 +117,12 @@ func subValR(ctx context.EvalInterface, valuePattern string, path string) (inter
 
 	return valuePattern, nil
 }
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case float64, int, int64, bool:
+		return true
+	default:
+		return false
+	}
+}
@@ -42,10 +42,14 @@ type Controller struct {
 	queue workqueue.RateLimitingInterface
 	// pLister can list/get cluster policy from the shared informer's store
 	pLister kyvernolister.ClusterPolicyLister
+	// npLister can list/get namespaced policy from the shared informer's store
+	npLister kyvernolister.PolicyLister
 	// grLister can list/get generate request from the shared informer's store
 	grLister kyvernolister.GenerateRequestNamespaceLister
 	// pSynced returns true if the cluster policy has been synced at least once
 	pSynced cache.InformerSynced
+	// npSynced returns true if the namespaced policy has been synced at least once
+	npSynced cache.InformerSynced
 	// grSynced returns true if the generate request store has been synced at least once
 	grSynced cache.InformerSynced
 	// dyanmic sharedinformer factory
@@ -61,6 +65,7 @@ func NewController(
 	kyvernoclient *kyvernoclient.Clientset,
 	client *dclient.Client,
 	pInformer kyvernoinformer.ClusterPolicyInformer,
+	npInformer kyvernoinformer.PolicyInformer,
 	grInformer kyvernoinformer.GenerateRequestInformer,
 	dynamicInformer dynamicinformer.DynamicSharedInformerFactory,
 	log logr.Logger,
@@ -79,15 +84,21 @@ func NewController(
 	c.syncHandler = c.syncGenerateRequest
 
 	c.pLister = pInformer.Lister()
+	c.npLister = npInformer.Lister()
 	c.grLister = grInformer.Lister().GenerateRequests(config.KubePolicyNamespace)
 
 	c.pSynced = pInformer.Informer().HasSynced
+	c.npSynced = npInformer.Informer().HasSynced
 	c.grSynced = grInformer.Informer().HasSynced
 
 	pInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
 		DeleteFunc: c.deletePolicy, // we only cleanup if the policy is delete
 	}, 2*time.Minute)
 
+	npInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: c.deleteNsPolicy, // we only cleanup if the policy is delete
+	}, 2*time.Minute)
+
 	grInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
 		AddFunc:    c.addGR,
 		UpdateFunc: c.updateGR,
@@ -147,6 +158,51 @@ func (c *Controller) deletePolicy(obj interface{}) {
 	}
 }
 
+func (c *Controller) deleteNsPolicy(obj interface{}) {
+	logger := c.log
+	p, ok := obj.(*kyverno.Policy)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			logger.Info("couldn't get object from tombstone", "obj", obj)
+			return
+		}
+		p, ok = tombstone.Obj.(*kyverno.Policy)
+		if !ok {
+			logger.Info("tombstone contained object that is not a Policy", "obj", obj)
+			return
+		}
+	}
+	logger.V(4).Info("deleting policy", "namespace", p.Namespace, "name", p.Name)
+	// generate requests refer to namespaced policies as namespace/name
+	key := kyverno.PolicyKey(p.Namespace, p.Name)
+	grs, err := c.grLister.GetGenerateRequestsForClusterPolicy(key)
+	if err != nil {
+		logger.Error(err, "failed to generate request CR for the policy", "key", key)
+		return
+	}
+	for _, gr := range grs {
+		c.addGR(gr)
+	}
+}
+
+// getPolicy returns an error if the cluster policy for a name key,
+// or the namespaced policy for a namespace/name key does not exist
+func (c *Controller) getPolicy(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	if namespace == "" {
+		_, err = c.pLister.Get(name)
+		return err
+	}
+
+	_, err = c.npLister.Policies(namespace).Get(name)
+	return err
+}
+
 func (c *Controller) addGR(obj interface{}) {
 	gr := obj.(*kyverno.GenerateRequest)
 	c.enqueueGR(gr)
@@ -196,7 +252,7 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	logger.Info("starting")
 	defer logger.Info("shutting down")
 
-	if !cache.WaitForCacheSync(stopCh, c.pSynced, c.grSynced) {
+	if !cache.WaitForCacheSync(stopCh, c.pSynced, c.npSynced, c.grSynced) {
 		logger.Info("failed to sync informer cache")
 		return
 	}
@@ -262,7 +318,7 @@ func (c *Controller) syncGenerateRequest(key string) error {
 	if err != nil {
 		return err
 	}
-	_, err = c.pLister.Get(gr.Spec.Policy)
+	err = c.getPolicy(gr.Spec.Policy)
 	if err != nil {
 		if !errors.IsNotFound(err) {
 			return err
@@ -0,0 +1,191 @@
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	dclient "github.com/nirmata/kyverno/pkg/dclient"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/mutate"
+	"github.com/nirmata/kyverno/pkg/engine/variables"
+	"github.com/nirmata/kyverno/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// clonePatches are the patches of a generate rule applied to the cloned resources,
+// the variables are substituted before
+type clonePatches struct {
+	ruleName            string
+	patchStrategicMerge interface{}
+	patchesJSON6902     string
+}
+
+func getClonePatches(ruleName string, genUnst map[string]interface{}) (clonePatches, error) {
+	patches := clonePatches{ruleName: ruleName}
+	patchStrategicMerge, _, err := unstructured.NestedFieldCopy(genUnst, "patchStrategicMerge")
+	if err != nil {
+		return patches, err
+	}
+	patches.patchStrategicMerge = patchStrategicMerge
+
+	patches.patchesJSON6902, _, err = unstructured.NestedString(genUnst, "patchesJson6902")
+	return patches, err
+}
+
+// apply patches a copy of the cloned resource
+func (p clonePatches) apply(log logr.Logger, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	patched := *obj.DeepCopy()
+	if p.patchStrategicMerge != nil {
+		resp, resource := mutate.ProcessStrategicMergePatch(p.ruleName, p.patchStrategicMerge, patched, log)
+		if !resp.Success {
+			return nil, fmt.Errorf("failed to patch the cloned resource %s/%s: %s", obj.GetNamespace(), obj.GetName(), resp.Message)
+		}
+		patched = resource
+	}
+
+	if p.patchesJSON6902 != "" {
+		resp, resource := mutate.ProcessPatchJSON6902(p.ruleName, kyverno.Mutation{PatchesJSON6902: p.patchesJSON6902}, patched, log)
+		if !resp.Success {
+			return nil, fmt.Errorf("failed to patch the cloned resource %s/%s: %s", obj.GetNamespace(), obj.GetName(), resp.Message)
+		}
+		patched = resource
+	}
+
+	return &patched, nil
+}
+
+// applyCloneList clones the resources selected by the cloneList of the rule in the namespace of the rule.
+// With synchronize, the clones of the resources that were deleted or are no longer selected are deleted.
+func applyCloneList(log logr.Logger, client *dclient.Client, rule kyverno.Rule, resource unstructured.Unstructured, ctx context.EvalInterface, processExisting bool, policy string, generated []kyverno.ResourceSpec) ([]kyverno.ResourceSpec, error) {
+	genUnst, err := getUnstrRule(rule.Generation.DeepCopy())
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := variables.SubstituteVars(log, ctx, genUnst.Object)
+	if err != nil {
+		return nil, err
+	}
+	genUnst.Object, _ = object.(map[string]interface{})
+
+	gen, err := toGeneration(genUnst.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	patches, err := getClonePatches(rule.Name, genUnst.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	var genResources []kyverno.ResourceSpec
+	for _, kindSelector := range gen.CloneList.Kinds {
+		apiVersion, kind := parseCloneKind(kindSelector)
+		sources, err := client.ListResource(apiVersion, kind, gen.CloneList.Namespace, gen.CloneList.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the resources of kind %s to clone: %v", kindSelector, err)
+		}
+
+		for i := range sources.Items {
+			source := &sources.Items[i]
+			genResource := kyverno.ResourceSpec{
+				APIVersion: source.GetAPIVersion(),
+				Kind:       source.GetKind(),
+				Namespace:  gen.Namespace,
+				Name:       source.GetName(),
+			}
+
+			rdata, mode, err := cloneResource(log, client, source, genResource, patches)
+			if err != nil {
+				return nil, err
+			}
+
+			if rdata != nil && !processExisting {
+				if err := writeResource(log, client, rule, resource, rdata, mode, genResource, policy); err != nil {
+					return nil, err
+				}
+			}
+			genResources = append(genResources, genResource)
+		}
+	}
+
+	if gen.Synchronize {
+		if err := deleteRemovedClones(log, client, gen, generated, genResources); err != nil {
+			return nil, err
+		}
+	}
+
+	return genResources, nil
+}
+
+// deleteRemovedClones deletes the resources that were generated by the cloneList, but
+// whose source was deleted or is no longer selected
+func deleteRemovedClones(log logr.Logger, client *dclient.Client, gen kyverno.Generation, generated, genResources []kyverno.ResourceSpec) error {
+	current := make(map[string]bool, len(genResources))
+	for _, r := range genResources {
+		current[r.Kind+"/"+r.Namespace+"/"+r.Name] = true
+	}
+
+	for _, r := range generated {
+		if r.Namespace != gen.Namespace || !matchesCloneKinds(gen.CloneList.Kinds, r.Kind) || current[r.Kind+"/"+r.Namespace+"/"+r.Name] {
+			continue
+		}
+
+		obj, err := client.GetResource(r.APIVersion, r.Kind, r.Namespace, r.Name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// only delete the clones kept in sync by kyverno
+		if obj.GetLabels()["policy.kyverno.io/synchronize"] != "enable" {
+			continue
+		}
+
+		log.V(4).Info("deleting clone of removed source", "genKind", r.Kind, "genNamespace", r.Namespace, "genName", r.Name)
+		if err := client.DeleteResource(obj.GetAPIVersion(), r.Kind, r.Namespace, r.Name, false); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseCloneKind returns the apiVersion and the kind of a kind selector, the
+// apiVersion is empty if the selector has no group and version
+func parseCloneKind(kindSelector string) (apiVersion, kind string) {
+	group, version, kind, _ := utils.ParseKindSelector(kindSelector)
+	if version != "*" {
+		if group == "*" {
+			group = ""
+		}
+		apiVersion = schema.GroupVersion{Group: group, Version: version}.String()
+	}
+	return apiVersion, kind
+}
+
+func matchesCloneKinds(kinds []string, kind string) bool {
+	for _, k := range kinds {
+		if _, cloneKind := parseCloneKind(k); cloneKind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func toGeneration(genUnst map[string]interface{}) (kyverno.Generation, error) {
+	var gen kyverno.Generation
+	raw, err := json.Marshal(genUnst)
+	if err != nil {
+		return gen, err
+	}
+
+	err = json.Unmarshal(raw, &gen)
+	return gen, err
+}
@@ -0,0 +1,66 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	"gotest.tools/assert"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func Test_ParseCloneKind(t *testing.T) {
+	testcases := []struct {
+		kindSelector string
+		apiVersion   string
+		kind         string
+	}{
+		{kindSelector: "ConfigMap", apiVersion: "", kind: "ConfigMap"},
+		{kindSelector: "v1/Secret", apiVersion: "v1", kind: "Secret"},
+		{kindSelector: "networking.k8s.io/v1/NetworkPolicy", apiVersion: "networking.k8s.io/v1", kind: "NetworkPolicy"},
+	}
+
+	for _, tc := range testcases {
+		apiVersion, kind := parseCloneKind(tc.kindSelector)
+		assert.Equal(t, apiVersion, tc.apiVersion, tc.kindSelector)
+		assert.Equal(t, kind, tc.kind, tc.kindSelector)
+	}
+
+	assert.Assert(t, matchesCloneKinds([]string{"v1/ConfigMap", "Secret"}, "Secret"))
+	assert.Assert(t, !matchesCloneKinds([]string{"v1/ConfigMap"}, "Secret"))
+}
+
+func Test_ClonePatches(t *testing.T) {
+	genUnst := map[string]interface{}{
+		"patchStrategicMerge": map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{
+					"cloned": "true",
+				},
+			},
+		},
+		"patchesJson6902": "- op: add\n  path: /data/env\n  value: prod",
+	}
+
+	patches, err := getClonePatches("clone-configmaps", genUnst)
+	assert.NilError(t, err)
+
+	source, err := utils.ConvertToUnstructured([]byte(`{
+		"apiVersion": "v1",
+		"kind": "ConfigMap",
+		"metadata": {
+			"name": "settings",
+			"namespace": "default"
+		},
+		"data": {
+			"key": "value"
+		}
+	}`))
+	assert.NilError(t, err)
+
+	patched, err := patches.apply(log.Log, source)
+	assert.NilError(t, err)
+	assert.Equal(t, patched.GetLabels()["cloned"], "true")
+	assert.DeepEqual(t, patched.Object["data"], map[string]interface{}{"key": "value", "env": "prod"})
+	// the source is not changed
+	assert.Equal(t, len(source.GetLabels()), 0)
+}
@@ -14,16 +14,22 @@ import (
 	"github.com/nirmata/kyverno/pkg/event"
 	"github.com/nirmata/kyverno/pkg/policystatus"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	listerv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 )
 
 const (
 	maxRetries = 5
+	// cloneListResync is the period at which the generate requests of the rules cloning a synchronized
+	// list of resources are processed again, so that the sources added or removed are reflected
+	cloneListResync = time.Minute
 )
 
 // Controller manages the life-cycle for Generate-Requests and applies generate rule
@@ -45,12 +51,20 @@ type Controller struct {
 	queue workqueue.RateLimitingInterface
 	// pLister can list/get cluster policy from the shared informer's store
 	pLister kyvernolister.ClusterPolicyLister
+	// npLister can list/get namespaced policy from the shared informer's store
+	npLister kyvernolister.PolicyLister
 	// grLister can list/get generate request from the shared informer's store
 	grLister kyvernolister.GenerateRequestNamespaceLister
+	// cmLister can list/get configmaps from the shared informer's store
+	cmLister listerv1.ConfigMapLister
 	// pSynced returns true if the Cluster policy store has been synced at least once
 	pSynced cache.InformerSynced
+	// npSynced returns true if the namespaced policy store has been synced at least once
+	npSynced cache.InformerSynced
 	// grSynced returns true if the Generate Request store has been synced at least once
 	grSynced cache.InformerSynced
+	// cmSynced returns true if the configmap store has been synced at least once
+	cmSynced cache.InformerSynced
 	// dyanmic sharedinformer factory
 	dynamicInformer dynamicinformer.DynamicSharedInformerFactory
 	//TODO: list of generic informers
@@ -67,7 +81,9 @@ func NewController(
 	kyvernoclient *kyvernoclient.Clientset,
 	client *dclient.Client,
 	pInformer kyvernoinformer.ClusterPolicyInformer,
+	npInformer kyvernoinformer.PolicyInformer,
 	grInformer kyvernoinformer.GenerateRequestInformer,
+	cmInformer coreinformers.ConfigMapInformer,
 	eventGen event.Interface,
 	dynamicInformer dynamicinformer.DynamicSharedInformerFactory,
 	policyStatus policystatus.Listener,
@@ -93,6 +109,11 @@ func NewController(
 		// Deletion of policy will be handled by cleanup controller
 	}, 2*time.Minute)
 
+	npInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: c.updateNsPolicy, // We only handle updates to policy
+		// Deletion of policy will be handled by cleanup controller
+	}, 2*time.Minute)
+
 	grInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
 		AddFunc:    c.addGR,
 		UpdateFunc: c.updateGR,
@@ -103,10 +124,14 @@ func NewController(
 	c.syncHandler = c.syncGenerateRequest
 
 	c.pLister = pInformer.Lister()
+	c.npLister = npInformer.Lister()
 	c.grLister = grInformer.Lister().GenerateRequests(config.KubePolicyNamespace)
+	c.cmLister = cmInformer.Lister()
 
 	c.pSynced = pInformer.Informer().HasSynced
+	c.npSynced = npInformer.Informer().HasSynced
 	c.grSynced = pInformer.Informer().HasSynced
+	c.cmSynced = cmInformer.Informer().HasSynced
 
 	//TODO: dynamic registration
 	// Only supported for namespaces
@@ -165,6 +190,46 @@ func (c *Controller) updatePolicy(old, cur interface{}) {
 	}
 }
 
+func (c *Controller) updateNsPolicy(old, cur interface{}) {
+	logger := c.log
+	oldP := old.(*kyverno.Policy)
+	curP := cur.(*kyverno.Policy)
+	if oldP.ResourceVersion == curP.ResourceVersion {
+		return
+	}
+	logger.V(4).Info("updating policy", "namespace", oldP.Namespace, "name", oldP.Name)
+	// generate requests refer to namespaced policies as namespace/name
+	key := kyverno.PolicyKey(curP.Namespace, curP.Name)
+	grs, err := c.grLister.GetGenerateRequestsForClusterPolicy(key)
+	if err != nil {
+		logger.Error(err, "failed to generate request for policy", "key", key)
+		return
+	}
+	// re-evaluate the GR as the policy was updated
+	for _, gr := range grs {
+		c.enqueueGR(gr)
+	}
+}
+
+// getPolicy returns the cluster policy for a name key, and the converted
+// namespaced policy for a namespace/name key
+func (c *Controller) getPolicy(key string) (*kyverno.ClusterPolicy, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		return c.pLister.Get(name)
+	}
+
+	nsPolicy, err := c.npLister.Policies(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return kyverno.ConvertPolicyToClusterPolicy(nsPolicy), nil
+}
+
 func (c *Controller) addGR(obj interface{}) {
 	gr := obj.(*kyverno.GenerateRequest)
 	c.enqueueGR(gr)
@@ -215,16 +280,62 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	logger.Info("starting")
 	defer logger.Info("shutting down")
 
-	if !cache.WaitForCacheSync(stopCh, c.pSynced, c.grSynced) {
+	if !cache.WaitForCacheSync(stopCh, c.pSynced, c.npSynced, c.grSynced, c.cmSynced) {
 		logger.Info("failed to sync informer cache")
 		return
 	}
 	for i := 0; i < workers; i++ {
 		go wait.Until(c.worker, constant.GenerateControllerResync, stopCh)
 	}
+	go wait.Until(c.resyncCloneList, cloneListResync, stopCh)
 	<-stopCh
 }
 
+// resyncCloneList enqueues the generate requests of the policies with rules cloning a synchronized list of resources
+func (c *Controller) resyncCloneList() {
+	logger := c.log
+	var keys []string
+	policies, err := c.pLister.List(labels.Everything())
+	if err != nil {
+		logger.Error(err, "failed to list cluster policies")
+	}
+	for _, policy := range policies {
+		if hasSynchronizedCloneList(policy.Spec) {
+			keys = append(keys, policy.Name)
+		}
+	}
+
+	nsPolicies, err := c.npLister.List(labels.Everything())
+	if err != nil {
+		logger.Error(err, "failed to list namespaced policies")
+	}
+	for _, policy := range nsPolicies {
+		if hasSynchronizedCloneList(policy.Spec) {
+			keys = append(keys, policy.Namespace+"/"+policy.Name)
+		}
+	}
+
+	for _, key := range keys {
+		grs, err := c.grLister.GetGenerateRequestsForClusterPolicy(key)
+		if err != nil {
+			logger.Error(err, "failed to get generate requests for policy", "key", key)
+			continue
+		}
+		for _, gr := range grs {
+			c.enqueueGR(gr)
+		}
+	}
+}
+
+func hasSynchronizedCloneList(spec kyverno.Spec) bool {
+	for _, rule := range spec.Rules {
+		if rule.Generation.Synchronize && len(rule.Generation.CloneList.Kinds) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // worker runs a worker thread that just dequeues items, processes them, and marks them done.
 // It enforces that the syncHandler is never invoked concurrently with the same key.
 func (c *Controller) worker() {
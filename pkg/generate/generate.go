@@ -11,8 +11,10 @@ import (
 	dclient "github.com/nirmata/kyverno/pkg/dclient"
 	"github.com/nirmata/kyverno/pkg/engine"
 	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
 	"github.com/nirmata/kyverno/pkg/engine/validate"
 	"github.com/nirmata/kyverno/pkg/engine/variables"
+	"github.com/nirmata/kyverno/pkg/metrics"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -49,7 +51,7 @@ func (c *Controller) applyGenerate(resource unstructured.Unstructured, gr kyvern
 	// build context
 	ctx := context.NewContext()
 
-	policy, err := c.pLister.Get(gr.Spec.Policy)
+	policy, err := c.getPolicy(gr.Spec.Policy)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			for _, e := range gr.Status.GeneratedResources {
@@ -98,6 +100,9 @@ func (c *Controller) applyGenerate(resource unstructured.Unstructured, gr kyvern
 		Context:          ctx,
 		AdmissionInfo:    gr.Spec.Context.UserRequestInfo,
 		ExcludeGroupRole: c.Config.GetExcludeGroupRole(),
+		ConfigMapLister:  c.cmLister,
+		Client:           c.client,
+		NamespaceLabels:  c.getNamespaceLabels(resource.GetNamespace(), logger),
 	}
 
 	// check if the policy still applies to the resource
@@ -120,6 +125,25 @@ func updateStatus(statusControl StatusControlInterface, gr kyverno.GenerateReque
 	return statusControl.Success(gr, genResources)
 }
 
+// getNamespaceLabels returns the labels of the namespace from the namespace informer
+func (c *Controller) getNamespaceLabels(namespace string, log logr.Logger) map[string]string {
+	if namespace == "" {
+		return nil
+	}
+
+	obj, err := c.nsInformer.Lister().Get(namespace)
+	if err != nil {
+		log.Error(err, "failed to get namespace", "namespace", namespace)
+		return nil
+	}
+
+	ns, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	return ns.GetLabels()
+}
+
 func (c *Controller) applyGeneratePolicy(log logr.Logger, policyContext engine.PolicyContext, gr kyverno.GenerateRequest) ([]kyverno.ResourceSpec, error) {
 	// List of generatedResources
 	var genResources []kyverno.ResourceSpec
@@ -129,7 +153,11 @@ func (c *Controller) applyGeneratePolicy(log logr.Logger, policyContext engine.P
 	resource := policyContext.NewResource
 	ctx := policyContext.Context
 	// To manage existing resources, we compare the creation time for the default resiruce to be generated and policy creation time
+	// the generate requests created for existing resources are always applied
 	processExisting := func() bool {
+		if gr.GetLabels()[kyverno.GenerateExistingLabel] == "true" {
+			return false
+		}
 		rcreationTime := resource.GetCreationTimestamp()
 		pcreationTime := policy.GetCreationTimestamp()
 		return rcreationTime.Before(&pcreationTime)
@@ -141,10 +169,25 @@ func (c *Controller) applyGeneratePolicy(log logr.Logger, policyContext engine.P
 			continue
 		}
 		startTime := time.Now()
+		if len(rule.Generation.CloneList.Kinds) != 0 {
+			cloned, err := applyCloneList(log, c.client, rule, resource, ctx, processExisting, policy.Name, gr.Status.GeneratedResources)
+			if err != nil {
+				metrics.RecordRuleResult(policy.Namespace, policy.Name, rule.Name, utils.Generation.String(), metrics.ResultFail)
+				return nil, err
+			}
+			metrics.RecordRuleResult(policy.Namespace, policy.Name, rule.Name, utils.Generation.String(), metrics.ResultPass)
+
+			ruleNameToProcessingTime[rule.Name] = time.Since(startTime)
+			genResources = append(genResources, cloned...)
+			continue
+		}
+
 		genResource, err := applyRule(log, c.client, rule, resource, ctx, processExisting, policy.Name)
 		if err != nil {
+			metrics.RecordRuleResult(policy.Namespace, policy.Name, rule.Name, utils.Generation.String(), metrics.ResultFail)
 			return nil, err
 		}
+		metrics.RecordRuleResult(policy.Namespace, policy.Name, rule.Name, utils.Generation.String(), metrics.ResultPass)
 
 		ruleNameToProcessingTime[rule.Name] = time.Since(startTime)
 		genResources = append(genResources, genResource)
@@ -152,7 +195,7 @@ func (c *Controller) applyGeneratePolicy(log logr.Logger, policyContext engine.P
 
 	if gr.Status.State == "" {
 		c.policyStatusListener.Send(generateSyncStats{
-			policyName:               policy.Name,
+			policyName:               gr.Spec.Policy,
 			ruleNameToProcessingTime: ruleNameToProcessingTime,
 		})
 	}
@@ -256,7 +299,12 @@ func applyRule(log logr.Logger, client *dclient.Client, rule kyverno.Rule, resou
 	if genData != nil {
 		rdata, mode, err = manageData(log, genAPIVersion, genKind, genNamespace, genName, genData, client, resource)
 	} else {
-		rdata, mode, err = manageClone(log, genAPIVersion, genKind, genNamespace, genName, genCopy, client, resource)
+		var patches clonePatches
+		patches, err = getClonePatches(rule.Name, genUnst.Object)
+		if err != nil {
+			return noGenResource, err
+		}
+		rdata, mode, err = manageClone(log, genAPIVersion, genKind, genNamespace, genName, genCopy, client, resource, patches)
 	}
 	if err != nil {
 		return noGenResource, err
@@ -274,6 +322,16 @@ func applyRule(log logr.Logger, client *dclient.Client, rule kyverno.Rule, resou
 
 	}
 
+	if err := writeResource(log, client, rule, resource, rdata, mode, newGenResource, policy); err != nil {
+		return noGenResource, err
+	}
+	return newGenResource, nil
+}
+
+// writeResource creates or updates the generated resource with the data
+func writeResource(log logr.Logger, client *dclient.Client, rule kyverno.Rule, resource unstructured.Unstructured, rdata map[string]interface{}, mode ResourceMode, genResource kyverno.ResourceSpec, policy string) error {
+	genAPIVersion, genKind, genNamespace, genName := genResource.APIVersion, genResource.Kind, genResource.Namespace, genResource.Name
+
 	// build the resource template
 	newResource := &unstructured.Unstructured{}
 	newResource.SetUnstructuredContent(rdata)
@@ -305,10 +363,10 @@ func applyRule(log logr.Logger, client *dclient.Client, rule kyverno.Rule, resou
 		newResource.SetResourceVersion("")
 		// Create the resource
 		logger.V(4).Info("creating new resource")
-		_, err = client.CreateResource(genAPIVersion, genKind, genNamespace, newResource, false)
+		_, err := client.CreateResource(genAPIVersion, genKind, genNamespace, newResource, false)
 		if err != nil {
 			// Failed to create resource
-			return noGenResource, err
+			return err
 		}
 		logger.V(4).Info("created new resource")
 
@@ -322,7 +380,7 @@ func applyRule(log logr.Logger, client *dclient.Client, rule kyverno.Rule, resou
 				if err != nil {
 					logger.Error(err, "updating existing resource")
 					// Failed to update resource
-					return noGenResource, err
+					return err
 				}
 				logger.V(4).Info("updated new resource")
 
@@ -333,7 +391,7 @@ func applyRule(log logr.Logger, client *dclient.Client, rule kyverno.Rule, resou
 			logger.V(4).Info("Synchronize resource is disabled")
 		}
 	}
-	return newGenResource, nil
+	return nil
 }
 
 func manageData(log logr.Logger, apiVersion, kind, namespace, name string, data map[string]interface{}, client *dclient.Client, resource unstructured.Unstructured) (map[string]interface{}, ResourceMode, error) {
@@ -359,7 +417,7 @@ func manageData(log logr.Logger, apiVersion, kind, namespace, name string, data
 
 }
 
-func manageClone(log logr.Logger, apiVersion, kind, namespace, name string, clone map[string]interface{}, client *dclient.Client, resource unstructured.Unstructured) (map[string]interface{}, ResourceMode, error) {
+func manageClone(log logr.Logger, apiVersion, kind, namespace, name string, clone map[string]interface{}, client *dclient.Client, resource unstructured.Unstructured, patches clonePatches) (map[string]interface{}, ResourceMode, error) {
 	newRNs, _, err := unstructured.NestedString(clone, "namespace")
 	if err != nil {
 		return nil, Skip, err
@@ -381,6 +439,17 @@ func manageClone(log logr.Logger, apiVersion, kind, namespace, name string, clon
 		return nil, Skip, fmt.Errorf("reference clone resource %s/%s/%s/%s not found. %v", apiVersion, kind, newRNs, newRName, err)
 	}
 
+	return cloneResource(log, client, obj, kyverno.ResourceSpec{APIVersion: apiVersion, Kind: kind, Namespace: namespace, Name: name}, patches)
+}
+
+// cloneResource returns the data of the source resource to clone, patched, and the mode to write it
+func cloneResource(log logr.Logger, client *dclient.Client, obj *unstructured.Unstructured, genResource kyverno.ResourceSpec, patches clonePatches) (map[string]interface{}, ResourceMode, error) {
+	apiVersion, kind, namespace, name := genResource.APIVersion, genResource.Kind, genResource.Namespace, genResource.Name
+	obj, err := patches.apply(log, obj)
+	if err != nil {
+		return nil, Skip, err
+	}
+
 	// check if resource to be generated exists
 	newResource, err := client.GetResource(apiVersion, kind, namespace, name)
 	if err == nil {
@@ -156,7 +156,7 @@ func getResources(policies []*v1.ClusterPolicy, resourcePaths []string, dClient
 		var resourceTypes []string
 		for _, policy := range policies {
 			for _, rule := range policy.Spec.Rules {
-				for _, kind := range rule.MatchResources.Kinds {
+				for _, kind := range rule.MatchKinds() {
 					resourceTypesMap[kind] = true
 				}
 			}
@@ -189,19 +189,30 @@ func getResources(policies []*v1.ClusterPolicy, resourcePaths []string, dClient
 func getResourcesOfTypeFromCluster(resourceTypes []string, dClient *client.Client) ([]*unstructured.Unstructured, error) {
 	var resources []*unstructured.Unstructured
 
-	for _, kind := range resourceTypes {
-		resourceList, err := dClient.ListResource("", kind, "", nil)
+	for _, kindSelector := range resourceTypes {
+		group, version, kind, subresource := utils.ParseKindSelector(kindSelector)
+		if subresource != "" || strings.Contains(kind, "*") {
+			// kinds with wildcards or a subresource cannot be listed
+			continue
+		}
+
+		apiVersion := ""
+		if group != "*" && version != "*" {
+			apiVersion = schema.GroupVersion{Group: group, Version: version}.String()
+		}
+
+		resourceList, err := dClient.ListResource(apiVersion, kind, "", nil)
 		if err != nil {
 			return nil, err
 		}
 
-		version := resourceList.GetAPIVersion()
+		gvk := schema.FromAPIVersionAndKind(resourceList.GetAPIVersion(), kind)
+		if !utils.MatchKindSelector(kindSelector, gvk, "") {
+			continue
+		}
+
 		for _, resource := range resourceList.Items {
-			resource.SetGroupVersionKind(schema.GroupVersionKind{
-				Group:   "",
-				Version: version,
-				Kind:    kind,
-			})
+			resource.SetGroupVersionKind(gvk)
 			resources = append(resources, resource.DeepCopy())
 		}
 	}
@@ -104,8 +104,8 @@ func GetPolicy(path string) (clusterPolicies []*v1.ClusterPolicy, errors []error
 			continue
 		}
 
-		if policy.TypeMeta.Kind != "ClusterPolicy" {
-			errors = append(errors, fmt.Errorf(fmt.Sprintf("resource %v is not a cluster policy", policy.Name)))
+		if policy.TypeMeta.Kind != "ClusterPolicy" && policy.TypeMeta.Kind != "Policy" {
+			errors = append(errors, fmt.Errorf(fmt.Sprintf("resource %v is not a policy", policy.Name)))
 			continue
 		}
 		clusterPolicies = append(clusterPolicies, policy)
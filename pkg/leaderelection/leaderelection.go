@@ -0,0 +1,125 @@
+package leaderelection
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	// leaseDuration is the duration that the other instances wait before taking over an expired lease
+	leaseDuration = 15 * time.Second
+	// renewDeadline is the duration that the leader retries renewing the lease before giving up the leadership
+	renewDeadline = 10 * time.Second
+	// retryPeriod is the duration between the attempts to acquire or renew the lease
+	retryPeriod = 2 * time.Second
+)
+
+//Interface is a lease based leader election between the instances of Kyverno
+type Interface interface {
+	// Run takes part in the leader election until the context is done, the lease is released when the context is done.
+	// startLeading is called when the leadership is acquired, with a context that is done when the leadership is lost.
+	// stopLeading is called when the leadership is lost or released.
+	Run(ctx context.Context, startLeading func(ctx context.Context), stopLeading func())
+	// IsLeader returns true if this instance holds the lease
+	IsLeader() bool
+	// ID returns the identity of this instance
+	ID() string
+}
+
+// LeaderElection elects the leader with a coordination.k8s.io Lease
+type LeaderElection struct {
+	name     string
+	id       string
+	lock     resourcelock.Interface
+	isLeader int32
+	log      logr.Logger
+}
+
+// New returns a leader election with the lease name in the namespace
+func New(name, namespace string, kubeClient kubernetes.Interface, log logr.Logger) (*LeaderElection, error) {
+	// the hostname is the name of the pod
+	id, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := resourcelock.New(
+		resourcelock.LeasesResourceLock,
+		namespace,
+		name,
+		kubeClient.CoreV1(),
+		kubeClient.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: id},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LeaderElection{
+		name: name,
+		id:   id,
+		lock: lock,
+		log:  log.WithValues("lease", namespace+"/"+name, "id", id),
+	}, nil
+}
+
+// Run takes part in the leader election until the context is done
+func (le *LeaderElection) Run(ctx context.Context, startLeading func(ctx context.Context), stopLeading func()) {
+	logger := le.log
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            le.lock,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Name:            le.name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				atomic.StoreInt32(&le.isLeader, 1)
+				logger.Info("started leading")
+				if startLeading != nil {
+					startLeading(ctx)
+				}
+			},
+			OnStoppedLeading: func() {
+				// the callback is also called when this instance was never the leader
+				if !atomic.CompareAndSwapInt32(&le.isLeader, 1, 0) {
+					return
+				}
+				logger.Info("stopped leading")
+				if stopLeading != nil {
+					stopLeading()
+				}
+			},
+			OnNewLeader: func(identity string) {
+				if identity != le.id {
+					logger.Info("another instance is leading", "leader", identity)
+				}
+			},
+		},
+	})
+	if err != nil {
+		logger.Error(err, "failed to create leader elector")
+		return
+	}
+
+	logger.Info("starting leader election")
+	elector.Run(ctx)
+}
+
+// IsLeader returns true if this instance holds the lease
+func (le *LeaderElection) IsLeader() bool {
+	return atomic.LoadInt32(&le.isLeader) == 1
+}
+
+// ID returns the identity of this instance
+func (le *LeaderElection) ID() string {
+	return le.id
+}
@@ -0,0 +1,54 @@
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func Test_LeaderElection_ReleaseOnCancel(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	le, err := New("kyverno", "kyverno", kubeClient, log.Log)
+	assert.NilError(t, err)
+
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		le.Run(ctx, func(ctx context.Context) { close(started) }, func() { close(stopped) })
+		close(done)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("leadership was not acquired")
+	}
+	assert.Assert(t, le.IsLeader())
+
+	lease, err := kubeClient.CoordinationV1().Leases("kyverno").Get("kyverno", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, *lease.Spec.HolderIdentity, le.ID())
+
+	cancel()
+	<-done
+	<-stopped
+	assert.Assert(t, !le.IsLeader())
+
+	// the lease is released, so another instance acquires it without waiting for the lease to expire
+	err = wait.PollImmediate(100*time.Millisecond, 5*time.Second, func() (bool, error) {
+		lease, err := kubeClient.CoordinationV1().Leases("kyverno").Get("kyverno", metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "", nil
+	})
+	assert.NilError(t, err)
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "kyverno"
+
+	// ResultPass is the result of a rule that was applied successfully
+	ResultPass = "pass"
+	// ResultFail is the result of a rule that failed
+	ResultFail = "fail"
+	// ResultSkip is the result of a rule whose preconditions were not met
+	ResultSkip = "skip"
+)
+
+var (
+	admissionRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "admission_request_duration_seconds",
+		Help:      "Latency of the admission requests processed by the webhook server, by webhook path.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"path", "allowed"})
+
+	ruleResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "policy_rule_results_total",
+		Help:      "Number of policy rule results, by policy namespace, policy, rule, rule type and result.",
+	}, []string{"policy_namespace", "policy", "rule", "type", "result"})
+
+	policyCacheSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "policy_cache_size",
+		Help:      "Number of policies in the policy cache, by policy type.",
+	}, []string{"type"})
+
+	webhookRegistered = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "webhook_configuration_registered",
+		Help:      "Whether the webhook configuration is registered (1) or not (0), by configuration name.",
+	}, []string{"name"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		admissionRequestDuration,
+		ruleResults,
+		policyCacheSize,
+		webhookRegistered,
+	)
+}
+
+// Handler returns the handler that exposes the metrics in the Prometheus format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveAdmissionRequest records the latency of an admission request for a webhook path
+func ObserveAdmissionRequest(path string, allowed bool, duration time.Duration) {
+	admissionRequestDuration.WithLabelValues(path, boolLabel(allowed)).Observe(duration.Seconds())
+}
+
+// RecordRuleResult counts the result of a policy rule, the policy namespace is empty for a cluster policy
+func RecordRuleResult(policyNamespace, policy, rule, ruleType, result string) {
+	ruleResults.WithLabelValues(policyNamespace, policy, rule, ruleType, result).Inc()
+}
+
+// RecordEngineResponse counts the results of the rules applied by the engine, the
+// skipped rules are counted with the type of the engine response
+func RecordEngineResponse(ruleType string, er response.EngineResponse) {
+	policyNamespace := er.PolicyResponse.PolicyNamespace
+	policy := er.PolicyResponse.Policy
+	for _, rule := range er.PolicyResponse.Rules {
+		result := ResultPass
+		if !rule.Success {
+			result = ResultFail
+		}
+		RecordRuleResult(policyNamespace, policy, rule.Name, rule.Type, result)
+	}
+
+	for _, rule := range er.PolicyResponse.RulesSkipped {
+		RecordRuleResult(policyNamespace, policy, rule, ruleType, ResultSkip)
+	}
+}
+
+// SetPolicyCacheSize records the number of cached policies of a policy type
+func SetPolicyCacheSize(policyType string, size int) {
+	policyCacheSize.WithLabelValues(policyType).Set(float64(size))
+}
+
+// SetWebhookRegistered records whether a webhook configuration is registered
+func SetWebhookRegistered(name string, registered bool) {
+	value := 0.0
+	if registered {
+		value = 1
+	}
+	webhookRegistered.WithLabelValues(name).Set(value)
+}
+
+func boolLabel(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
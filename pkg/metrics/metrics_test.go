@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/assert"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func Test_RecordEngineResponse(t *testing.T) {
+	er := response.EngineResponse{
+		PolicyResponse: response.PolicyResponse{
+			Policy: "check-labels",
+			Rules: []response.RuleResponse{
+				{Name: "require-app", Type: "Validation", Success: true},
+				{Name: "require-team", Type: "Validation", Success: false},
+			},
+			RulesSkipped: []string{"require-env"},
+		},
+	}
+
+	RecordEngineResponse("Validation", er)
+	RecordEngineResponse("Validation", er)
+
+	assert.Equal(t, testutil.ToFloat64(ruleResults.WithLabelValues("", "check-labels", "require-app", "Validation", ResultPass)), 2.0)
+	assert.Equal(t, testutil.ToFloat64(ruleResults.WithLabelValues("", "check-labels", "require-team", "Validation", ResultFail)), 2.0)
+	assert.Equal(t, testutil.ToFloat64(ruleResults.WithLabelValues("", "check-labels", "require-env", "Validation", ResultSkip)), 2.0)
+
+	// a namespaced policy with the same name is counted separately
+	er.PolicyResponse.PolicyNamespace = "dev"
+	RecordEngineResponse("Validation", er)
+	assert.Equal(t, testutil.ToFloat64(ruleResults.WithLabelValues("dev", "check-labels", "require-app", "Validation", ResultPass)), 1.0)
+	assert.Equal(t, testutil.ToFloat64(ruleResults.WithLabelValues("", "check-labels", "require-app", "Validation", ResultPass)), 2.0)
+}
+
+func Test_WebhookRegistered(t *testing.T) {
+	SetWebhookRegistered("kyverno-resource-mutating-webhook-cfg", true)
+	assert.Equal(t, testutil.ToFloat64(webhookRegistered.WithLabelValues("kyverno-resource-mutating-webhook-cfg")), 1.0)
+
+	SetWebhookRegistered("kyverno-resource-mutating-webhook-cfg", false)
+	assert.Equal(t, testutil.ToFloat64(webhookRegistered.WithLabelValues("kyverno-resource-mutating-webhook-cfg")), 0.0)
+}
+
+func Test_WorkqueueDepth(t *testing.T) {
+	queue := workqueue.NewNamed("test-queue")
+	defer queue.ShutDown()
+
+	queue.Add("a")
+	queue.Add("b")
+	assert.Equal(t, testutil.ToFloat64(workqueueDepth.WithLabelValues("test-queue")), 2.0)
+
+	item, _ := queue.Get()
+	queue.Done(item)
+	assert.Equal(t, testutil.ToFloat64(workqueueDepth.WithLabelValues("test-queue")), 1.0)
+	assert.Equal(t, testutil.ToFloat64(workqueueAdds.WithLabelValues("test-queue")), 2.0)
+}
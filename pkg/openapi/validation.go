@@ -10,6 +10,7 @@ import (
 
 	data "github.com/nirmata/kyverno/api"
 	"github.com/nirmata/kyverno/pkg/engine/utils"
+	kyvernoutils "github.com/nirmata/kyverno/pkg/utils"
 
 	"github.com/nirmata/kyverno/pkg/engine"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -118,7 +119,21 @@ func (o *Controller) ValidatePolicyMutation(policy v1.ClusterPolicy) error {
 	var kindToRules = make(map[string][]v1.Rule)
 	for _, rule := range policy.Spec.Rules {
 		if rule.HasMutate() {
-			for _, kind := range rule.MatchResources.Kinds {
+			kindSelectors := rule.MatchKinds()
+			// the rules with targets mutate the target kinds instead of the matched kinds
+			if rule.HasMutateTargets() {
+				kindSelectors = nil
+				for _, target := range rule.Mutation.Targets {
+					kindSelectors = append(kindSelectors, target.Kind)
+				}
+			}
+
+			for _, kindSelector := range kindSelectors {
+				// kinds with wildcards or a subresource have no single definition to validate against
+				_, _, kind, subresource := kyvernoutils.ParseKindSelector(kindSelector)
+				if subresource != "" || strings.Contains(kind, "*") {
+					continue
+				}
 				kindToRules[kind] = append(kindToRules[kind], rule)
 			}
 		}
@@ -21,16 +21,30 @@ func ContainsVariablesOtherThanObject(policy kyverno.ClusterPolicy) error {
 			return fmt.Errorf("invalid variable used at path: spec/rules[%d]/exclude/%s", idx, path)
 		}
 
-		filterVars := []string{"request.object"}
-		ctx := context.NewContext(filterVars...)
-		for condIdx, condition := range rule.Conditions {
-			if condition.Key, err = variables.SubstituteVars(log.Log, ctx, condition.Key); !checkNotFoundErr(err) {
-				return fmt.Errorf("invalid variable used at spec/rules[%d]/condition[%d]/key", idx, condIdx)
+		for _, block := range []struct {
+			name    string
+			filters kyverno.ResourceFilters
+		}{
+			{"match/any", rule.MatchResources.Any},
+			{"match/all", rule.MatchResources.All},
+			{"exclude/any", rule.ExcludeResources.Any},
+			{"exclude/all", rule.ExcludeResources.All},
+		} {
+			for i, filter := range block.filters {
+				if path := userInfoDefined(filter.UserInfo); path != "" {
+					return fmt.Errorf("invalid variable used at path: spec/rules[%d]/%s[%d]/%s", idx, block.name, i, path)
+				}
 			}
+		}
 
-			if condition.Value, err = variables.SubstituteVars(log.Log, ctx, condition.Value); !checkNotFoundErr(err) {
-				return fmt.Errorf("invalid variable used at spec/rules[%d]/condition[%d]/value", idx, condIdx)
-			}
+		// variables loaded from the rule context, the foreach elements and the mutate targets are also available in background mode
+		filterVars := []string{"request.object", "element", "target"}
+		for _, entry := range rule.Context {
+			filterVars = append(filterVars, entry.Name)
+		}
+		ctx := context.NewContext(filterVars...)
+		if err := checkConditionVariables(ctx, rule.Conditions, fmt.Sprintf("spec/rules[%d]/condition", idx)); err != nil {
+			return err
 		}
 
 		if rule.Mutation.Overlay != nil {
@@ -53,12 +67,25 @@ func ContainsVariablesOtherThanObject(policy kyverno.ClusterPolicy) error {
 			return fmt.Errorf("invalid variable used at spec/rules[%d]/validate/message", idx)
 		}
 		if rule.Validation.Deny != nil {
-			for i := range rule.Validation.Deny.Conditions {
-				if _, err = variables.SubstituteVars(log.Log, ctx, rule.Validation.Deny.Conditions[i].Key); !checkNotFoundErr(err) {
-					return fmt.Errorf("invalid variable used at spec/rules[%d]/validate/deny/conditions[%d]/key", idx, i)
+			if err := checkConditionVariables(ctx, rule.Validation.Deny.Conditions, fmt.Sprintf("spec/rules[%d]/validate/deny/conditions", idx)); err != nil {
+				return err
+			}
+		}
+		if foreach := rule.Validation.ForEachValidation; foreach != nil {
+			if _, err = variables.SubstituteVars(log.Log, ctx, foreach.Pattern); !checkNotFoundErr(err) {
+				return fmt.Errorf("invalid variable used at spec/rules[%d]/validate/foreach/pattern", idx)
+			}
+			for idx2, pattern := range foreach.AnyPattern {
+				if _, err = variables.SubstituteVars(log.Log, ctx, pattern); !checkNotFoundErr(err) {
+					return fmt.Errorf("invalid variable used at spec/rules[%d]/validate/foreach/anyPattern[%d]", idx, idx2)
 				}
-				if _, err = variables.SubstituteVars(log.Log, ctx, rule.Validation.Deny.Conditions[i].Value); !checkNotFoundErr(err) {
-					return fmt.Errorf("invalid variable used at spec/rules[%d]/validate/deny/conditions[%d]/value", idx, i)
+			}
+			if err := checkConditionVariables(ctx, foreach.Preconditions, fmt.Sprintf("spec/rules[%d]/validate/foreach/preconditions", idx)); err != nil {
+				return err
+			}
+			if foreach.Deny != nil {
+				if err := checkConditionVariables(ctx, foreach.Deny.Conditions, fmt.Sprintf("spec/rules[%d]/validate/foreach/deny/conditions", idx)); err != nil {
+					return err
 				}
 			}
 		}
@@ -66,6 +93,28 @@ func ContainsVariablesOtherThanObject(policy kyverno.ClusterPolicy) error {
 	return nil
 }
 
+// checkConditionVariables checks the variables of the conditions and of their any and all groups
+func checkConditionVariables(ctx context.EvalInterface, conditions []kyverno.Condition, path string) error {
+	for i, condition := range conditions {
+		if _, err := variables.SubstituteVars(log.Log, ctx, condition.Key); !checkNotFoundErr(err) {
+			return fmt.Errorf("invalid variable used at %s[%d]/key", path, i)
+		}
+		if _, err := variables.SubstituteVars(log.Log, ctx, condition.Value); !checkNotFoundErr(err) {
+			return fmt.Errorf("invalid variable used at %s[%d]/value", path, i)
+		}
+		if _, err := variables.SubstituteVars(log.Log, ctx, condition.Message); !checkNotFoundErr(err) {
+			return fmt.Errorf("invalid variable used at %s[%d]/message", path, i)
+		}
+		if err := checkConditionVariables(ctx, condition.AnyConditions, fmt.Sprintf("%s[%d]/any", path, i)); err != nil {
+			return err
+		}
+		if err := checkConditionVariables(ctx, condition.AllConditions, fmt.Sprintf("%s[%d]/all", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func checkNotFoundErr(err error) bool {
 	if err != nil {
 		switch err.(type) {
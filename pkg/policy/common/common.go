@@ -55,6 +55,14 @@ func validateMap(patternMap map[string]interface{}, path string, supportedAnchor
 					return path + "/" + key, fmt.Errorf("Existence anchor: single value expected, multiple specified")
 				}
 			}
+
+			// global anchors cannot be nested in the value of
+			// a global or negation anchor as it is not evaluated there
+			if anchor.IsGlobalAnchor(key) || anchor.IsNegationAnchor(key) {
+				if hasGlobalAnchor(value) {
+					return path + "/" + key, fmt.Errorf("Global anchor cannot be nested in the value of %s", key)
+				}
+			}
 		}
 		// lets validate the values now :)
 		if errPath, err := ValidatePattern(value, path+"/"+key, supportedAnchors); err != nil {
@@ -75,6 +83,24 @@ func validateArray(patternArray []interface{}, path string, supportedAnchors []a
 	return "", nil
 }
 
+func hasGlobalAnchor(patternElement interface{}) bool {
+	switch typed := patternElement.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			if anchor.IsGlobalAnchor(key) || hasGlobalAnchor(value) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, value := range typed {
+			if hasGlobalAnchor(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func checkAnchors(key string, supportedAnchors []anchor.IsAnchor) bool {
 	for _, f := range supportedAnchors {
 		if f(key) {
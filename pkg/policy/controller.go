@@ -15,6 +15,7 @@ import (
 	"github.com/nirmata/kyverno/pkg/constant"
 	client "github.com/nirmata/kyverno/pkg/dclient"
 	"github.com/nirmata/kyverno/pkg/event"
+	"github.com/nirmata/kyverno/pkg/policystatus"
 	"github.com/nirmata/kyverno/pkg/policyviolation"
 	"github.com/nirmata/kyverno/pkg/webhookconfig"
 	v1 "k8s.io/api/core/v1"
@@ -26,6 +27,7 @@ import (
 	listerv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/client-go/util/workqueue"
 )
 
@@ -55,6 +57,9 @@ type PolicyController struct {
 	// pLister can list/get policy from the shared informer's store
 	pLister kyvernolister.ClusterPolicyLister
 
+	// npLister can list/get namespaced policy from the shared informer's store
+	npLister kyvernolister.PolicyLister
+
 	// pvLister can list/get policy violation from the shared informer's store
 	cpvLister kyvernolister.ClusterPolicyViolationLister
 
@@ -64,9 +69,15 @@ type PolicyController struct {
 	// nsLister can list/get namespacecs from the shared informer's store
 	nsLister listerv1.NamespaceLister
 
+	// cmLister can list/get configmaps from the shared informer's store
+	cmLister listerv1.ConfigMapLister
+
 	// pListerSynced returns true if the Policy store has been synced at least once
 	pListerSynced cache.InformerSynced
 
+	// npListerSynced returns true if the namespaced Policy store has been synced at least once
+	npListerSynced cache.InformerSynced
+
 	// pvListerSynced returns true if the Policy store has been synced at least once
 	cpvListerSynced cache.InformerSynced
 
@@ -76,6 +87,9 @@ type PolicyController struct {
 	// nsListerSynced returns true if the namespace store has been synced at least once
 	nsListerSynced cache.InformerSynced
 
+	// cmListerSynced returns true if the configmap store has been synced at least once
+	cmListerSynced cache.InformerSynced
+
 	// Resource manager, manages the mapping for already processed resource
 	rm resourceManager
 
@@ -88,6 +102,21 @@ type PolicyController struct {
 	// resourceWebhookWatcher queues the webhook creation request, creates the webhook
 	resourceWebhookWatcher *webhookconfig.ResourceWebhookRegister
 
+	// policyStatusListener records the mutation of existing resources in the policy status
+	policyStatusListener policystatus.Listener
+
+	// mutateExistingQueue holds the keys of the existing resources to patch
+	mutateExistingQueue workqueue.RateLimitingInterface
+
+	// mutateExistingRequests holds the patches of the existing resources in the queue
+	mutateExistingRequests *mutateExistingRequests
+
+	// mutateExistingLimiter limits the rate at which existing resources are patched
+	mutateExistingLimiter flowcontrol.RateLimiter
+
+	// generateExistingLimiter limits the rate at which generate requests are created for existing resources
+	generateExistingLimiter flowcontrol.RateLimiter
+
 	log logr.Logger
 }
 
@@ -95,12 +124,15 @@ type PolicyController struct {
 func NewPolicyController(kyvernoClient *kyvernoclient.Clientset,
 	client *client.Client,
 	pInformer kyvernoinformer.ClusterPolicyInformer,
+	npInformer kyvernoinformer.PolicyInformer,
 	cpvInformer kyvernoinformer.ClusterPolicyViolationInformer,
 	nspvInformer kyvernoinformer.PolicyViolationInformer,
 	configHandler config.Interface, eventGen event.Interface,
 	pvGenerator policyviolation.GeneratorInterface,
 	resourceWebhookWatcher *webhookconfig.ResourceWebhookRegister,
+	policyStatusListener policystatus.Listener,
 	namespaces informers.NamespaceInformer,
+	configMaps informers.ConfigMapInformer,
 	log logr.Logger) (*PolicyController, error) {
 
 	// Event broad caster
@@ -113,15 +145,20 @@ func NewPolicyController(kyvernoClient *kyvernoclient.Clientset,
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: eventInterface})
 
 	pc := PolicyController{
-		client:                 client,
-		kyvernoClient:          kyvernoClient,
-		eventGen:               eventGen,
-		eventRecorder:          eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "policy_controller"}),
-		queue:                  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "policy"),
-		configHandler:          configHandler,
-		pvGenerator:            pvGenerator,
-		resourceWebhookWatcher: resourceWebhookWatcher,
-		log:                    log,
+		client:                  client,
+		kyvernoClient:           kyvernoClient,
+		eventGen:                eventGen,
+		eventRecorder:           eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "policy_controller"}),
+		queue:                   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "policy"),
+		configHandler:           configHandler,
+		pvGenerator:             pvGenerator,
+		resourceWebhookWatcher:  resourceWebhookWatcher,
+		policyStatusListener:    policyStatusListener,
+		mutateExistingQueue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), mutateExistingQueueName),
+		mutateExistingRequests:  newMutateExistingRequests(),
+		mutateExistingLimiter:   flowcontrol.NewTokenBucketRateLimiter(mutateExistingQPS, mutateExistingBurst),
+		generateExistingLimiter: flowcontrol.NewTokenBucketRateLimiter(generateExistingQPS, generateExistingBurst),
+		log:                     log,
 	}
 
 	pc.pvControl = RealPVControl{Client: kyvernoClient, Recorder: pc.eventRecorder}
@@ -132,6 +169,12 @@ func NewPolicyController(kyvernoClient *kyvernoclient.Clientset,
 		DeleteFunc: pc.deletePolicy,
 	})
 
+	npInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    pc.addNsPolicy,
+		UpdateFunc: pc.updateNsPolicy,
+		DeleteFunc: pc.deleteNsPolicy,
+	})
+
 	cpvInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    pc.addClusterPolicyViolation,
 		UpdateFunc: pc.updateClusterPolicyViolation,
@@ -145,14 +188,18 @@ func NewPolicyController(kyvernoClient *kyvernoclient.Clientset,
 	})
 
 	pc.pLister = pInformer.Lister()
+	pc.npLister = npInformer.Lister()
 	pc.cpvLister = cpvInformer.Lister()
 	pc.nspvLister = nspvInformer.Lister()
 	pc.nsLister = namespaces.Lister()
+	pc.cmLister = configMaps.Lister()
 
 	pc.pListerSynced = pInformer.Informer().HasSynced
+	pc.npListerSynced = npInformer.Informer().HasSynced
 	pc.cpvListerSynced = cpvInformer.Informer().HasSynced
 	pc.nspvListerSynced = nspvInformer.Informer().HasSynced
 	pc.nsListerSynced = namespaces.Informer().HasSynced
+	pc.cmListerSynced = configMaps.Informer().HasSynced
 
 	// resource manager
 	// rebuild after 300 seconds/ 5 mins
@@ -225,6 +272,51 @@ func (pc *PolicyController) deletePolicy(obj interface{}) {
 	pc.enqueuePolicy(p)
 }
 
+func (pc *PolicyController) addNsPolicy(obj interface{}) {
+	logger := pc.log
+	p := kyverno.ConvertPolicyToClusterPolicy(obj.(*kyverno.Policy))
+	if !pc.canBackgroundProcess(p) {
+		return
+	}
+
+	logger.V(4).Info("queuing policy for background processing", "namespace", p.Namespace, "name", p.Name)
+	pc.enqueuePolicy(p)
+}
+
+func (pc *PolicyController) updateNsPolicy(old, cur interface{}) {
+	logger := pc.log
+	oldP := old.(*kyverno.Policy)
+	curP := kyverno.ConvertPolicyToClusterPolicy(cur.(*kyverno.Policy))
+
+	if !pc.canBackgroundProcess(curP) {
+		return
+	}
+
+	logger.V(4).Info("updating policy", "namespace", oldP.Namespace, "name", oldP.Name)
+	pc.enqueuePolicy(curP)
+}
+
+func (pc *PolicyController) deleteNsPolicy(obj interface{}) {
+	logger := pc.log
+	p, ok := obj.(*kyverno.Policy)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			logger.Info("couldnt get object from tomstone", "obj", obj)
+			return
+		}
+
+		p, ok = tombstone.Obj.(*kyverno.Policy)
+		if !ok {
+			logger.Info("tombstone container object that is not a policy", "obj", obj)
+			return
+		}
+	}
+
+	logger.V(4).Info("deleting policy", "namespace", p.Namespace, "name", p.Name)
+	pc.enqueuePolicy(kyverno.ConvertPolicyToClusterPolicy(p))
+}
+
 func (pc *PolicyController) enqueuePolicy(policy *kyverno.ClusterPolicy) {
 	logger := pc.log
 	key, err := cache.MetaNamespaceKeyFunc(policy)
@@ -241,11 +333,12 @@ func (pc *PolicyController) Run(workers int, stopCh <-chan struct{}) {
 
 	defer utilruntime.HandleCrash()
 	defer pc.queue.ShutDown()
+	defer pc.mutateExistingQueue.ShutDown()
 
 	logger.Info("starting")
 	defer logger.Info("shutting down")
 
-	if !cache.WaitForCacheSync(stopCh, pc.pListerSynced, pc.cpvListerSynced, pc.nspvListerSynced, pc.nsListerSynced) {
+	if !cache.WaitForCacheSync(stopCh, pc.pListerSynced, pc.npListerSynced, pc.cpvListerSynced, pc.nspvListerSynced, pc.nsListerSynced, pc.cmListerSynced) {
 		logger.Info("failed to sync informer cache")
 		return
 	}
@@ -254,6 +347,9 @@ func (pc *PolicyController) Run(workers int, stopCh <-chan struct{}) {
 		go wait.Until(pc.worker, constant.PolicyControllerResync, stopCh)
 	}
 
+	// existing resources are patched by a single worker at a limited rate, without blocking the policy workers
+	go wait.Until(pc.mutateExistingWorker, time.Second, stopCh)
+
 	<-stopCh
 }
 
@@ -265,11 +361,6 @@ func (pc *PolicyController) worker() {
 }
 
 func (pc *PolicyController) processNextWorkItem() bool {
-	// if policies exist before Kyverno get created, resource webhook configuration
-	// could not be registered as clusterpolicy.spec.background=false by default
-	// the policy controller would starts only when the first incoming policy is queued
-	pc.resourceWebhookWatcher.RegisterResourceWebhook()
-
 	key, quit := pc.queue.Get()
 	if quit {
 		return false
@@ -307,15 +398,15 @@ func (pc *PolicyController) syncPolicy(key string) error {
 		logger.V(4).Info("finished syncing policy", "key", key, "processingTime", time.Since(startTime).String())
 	}()
 
-	policy, err := pc.pLister.Get(key)
+	policy, err := pc.getPolicy(key)
 	if errors.IsNotFound(err) {
 		go pc.deletePolicyViolations(key)
+		go pc.pvGenerator.RemovePolicy(key)
 
 		// remove webhook configurations if there are no policies
 		if err := pc.removeResourceWebhookConfiguration(); err != nil {
 			logger.Error(err, "failed to remove resource webhook configurations")
 		}
-
 		return nil
 	}
 
@@ -323,21 +414,55 @@ func (pc *PolicyController) syncPolicy(key string) error {
 		return err
 	}
 
-	pc.resourceWebhookWatcher.RegisterResourceWebhook()
-
 	engineResponses := pc.processExistingResources(policy)
 	pc.cleanupAndReport(engineResponses)
 
 	return nil
 }
 
+// getPolicy returns the cluster policy for a name key, and the converted
+// namespaced policy for a namespace/name key
+func (pc *PolicyController) getPolicy(key string) (*kyverno.ClusterPolicy, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		return pc.pLister.Get(name)
+	}
+
+	nsPolicy, err := pc.npLister.Policies(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return kyverno.ConvertPolicyToClusterPolicy(nsPolicy), nil
+}
+
 func (pc *PolicyController) deletePolicyViolations(key string) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		pc.log.Error(err, "failed to parse policy key", "policy", key)
+		return
+	}
+
+	// violations of a namespaced policy only exist in its namespace
+	if namespace != "" {
+		npv, err := pc.deleteNamespacedPolicyViolations(namespace, name)
+		if err != nil {
+			pc.log.Error(err, "failed to delete policy violations", "policy", key)
+		}
+
+		pc.log.Info("deleted policy violations", "policy", key, "count", npv)
+		return
+	}
+
 	cpv, err := pc.deleteClusterPolicyViolations(key)
 	if err != nil {
 		pc.log.Error(err, "failed to delete policy violations", "policy", key)
 	}
 
-	npv, err := pc.deleteNamespacedPolicyViolations(key)
+	npv, err := pc.deleteNamespacedPolicyViolations("", key)
 	if err != nil {
 		pc.log.Error(err, "failed to delete policy violations", "policy", key)
 	}
@@ -363,8 +488,8 @@ func (pc *PolicyController) deleteClusterPolicyViolations(policy string) (int, e
 	return count, nil
 }
 
-func (pc *PolicyController) deleteNamespacedPolicyViolations(policy string) (int, error) {
-	nspvList, err := pc.getNamespacedPolicyViolationForPolicy(policy)
+func (pc *PolicyController) deleteNamespacedPolicyViolations(namespace, policy string) (int, error) {
+	nspvList, err := pc.getNamespacedPolicyViolationForPolicy(namespace, policy)
 	if err != nil {
 		return 0, err
 	}
@@ -381,18 +506,33 @@ func (pc *PolicyController) deleteNamespacedPolicyViolations(policy string) (int
 	return count, nil
 }
 
-func (pc *PolicyController) getNamespacedPolicyViolationForPolicy(policy string) ([]*kyverno.PolicyViolation, error) {
+func (pc *PolicyController) getNamespacedPolicyViolationForPolicy(namespace, policy string) ([]*kyverno.PolicyViolation, error) {
 	policySelector, err := buildPolicyLabel(policy)
 	if err != nil {
 		return nil, err
 	}
-	// Get List of cluster policy violation
-	nspvList, err := pc.nspvLister.List(policySelector)
+
+	// Get List of namespaced policy violation, scoped to the namespace of a namespaced policy
+	var nspvList []*kyverno.PolicyViolation
+	if namespace != "" {
+		nspvList, err = pc.nspvLister.PolicyViolations(namespace).List(policySelector)
+	} else {
+		nspvList, err = pc.nspvLister.List(policySelector)
+	}
 	if err != nil {
 		return nil, err
 	}
-	return nspvList, nil
 
+	// the violations of a namespaced policy are annotated with its namespace,
+	// skip the violations owned by a policy with the same name
+	var results []*kyverno.PolicyViolation
+	for _, nspv := range nspvList {
+		if nspv.Annotations[kyverno.PolicyNamespaceAnnotation] != namespace {
+			continue
+		}
+		results = append(results, nspv)
+	}
+	return results, nil
 }
 
 //PVControlInterface provides interface to  operate on policy violation resource
@@ -19,6 +19,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func (pc *PolicyController) processExistingResources(policy *kyverno.ClusterPolicy) []response.EngineResponse {
@@ -27,11 +28,13 @@ func (pc *PolicyController) processExistingResources(policy *kyverno.ClusterPoli
 	// drops the cache after configured rebuild time
 	pc.rm.Drop()
 	var engineResponses []response.EngineResponse
+	var mutateExistingCount, generateExistingCount int
+	generateExisting := policy.Spec.GenerateExistingOnPolicyUpdate && policy.HasGenerate()
 	// get resource that are satisfy the resource description defined in the rules
 	resourceMap := pc.listResources(policy)
 	for _, resource := range resourceMap {
 		// pre-processing, check if the policy and resource version has been processed before
-		if !pc.rm.ProcessResource(policy.Name, policy.ResourceVersion, resource.GetKind(), resource.GetNamespace(), resource.GetName(), resource.GetResourceVersion()) {
+		if !pc.rm.ProcessResource(kyverno.PolicyKey(policy.GetNamespace(), policy.GetName()), policy.ResourceVersion, resource.GetKind(), resource.GetNamespace(), resource.GetName(), resource.GetResourceVersion()) {
 			logger.V(4).Info("policy and resource already processed", "policyResourceVersion", policy.ResourceVersion, "resourceResourceVersion", resource.GetResourceVersion(), "kind", resource.GetKind(), "namespace", resource.GetNamespace(), "name", resource.GetName())
 			continue
 		}
@@ -47,11 +50,44 @@ func (pc *PolicyController) processExistingResources(policy *kyverno.ClusterPoli
 		}
 
 		// apply the policy on each
-		engineResponse := applyPolicy(*policy, resource, logger, pc.configHandler.GetExcludeGroupRole())
+		namespaceLabels := utils.GetNamespaceLabels(pc.nsLister, resource.GetNamespace(), logger)
+		engineResponse := applyPolicy(*policy, resource, logger, pc.configHandler.GetExcludeGroupRole(), pc.cmLister, pc.client, namespaceLabels)
+		if policy.MutateExistingEnabled() {
+			for i := range engineResponse {
+				if pc.mutateExistingResource(policy, resource, &engineResponse[i], logger.WithValues("kind", resource.GetKind(), "namespace", resource.GetNamespace(), "name", resource.GetName())) {
+					mutateExistingCount++
+				}
+			}
+		}
+		if generateExisting {
+			created, err := pc.generateExistingResource(policy, resource, logger.WithValues("kind", resource.GetKind(), "namespace", resource.GetNamespace(), "name", resource.GetName()))
+			if err != nil {
+				logger.Error(err, "failed to create generate request for existing resource", "kind", resource.GetKind(), "namespace", resource.GetNamespace(), "name", resource.GetName())
+			} else if created {
+				generateExistingCount++
+			}
+		}
 		// get engine response for mutation & validation independently
 		engineResponses = append(engineResponses, engineResponse...)
 		// post-processing, register the resource as processed
-		pc.rm.RegisterResource(policy.GetName(), policy.GetResourceVersion(), resource.GetKind(), resource.GetNamespace(), resource.GetName(), resource.GetResourceVersion())
+		pc.rm.RegisterResource(kyverno.PolicyKey(policy.GetNamespace(), policy.GetName()), policy.GetResourceVersion(), resource.GetKind(), resource.GetNamespace(), resource.GetName(), resource.GetResourceVersion())
+	}
+
+	if policy.MutateExistingEnabled() {
+		policyName := kyverno.PolicyKey(policy.GetNamespace(), policy.GetName())
+		pending := mutateExistingCount
+		// in apply mode the resources are pending until they are patched
+		if policy.Spec.MutateExistingAction != kyverno.MutateExistingDryRun {
+			pending = pc.mutateExistingRequests.pending(policyName)
+		}
+		pc.policyStatusListener.Send(mutateExistingStats{policyName: policyName, pending: pending})
+	}
+
+	if generateExisting && generateExistingCount > 0 {
+		pc.policyStatusListener.Send(generateExistingStats{
+			policyName: kyverno.PolicyKey(policy.GetNamespace(), policy.GetName()),
+			count:      generateExistingCount,
+		})
 	}
 	return engineResponses
 }
@@ -63,17 +99,29 @@ func (pc *PolicyController) listResources(policy *kyverno.ClusterPolicy) map[str
 	resourceMap := map[string]unstructured.Unstructured{}
 
 	for _, rule := range policy.Spec.Rules {
-		for _, k := range rule.MatchResources.Kinds {
+		for _, k := range rule.MatchKinds() {
+			apiVersion, kind, ok := getListableKind(k)
+			if !ok {
+				pc.log.V(4).Info("skip kind with wildcards or subresource in background processing", "kind", k)
+				continue
+			}
 
-			resourceSchema, _, err := pc.client.DiscoveryClient.FindResource("", k)
+			resourceSchema, _, err := pc.client.DiscoveryClient.FindResource(apiVersion, kind)
 			if err != nil {
 				pc.log.Error(err, "failed to find resource", "kind", k)
 				continue
 			}
 
 			if !resourceSchema.Namespaced {
+				// a namespaced policy does not apply to cluster-wide resources
+				if policy.Namespace != "" {
+					continue
+				}
 				rMap := getResourcesPerNamespace(k, pc.client, "", rule, pc.configHandler, pc.log)
 				mergeResources(resourceMap, rMap)
+			} else if policy.Namespace != "" {
+				rMap := getResourcesPerNamespace(k, pc.client, policy.Namespace, rule, pc.configHandler, pc.log)
+				mergeResources(resourceMap, rMap)
 			} else {
 				namespaces := getNamespacesForRule(&rule, pc.nsLister, pc.log)
 				for _, ns := range namespaces {
@@ -91,6 +139,20 @@ func (pc *PolicyController) listResources(policy *kyverno.ClusterPolicy) map[str
 	return resourceMap
 }
 
+// getListableKind returns the apiVersion and kind to list resources of a kind selector,
+// kinds with wildcards or a subresource cannot be listed
+func getListableKind(kindSelector string) (apiVersion, kind string, ok bool) {
+	group, version, kind, subresource := utils.ParseKindSelector(kindSelector)
+	if subresource != "" || strings.Contains(kind, "*") {
+		return "", "", false
+	}
+
+	if group != "*" && version != "*" {
+		apiVersion = schema.GroupVersion{Group: group, Version: version}.String()
+	}
+	return apiVersion, kind, true
+}
+
 // excludePod filter out the pods with ownerReference
 func excludePod(resourceMap map[string]unstructured.Unstructured, log logr.Logger) map[string]unstructured.Unstructured {
 	for uid, r := range resourceMap {
@@ -108,6 +170,10 @@ func excludePod(resourceMap map[string]unstructured.Unstructured, log logr.Logge
 }
 
 func getNamespacesForRule(rule *kyverno.Rule, nslister listerv1.NamespaceLister, log logr.Logger) []string {
+	if rule.MatchResources.NamespaceSelector != nil {
+		return getSelectedNamespaces(rule, nslister, log)
+	}
+
 	if len(rule.MatchResources.Namespaces) == 0 {
 		return getAllNamespaces(nslister, log)
 	}
@@ -130,6 +196,30 @@ func getNamespacesForRule(rule *kyverno.Rule, nslister listerv1.NamespaceLister,
 	return results
 }
 
+// getSelectedNamespaces returns the namespaces selected by the namespace selector of the rule,
+// that also match the namespace names of the rule if they are specified
+func getSelectedNamespaces(rule *kyverno.Rule, nslister listerv1.NamespaceLister, log logr.Logger) []string {
+	selector, err := metav1.LabelSelectorAsSelector(rule.MatchResources.NamespaceSelector)
+	if err != nil {
+		log.Error(err, "failed to build namespace selector")
+		return nil
+	}
+
+	namespaces, err := nslister.List(selector)
+	if err != nil {
+		log.Error(err, "Failed to list namespaces")
+		return nil
+	}
+
+	var results []string
+	for _, ns := range namespaces {
+		if len(rule.MatchResources.Namespaces) == 0 || utils.ContainsNamepace(rule.MatchResources.Namespaces, ns.GetName()) {
+			results = append(results, ns.GetName())
+		}
+	}
+	return results
+}
+
 func hasWildcard(s string) bool {
 	if s == "" {
 		return false
@@ -171,17 +261,22 @@ func getAllNamespaces(nslister listerv1.NamespaceLister, log logr.Logger) []stri
 	return results
 }
 
-func getResourcesPerNamespace(kind string, client *client.Client, namespace string, rule kyverno.Rule, configHandler config.Interface, log logr.Logger) map[string]unstructured.Unstructured {
+func getResourcesPerNamespace(kindSelector string, client *client.Client, namespace string, rule kyverno.Rule, configHandler config.Interface, log logr.Logger) map[string]unstructured.Unstructured {
 	resourceMap := map[string]unstructured.Unstructured{}
 	ls := rule.MatchResources.Selector
 
+	apiVersion, kind, ok := getListableKind(kindSelector)
+	if !ok {
+		return resourceMap
+	}
+
 	if kind == "Namespace" {
 		namespace = ""
 	}
 
-	list, err := client.ListResource("", kind, namespace, ls)
+	list, err := client.ListResource(apiVersion, kind, namespace, ls)
 	if err != nil {
-		log.Error(err, "failed to list resources", "kind", kind, "namespace", namespace)
+		log.Error(err, "failed to list resources", "kind", kindSelector, "namespace", namespace)
 		return nil
 	}
 	// filter based on name
@@ -190,6 +285,11 @@ func getResourcesPerNamespace(kind string, client *client.Client, namespace stri
 			continue
 		}
 
+		// the preferred version may be listed for a selector with a group or version wildcard
+		if !utils.MatchKindSelector(kindSelector, r.GroupVersionKind(), "") {
+			continue
+		}
+
 		if r.GetKind() == "Pod" {
 			if !isRunningPod(r) {
 				continue
@@ -257,21 +357,21 @@ func excludeResources(included map[string]unstructured.Unstructured, exclude kyv
 		return Process
 	}
 
-	findKind := func(kind string, kinds []string) bool {
+	findKind := func(gvk schema.GroupVersionKind, kinds []string) bool {
 		for _, k := range kinds {
-			if k == kind {
+			if utils.MatchKindSelector(k, gvk, "") {
 				return true
 			}
 		}
 		return false
 	}
 
-	excludeKind := func(kind string) Condition {
+	excludeKind := func(gvk schema.GroupVersionKind) Condition {
 		if len(exclude.Kinds) == 0 {
 			return NotEvaluate
 		}
 
-		if findKind(kind, exclude.Kinds) {
+		if findKind(gvk, exclude.Kinds) {
 			return Skip
 		}
 
@@ -294,7 +394,7 @@ func excludeResources(included map[string]unstructured.Unstructured, exclude kyv
 		if ret := excludeSelector(resource.GetLabels()); ret != NotEvaluate {
 			excludeEval = append(excludeEval, ret)
 		}
-		if ret := excludeKind(resource.GetKind()); ret != NotEvaluate {
+		if ret := excludeKind(resource.GroupVersionKind()); ret != NotEvaluate {
 			excludeEval = append(excludeEval, ret)
 		}
 		// exclude the filtered resources
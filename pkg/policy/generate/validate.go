@@ -3,6 +3,7 @@ package generate
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/go-logr/logr"
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
@@ -10,6 +11,7 @@ import (
 	"github.com/nirmata/kyverno/pkg/engine/anchor"
 	"github.com/nirmata/kyverno/pkg/engine/variables"
 	"github.com/nirmata/kyverno/pkg/policy/common"
+	"github.com/nirmata/kyverno/pkg/utils"
 )
 
 // Generate provides implementation to validate 'generate' rule
@@ -36,14 +38,32 @@ func NewGenerateFactory(client *dclient.Client, rule kyverno.Generation, log log
 //Validate validates the 'generate' rule
 func (g *Generate) Validate() (string, error) {
 	rule := g.rule
-	if rule.Data == nil && rule.Clone == (kyverno.CloneFrom{}) {
-		return "", fmt.Errorf("clone or data are required")
+	hasClone, hasCloneList := rule.Clone != (kyverno.CloneFrom{}), !reflect.DeepEqual(rule.CloneList, kyverno.CloneList{})
+	if rule.Data == nil && !hasClone && !hasCloneList {
+		return "", fmt.Errorf("clone, cloneList or data are required")
 	}
-	if rule.Data != nil && rule.Clone != (kyverno.CloneFrom{}) {
-		return "", fmt.Errorf("only one operation allowed per generate rule(data or clone)")
+	if (rule.Data != nil && hasClone) || (rule.Data != nil && hasCloneList) || (hasClone && hasCloneList) {
+		return "", fmt.Errorf("only one operation allowed per generate rule(data, clone or cloneList)")
+	}
+	if rule.Data != nil && (rule.PatchStrategicMerge != nil || rule.PatchesJSON6902 != "") {
+		return "", fmt.Errorf("patches can only be applied to cloned resources")
 	}
 	kind, name, namespace := rule.Kind, rule.Name, rule.Namespace
 
+	// the cloned resources keep the kind and the name of their source
+	if hasCloneList {
+		if kind != "" || name != "" {
+			return "", fmt.Errorf("kind and name cannot be set with cloneList")
+		}
+		if namespace == "" {
+			return "namespace", fmt.Errorf("namespace cannot be empty")
+		}
+		if path, err := g.validateCloneList(rule.CloneList, namespace); err != nil {
+			return fmt.Sprintf("cloneList.%s", path), err
+		}
+		return "", nil
+	}
+
 	if name == "" {
 		return "name", fmt.Errorf("name cannot be empty")
 	}
@@ -100,6 +120,42 @@ func (g *Generate) validateClone(c kyverno.CloneFrom, kind string) (string, erro
 	return "", nil
 }
 
+func (g *Generate) validateCloneList(c kyverno.CloneList, namespace string) (string, error) {
+	if len(c.Kinds) == 0 {
+		return "kinds", fmt.Errorf("kinds cannot be empty")
+	}
+	if c.Namespace == "" {
+		return "namespace", fmt.Errorf("namespace cannot be empty")
+	}
+	if c.Namespace == namespace {
+		return "namespace", fmt.Errorf("the resources cannot be cloned in their own namespace")
+	}
+
+	for i, kindSelector := range c.Kinds {
+		_, _, kind, subresource := utils.ParseKindSelector(kindSelector)
+		if subresource != "" || strings.Contains(kind, "*") {
+			return fmt.Sprintf("kinds[%d]", i), fmt.Errorf("kind '%s' cannot be cloned, wildcards and subresources are not supported", kindSelector)
+		}
+
+		if variables.IsVariable(c.Namespace) {
+			continue
+		}
+		// GET
+		ok, err := g.authCheck.CanIGet(kind, c.Namespace)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", fmt.Errorf("kyverno does not have permissions to 'get' resource %s/%s. Update permissions in ClusterRole 'kyverno:generatecontroller'", kind, c.Namespace)
+		}
+
+		if err := g.canIGenerate(kind, namespace); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
 //canIGenerate returns a error if kyverno cannot perform oprations
 func (g *Generate) canIGenerate(kind, namespace string) error {
 	// Skip if there is variable defined
@@ -87,3 +87,96 @@ func Test_Validate_Generate_HasAnchors(t *testing.T) {
 		assert.Assert(t, err != nil)
 	}
 }
+
+func Test_Validate_Generate_CloneList(t *testing.T) {
+	testcases := []struct {
+		description string
+		rawGenerate []byte
+		expectedErr bool
+	}{
+		{
+			description: "cloneList",
+			rawGenerate: []byte(`
+			{
+				"namespace": "{{request.object.metadata.name}}",
+				"cloneList": {
+					"namespace": "default",
+					"kinds": ["v1/ConfigMap", "Secret"],
+					"selector": {"matchLabels": {"allowedToBeCloned": "true"}}
+				},
+				"patchStrategicMerge": {"metadata": {"labels": {"cloned": "true"}}}
+			}`),
+		},
+		{
+			description: "cloneList with name",
+			rawGenerate: []byte(`
+			{
+				"name": "copied",
+				"namespace": "prod",
+				"cloneList": {"namespace": "default", "kinds": ["ConfigMap"]}
+			}`),
+			expectedErr: true,
+		},
+		{
+			description: "cloneList without kinds",
+			rawGenerate: []byte(`
+			{
+				"namespace": "prod",
+				"cloneList": {"namespace": "default"}
+			}`),
+			expectedErr: true,
+		},
+		{
+			description: "cloneList in the source namespace",
+			rawGenerate: []byte(`
+			{
+				"namespace": "default",
+				"cloneList": {"namespace": "default", "kinds": ["ConfigMap"]}
+			}`),
+			expectedErr: true,
+		},
+		{
+			description: "cloneList with wildcard kind",
+			rawGenerate: []byte(`
+			{
+				"namespace": "prod",
+				"cloneList": {"namespace": "default", "kinds": ["*"]}
+			}`),
+			expectedErr: true,
+		},
+		{
+			description: "cloneList and clone",
+			rawGenerate: []byte(`
+			{
+				"kind": "ConfigMap",
+				"name": "copied",
+				"namespace": "prod",
+				"clone": {"namespace": "default", "name": "game"},
+				"cloneList": {"namespace": "default", "kinds": ["ConfigMap"]}
+			}`),
+			expectedErr: true,
+		},
+		{
+			description: "patches with data",
+			rawGenerate: []byte(`
+			{
+				"kind": "ConfigMap",
+				"name": "generated",
+				"namespace": "prod",
+				"data": {"data": {"key": "value"}},
+				"patchesJson6902": "- op: add\n  path: /data/other\n  value: value"
+			}`),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		var genRule kyverno.Generation
+		err := json.Unmarshal(tc.rawGenerate, &genRule)
+		assert.NilError(t, err, tc.description)
+
+		checker := NewFakeGenerate(genRule)
+		_, err = checker.Validate()
+		assert.Equal(t, err != nil, tc.expectedErr, tc.description)
+	}
+}
@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/go-logr/logr"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/config"
+	"github.com/nirmata/kyverno/pkg/engine"
+	"github.com/nirmata/kyverno/pkg/engine/context"
+	"github.com/nirmata/kyverno/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// generateExistingQPS is the number of generate requests created per second for existing triggers
+	generateExistingQPS = 5
+	// generateExistingBurst is the maximum number of generate requests created at once for existing triggers
+	generateExistingBurst = 10
+)
+
+// generateExistingResource creates a generate request for an existing trigger resource that
+// matches the generate rules of the policy. The name of the generate request is derived from
+// the policy and the trigger, so a trigger is only requested once per policy.
+// It returns true if a generate request was created.
+func (pc *PolicyController) generateExistingResource(policy *kyverno.ClusterPolicy, resource unstructured.Unstructured, logger logr.Logger) (bool, error) {
+	ctx := context.NewContext()
+	if err := ctx.AddResource(transformResource(resource)); err != nil {
+		return false, err
+	}
+
+	policyContext := engine.PolicyContext{
+		Policy:           *policy,
+		NewResource:      resource,
+		Context:          ctx,
+		ExcludeGroupRole: pc.configHandler.GetExcludeGroupRole(),
+		ConfigMapLister:  pc.cmLister,
+		Client:           pc.client,
+		NamespaceLabels:  utils.GetNamespaceLabels(pc.nsLister, resource.GetNamespace(), logger),
+	}
+
+	engineResponse := engine.Generate(policyContext)
+	if len(engineResponse.PolicyResponse.Rules) == 0 {
+		return false, nil
+	}
+
+	gr := kyverno.GenerateRequest{
+		Spec: kyverno.GenerateRequestSpec{
+			Policy: kyverno.PolicyKey(policy.GetNamespace(), policy.GetName()),
+			Resource: kyverno.ResourceSpec{
+				Kind:      resource.GetKind(),
+				Namespace: resource.GetNamespace(),
+				Name:      resource.GetName(),
+			},
+		},
+	}
+	gr.SetName(generateExistingRequestName(kyverno.PolicyKey(policy.GetNamespace(), policy.GetName()), resource))
+	gr.SetNamespace(config.KubePolicyNamespace)
+	// the generate controller skips the triggers created before the policy, unless requested
+	gr.SetLabels(map[string]string{kyverno.GenerateExistingLabel: "true"})
+
+	pc.generateExistingLimiter.Accept()
+	_, err := pc.kyvernoClient.KyvernoV1().GenerateRequests(config.KubePolicyNamespace).Create(&gr)
+	if errors.IsAlreadyExists(err) {
+		logger.V(4).Info("generate request already exists for existing trigger", "generateRequest", gr.GetName())
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	logger.V(3).Info("created generate request for existing trigger", "generateRequest", gr.GetName())
+	return true, nil
+}
+
+// generateExistingRequestName returns the name of the generate request of an existing trigger,
+// the uid distinguishes a trigger that was deleted and created again
+func generateExistingRequestName(policyKey string, resource unstructured.Unstructured) string {
+	sum := sha256.Sum256([]byte(policyKey + "/" + resource.GetKind() + "/" + resource.GetNamespace() + "/" + resource.GetName() + "/" + string(resource.GetUID())))
+	return "gr-existing-" + hex.EncodeToString(sum[:])[:16]
+}
+
+// generateExistingStats records the generate requests created for existing triggers in the policy status
+type generateExistingStats struct {
+	policyName string
+	count      int
+}
+
+func (gs generateExistingStats) PolicyName() string {
+	return gs.policyName
+}
+
+func (gs generateExistingStats) UpdateStatus(status kyverno.PolicyStatus) kyverno.PolicyStatus {
+	status.ExistingGenerateRequestsCount += gs.count
+	return status
+}
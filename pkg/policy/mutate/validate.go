@@ -36,11 +36,43 @@ func (m *Mutate) Validate() (string, error) {
 	}
 	// Overlay
 	if rule.Overlay != nil {
-		path, err := common.ValidatePattern(rule.Overlay, "/", []anchor.IsAnchor{anchor.IsConditionAnchor, anchor.IsAddingAnchor})
+		path, err := common.ValidatePattern(rule.Overlay, "/", []anchor.IsAnchor{anchor.IsConditionAnchor, anchor.IsAddingAnchor, anchor.IsGlobalAnchor})
 		if err != nil {
 			return path, err
 		}
 	}
+	// ForEach
+	if rule.ForEachMutation != nil {
+		if rule.Overlay != nil || len(rule.Patches) != 0 || rule.PatchStrategicMerge != nil || rule.PatchesJSON6902 != "" {
+			return "foreach", errors.New("foreach cannot be combined with other mutations")
+		}
+		if path, err := validateForEach(rule.ForEachMutation); err != nil {
+			return fmt.Sprintf("foreach.%s", path), err
+		}
+	}
+	// Targets
+	if len(rule.Targets) != 0 {
+		if rule.ForEachMutation != nil {
+			return "targets", errors.New("targets cannot be combined with foreach")
+		}
+		for i, target := range rule.Targets {
+			if target.Kind == "" {
+				return fmt.Sprintf("targets[%d].kind", i), errors.New("a kind is required")
+			}
+		}
+	}
+	return "", nil
+}
+
+// validateForEach checks the list and the patch applied for each element
+func validateForEach(foreach *kyverno.ForEachMutation) (string, error) {
+	if foreach.List == "" {
+		return "list", errors.New("a list is required")
+	}
+
+	if (foreach.PatchStrategicMerge == nil) == (foreach.PatchesJSON6902 == "") {
+		return "", errors.New("either patchStrategicMerge or patchesJson6902 must be specified")
+	}
 	return "", nil
 }
 
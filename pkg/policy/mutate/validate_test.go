@@ -149,3 +149,61 @@ func Test_Validate_Mutate_Unsupported(t *testing.T) {
 		assert.Assert(t, err != nil)
 	}
 }
+
+func TestValidateForEach(t *testing.T) {
+	testcases := []struct {
+		rawMutate []byte
+		valid     bool
+	}{
+		{
+			rawMutate: []byte(`{"foreach": {"list": "request.object.spec.containers", "patchesJson6902": "- op: add\n  path: /spec/containers/{{elementIndex}}/imagePullPolicy\n  value: Always"}}`),
+			valid:     true,
+		},
+		{
+			rawMutate: []byte(`{"foreach": {"list": "request.object.spec.containers"}}`),
+			valid:     false,
+		},
+		{
+			rawMutate: []byte(`{"foreach": {"patchStrategicMerge": {"metadata": {"labels": {"app": "nginx"}}}}}`),
+			valid:     false,
+		},
+		{
+			rawMutate: []byte(`{"overlay": {"metadata": {"labels": {"app": "nginx"}}}, "foreach": {"list": "request.object.spec.containers", "patchStrategicMerge": {"metadata": {"labels": {"app": "nginx"}}}}}`),
+			valid:     false,
+		},
+	}
+
+	for i, tc := range testcases {
+		var mutate kyverno.Mutation
+		assert.NilError(t, json.Unmarshal(tc.rawMutate, &mutate))
+		_, err := NewMutateFactory(mutate).Validate()
+		assert.Equal(t, err == nil, tc.valid, "testcase %d", i)
+	}
+}
+
+func TestValidateTargets(t *testing.T) {
+	testcases := []struct {
+		rawMutate []byte
+		valid     bool
+	}{
+		{
+			rawMutate: []byte(`{"targets": [{"apiVersion": "apps/v1", "kind": "Deployment", "namespace": "{{request.object.metadata.namespace}}"}], "patchStrategicMerge": {"metadata": {"annotations": {"secret-version": "{{request.object.metadata.resourceVersion}}"}}}}`),
+			valid:     true,
+		},
+		{
+			rawMutate: []byte(`{"targets": [{"namespace": "default", "name": "quota-*"}], "patchStrategicMerge": {"spec": {"hard": {"pods": "10"}}}}`),
+			valid:     false,
+		},
+		{
+			rawMutate: []byte(`{"targets": [{"kind": "Deployment"}], "foreach": {"list": "request.object.spec.containers", "patchStrategicMerge": {"metadata": {"labels": {"app": "nginx"}}}}}`),
+			valid:     false,
+		},
+	}
+
+	for i, tc := range testcases {
+		var mutate kyverno.Mutation
+		assert.NilError(t, json.Unmarshal(tc.rawMutate, &mutate))
+		_, err := NewMutateFactory(mutate).Validate()
+		assert.Equal(t, err == nil, tc.valid, "testcase %d", i)
+	}
+}
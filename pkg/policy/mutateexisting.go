@@ -0,0 +1,213 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/go-logr/logr"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	engineutils "github.com/nirmata/kyverno/pkg/engine/utils"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// mutateExistingQPS is the number of existing resources patched per second
+	mutateExistingQPS = 5
+	// mutateExistingBurst is the maximum number of existing resources patched at once
+	mutateExistingBurst = 10
+
+	mutateExistingQueueName = "mutate-existing"
+)
+
+// mutateExistingRequest is a patch of an existing resource waiting in the mutate existing queue
+type mutateExistingRequest struct {
+	policyName     string
+	resource       unstructured.Unstructured
+	patches        [][]byte
+	engineResponse response.EngineResponse
+}
+
+// mutateExistingRequests holds the pending patches by queue key, a resource
+// processed again before it is patched replaces its pending patch
+type mutateExistingRequests struct {
+	mu       sync.Mutex
+	requests map[string]*mutateExistingRequest
+}
+
+func newMutateExistingRequests() *mutateExistingRequests {
+	return &mutateExistingRequests{requests: make(map[string]*mutateExistingRequest)}
+}
+
+func (r *mutateExistingRequests) add(key string, request *mutateExistingRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests[key] = request
+}
+
+func (r *mutateExistingRequests) get(key string) *mutateExistingRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests[key]
+}
+
+// pending returns the number of resources of the policy waiting to be patched
+func (r *mutateExistingRequests) pending(policyName string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, request := range r.requests {
+		if request.policyName == policyName {
+			count++
+		}
+	}
+	return count
+}
+
+// remove deletes the request unless it was replaced by a newer one
+func (r *mutateExistingRequests) remove(key string, request *mutateExistingRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.requests[key] == request {
+		delete(r.requests, key)
+	}
+}
+
+// mutateExistingResource checks if an existing resource requires mutation by the policy, the
+// mutate rules of the engine response are reported as failed until the resource is patched.
+// In apply mode the patch is queued, in dryRun mode the resource is not patched.
+// It returns true if the resource requires mutation.
+func (pc *PolicyController) mutateExistingResource(policy *kyverno.ClusterPolicy, resource unstructured.Unstructured, engineResponse *response.EngineResponse, logger logr.Logger) bool {
+	if reflect.DeepEqual(resource, engineResponse.PatchedResource) {
+		return false
+	}
+
+	var patches [][]byte
+	for _, rule := range engineResponse.PolicyResponse.Rules {
+		if rule.Type == engineutils.Mutation.String() {
+			patches = append(patches, rule.Patches...)
+		}
+	}
+
+	if len(patches) == 0 {
+		return false
+	}
+
+	if policy.Spec.MutateExistingAction == kyverno.MutateExistingDryRun {
+		setMutateRuleResults(engineResponse, false, "existing resource requires mutation")
+		logger.V(2).Info("existing resource requires mutation", "patches", string(engineutils.JoinPatches(patches)))
+		return true
+	}
+
+	setMutateRuleResults(engineResponse, false, "existing resource is queued for mutation")
+	pc.enqueueMutateExisting(&mutateExistingRequest{
+		policyName:     kyverno.PolicyKey(policy.GetNamespace(), policy.GetName()),
+		resource:       resource,
+		patches:        patches,
+		engineResponse: *engineResponse,
+	})
+	logger.V(3).Info("queued existing resource for mutation")
+	return true
+}
+
+func (pc *PolicyController) enqueueMutateExisting(request *mutateExistingRequest) {
+	resource := request.resource
+	key := request.policyName + "/" + resource.GetKind() + "/" + resource.GetNamespace() + "/" + resource.GetName()
+	pc.mutateExistingRequests.add(key, request)
+	pc.mutateExistingQueue.Add(key)
+}
+
+func (pc *PolicyController) mutateExistingWorker() {
+	for pc.processNextMutateExisting() {
+	}
+}
+
+func (pc *PolicyController) processNextMutateExisting() bool {
+	key, quit := pc.mutateExistingQueue.Get()
+	if quit {
+		return false
+	}
+	defer pc.mutateExistingQueue.Done(key)
+
+	request := pc.mutateExistingRequests.get(key.(string))
+	if request == nil {
+		pc.mutateExistingQueue.Forget(key)
+		return true
+	}
+
+	resource := request.resource
+	logger := pc.log.WithValues("policy", request.policyName, "kind", resource.GetKind(), "namespace", resource.GetNamespace(), "name", resource.GetName())
+
+	pc.mutateExistingLimiter.Accept()
+	err := pc.patchExistingResource(request)
+	// the patch is not retried if the resource has been changed since it was processed
+	if err != nil && !errors.IsInvalid(err) && !errors.IsConflict(err) && !errors.IsNotFound(err) && pc.mutateExistingQueue.NumRequeues(key) < maxRetries {
+		logger.Error(err, "failed to mutate existing resource, re-queue")
+		pc.mutateExistingQueue.AddRateLimited(key)
+		return true
+	}
+
+	pc.mutateExistingQueue.Forget(key)
+	pc.mutateExistingRequests.remove(key.(string), request)
+
+	stats := mutateExistingStats{
+		policyName: request.policyName,
+		pending:    pc.mutateExistingRequests.pending(request.policyName),
+	}
+	if err != nil {
+		logger.Error(err, "failed to mutate existing resource")
+		setMutateRuleResults(&request.engineResponse, false, fmt.Sprintf("failed to mutate existing resource: %v", err))
+	} else {
+		logger.V(3).Info("mutated existing resource")
+		setMutateRuleResults(&request.engineResponse, true, "mutated existing resource")
+		stats.mutated = 1
+	}
+	pc.policyStatusListener.Send(stats)
+
+	pc.cleanupAndReport([]response.EngineResponse{request.engineResponse})
+	return true
+}
+
+// patchExistingResource applies the patches of the request to the resource
+func (pc *PolicyController) patchExistingResource(request *mutateExistingRequest) error {
+	resource := request.resource
+	// the patches were computed for this version of the resource, the test
+	// operation fails the patch if the resource has been changed since
+	testVersion := fmt.Sprintf(`{ "op": "test", "path": "/metadata/resourceVersion", "value": "%s" }`, resource.GetResourceVersion())
+	patches := append([][]byte{[]byte(testVersion)}, request.patches...)
+
+	_, err := pc.client.PatchResource(resource.GetAPIVersion(), resource.GetKind(), resource.GetNamespace(), resource.GetName(), engineutils.JoinPatches(patches))
+	return err
+}
+
+// setMutateRuleResults sets the result of the mutate rules with patches
+func setMutateRuleResults(engineResponse *response.EngineResponse, success bool, message string) {
+	for i, rule := range engineResponse.PolicyResponse.Rules {
+		if rule.Type != engineutils.Mutation.String() || len(rule.Patches) == 0 {
+			continue
+		}
+
+		engineResponse.PolicyResponse.Rules[i].Success = success
+		engineResponse.PolicyResponse.Rules[i].Message = message
+	}
+}
+
+// mutateExistingStats records the results of the mutation of existing resources in the policy status,
+// pending is the number of resources that require mutation or are queued to be patched
+type mutateExistingStats struct {
+	policyName string
+	pending    int
+	mutated    int
+}
+
+func (ms mutateExistingStats) PolicyName() string {
+	return ms.policyName
+}
+
+func (ms mutateExistingStats) UpdateStatus(status kyverno.PolicyStatus) kyverno.PolicyStatus {
+	status.ExistingResourcesPendingCount = ms.pending
+	status.ExistingResourcesMutatedCount += ms.mutated
+	return status
+}
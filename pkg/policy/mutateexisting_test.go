@@ -0,0 +1,103 @@
+package policy
+
+import (
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	engineutils "github.com/nirmata/kyverno/pkg/engine/utils"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newMutateExistingResponse(resource unstructured.Unstructured) response.EngineResponse {
+	patched := resource.DeepCopy()
+	patched.SetLabels(map[string]string{"app": "nginx"})
+	return response.EngineResponse{
+		PatchedResource: *patched,
+		PolicyResponse: response.PolicyResponse{
+			Rules: []response.RuleResponse{
+				{
+					Name:    "add-labels",
+					Type:    engineutils.Mutation.String(),
+					Success: true,
+					Patches: [][]byte{[]byte(`{"op":"add","path":"/metadata/labels","value":{"app":"nginx"}}`)},
+				},
+				{
+					Name:    "require-labels",
+					Type:    engineutils.Validation.String(),
+					Success: true,
+				},
+			},
+		},
+	}
+}
+
+func Test_MutateExistingResource(t *testing.T) {
+	pc := &PolicyController{
+		mutateExistingQueue:    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), mutateExistingQueueName),
+		mutateExistingRequests: newMutateExistingRequests(),
+		log:                    log.Log,
+	}
+	defer pc.mutateExistingQueue.ShutDown()
+
+	var resource unstructured.Unstructured
+	resource.SetAPIVersion("v1")
+	resource.SetKind("Pod")
+	resource.SetNamespace("default")
+	resource.SetName("nginx")
+
+	policy := &kyverno.ClusterPolicy{}
+	policy.SetName("add-labels")
+
+	// the pending resources are reported in dryRun mode, and not patched
+	policy.Spec.MutateExistingAction = kyverno.MutateExistingDryRun
+	engineResponse := newMutateExistingResponse(resource)
+	assert.Assert(t, pc.mutateExistingResource(policy, resource, &engineResponse, log.Log))
+	assert.Assert(t, !engineResponse.PolicyResponse.Rules[0].Success)
+	assert.Equal(t, engineResponse.PolicyResponse.Rules[0].Message, "existing resource requires mutation")
+	assert.Assert(t, engineResponse.PolicyResponse.Rules[1].Success)
+	assert.Equal(t, pc.mutateExistingQueue.Len(), 0)
+
+	// the patches are queued in apply mode
+	policy.Spec.MutateExistingAction = kyverno.MutateExistingApply
+	engineResponse = newMutateExistingResponse(resource)
+	assert.Assert(t, pc.mutateExistingResource(policy, resource, &engineResponse, log.Log))
+	assert.Assert(t, !engineResponse.PolicyResponse.Rules[0].Success)
+	assert.Equal(t, pc.mutateExistingQueue.Len(), 1)
+
+	key := "add-labels/Pod/default/nginx"
+	request := pc.mutateExistingRequests.get(key)
+	assert.Assert(t, request != nil)
+	assert.Equal(t, len(request.patches), 1)
+	assert.Equal(t, pc.mutateExistingRequests.pending("add-labels"), 1)
+	assert.Equal(t, pc.mutateExistingRequests.pending("other"), 0)
+
+	// a newer request for the resource is not removed with the processed one
+	engineResponse = newMutateExistingResponse(resource)
+	assert.Assert(t, pc.mutateExistingResource(policy, resource, &engineResponse, log.Log))
+	assert.Equal(t, pc.mutateExistingQueue.Len(), 1)
+	pc.mutateExistingRequests.remove(key, request)
+	assert.Assert(t, pc.mutateExistingRequests.get(key) != nil)
+
+	// a resource that does not require mutation is not queued
+	engineResponse = newMutateExistingResponse(resource)
+	engineResponse.PatchedResource = resource
+	assert.Assert(t, !pc.mutateExistingResource(policy, resource, &engineResponse, log.Log))
+}
+
+func Test_MutateExistingStats(t *testing.T) {
+	status := kyverno.PolicyStatus{ExistingResourcesMutatedCount: 1}
+
+	// a resource is patched while other resources of the policy are still queued
+	status = mutateExistingStats{policyName: "add-labels", pending: 2, mutated: 1}.UpdateStatus(status)
+	assert.Equal(t, status.ExistingResourcesPendingCount, 2)
+	assert.Equal(t, status.ExistingResourcesMutatedCount, 2)
+
+	// the patch of the last queued resource failed
+	status = mutateExistingStats{policyName: "add-labels", pending: 0}.UpdateStatus(status)
+	assert.Equal(t, status.ExistingResourcesPendingCount, 0)
+	assert.Equal(t, status.ExistingResourcesMutatedCount, 2)
+}
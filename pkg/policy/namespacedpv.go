@@ -107,6 +107,16 @@ func (pc *PolicyController) deleteNamespacedPolicyViolation(obj interface{}) {
 
 func (pc *PolicyController) getPolicyForNamespacedPolicyViolation(pv *kyverno.PolicyViolation) []*kyverno.ClusterPolicy {
 	logger := pc.log.WithValues("kind", pv.Kind, "namespace", pv.Namespace, "name", pv.Name)
+	// the violations of a namespaced policy are annotated with the namespace of the policy
+	if ns := pv.Annotations[kyverno.PolicyNamespaceAnnotation]; ns != "" {
+		nsPolicy, err := pc.npLister.Policies(ns).Get(pv.Spec.Policy)
+		if err != nil {
+			logger.V(4).Info("missing namespaced policy for namespaced policy violation", "policy", kyverno.PolicyKey(ns, pv.Spec.Policy), "reason", err.Error())
+			return nil
+		}
+		return []*kyverno.ClusterPolicy{kyverno.ConvertPolicyToClusterPolicy(nsPolicy)}
+	}
+
 	policies, err := pc.pLister.GetPolicyForNamespacedPolicyViolation(pv)
 	if err != nil || len(policies) == 0 {
 		logger.V(4).Info("missing policy for namespaced policy violation", "reason", err.Error())
@@ -0,0 +1,96 @@
+package policy
+
+import (
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	kyvernolister "github.com/nirmata/kyverno/pkg/client/listers/kyverno/v1"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func Test_GetPolicyForNamespacedPolicyViolation(t *testing.T) {
+	pIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.NilError(t, pIndexer.Add(&kyverno.ClusterPolicy{ObjectMeta: metav1.ObjectMeta{Name: "require-labels"}}))
+	npIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.NilError(t, npIndexer.Add(&kyverno.Policy{ObjectMeta: metav1.ObjectMeta{Name: "require-labels", Namespace: "dev"}}))
+
+	pc := &PolicyController{
+		pLister:  kyvernolister.NewClusterPolicyLister(pIndexer),
+		npLister: kyvernolister.NewPolicyLister(npIndexer),
+		log:      log.Log,
+	}
+
+	newPV := func(annotations map[string]string) *kyverno.PolicyViolation {
+		return &kyverno.PolicyViolation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "require-labels-pod",
+				Namespace:   "dev",
+				Labels:      map[string]string{"policy": "require-labels"},
+				Annotations: annotations,
+			},
+			Spec: kyverno.PolicyViolationSpec{Policy: "require-labels"},
+		}
+	}
+
+	// a violation of the cluster policy in the namespace of a namespaced policy with the same name
+	policies := pc.getPolicyForNamespacedPolicyViolation(newPV(nil))
+	assert.Equal(t, len(policies), 1)
+	assert.Equal(t, policies[0].Namespace, "")
+
+	policies = pc.getPolicyForNamespacedPolicyViolation(newPV(map[string]string{kyverno.PolicyNamespaceAnnotation: "dev"}))
+	assert.Equal(t, len(policies), 1)
+	assert.Equal(t, policies[0].Namespace, "dev")
+
+	policies = pc.getPolicyForNamespacedPolicyViolation(newPV(map[string]string{kyverno.PolicyNamespaceAnnotation: "prod"}))
+	assert.Equal(t, len(policies), 0)
+}
+
+func Test_GetNamespacedPolicyViolationForPolicy(t *testing.T) {
+	nspvIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, pv := range []struct {
+		name, namespace, policyNamespace string
+	}{
+		{name: "cluster-dev", namespace: "dev"},
+		{name: "cluster-prod", namespace: "prod"},
+		{name: "namespaced-dev", namespace: "dev", policyNamespace: "dev"},
+	} {
+		nspv := &kyverno.PolicyViolation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pv.name,
+				Namespace: pv.namespace,
+				Labels:    map[string]string{"policy": "require-labels"},
+			},
+			Spec: kyverno.PolicyViolationSpec{Policy: "require-labels"},
+		}
+		if pv.policyNamespace != "" {
+			nspv.Annotations = map[string]string{kyverno.PolicyNamespaceAnnotation: pv.policyNamespace}
+		}
+		assert.NilError(t, nspvIndexer.Add(nspv))
+	}
+
+	pc := &PolicyController{
+		nspvLister: kyvernolister.NewPolicyViolationLister(nspvIndexer),
+		log:        log.Log,
+	}
+
+	names := func(pvs []*kyverno.PolicyViolation) map[string]bool {
+		result := make(map[string]bool)
+		for _, pv := range pvs {
+			result[pv.Name] = true
+		}
+		return result
+	}
+
+	// the violations of the cluster policy in all namespaces
+	pvs, err := pc.getNamespacedPolicyViolationForPolicy("", "require-labels")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names(pvs), map[string]bool{"cluster-dev": true, "cluster-prod": true})
+
+	// the violations of the namespaced policy with the same name
+	pvs, err = pc.getNamespacedPolicyViolationForPolicy("dev", "require-labels")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names(pvs), map[string]bool{"namespaced-dev": true})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path"
 	"reflect"
 	"strings"
 
@@ -13,6 +14,9 @@ import (
 
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	dclient "github.com/nirmata/kyverno/pkg/dclient"
+	"github.com/nirmata/kyverno/pkg/engine/jmespath"
+	"github.com/nirmata/kyverno/pkg/engine/variables"
+	"github.com/nirmata/kyverno/pkg/utils"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -36,6 +40,18 @@ func Validate(policyRaw []byte, client *dclient.Client, mock bool, openAPIContro
 		}
 	}
 
+	if path, err := validateFailureActionOverrides(p.Spec.ValidationFailureActionOverrides); err != nil {
+		return fmt.Errorf("path: spec.%s: %v", path, err)
+	}
+
+	if err := validateMutateExistingAction(p); err != nil {
+		return fmt.Errorf("path: spec.mutateExistingAction: %v", err)
+	}
+
+	if err := validateGenerateExisting(p); err != nil {
+		return fmt.Errorf("path: spec.generateExistingOnPolicyUpdate: %v", err)
+	}
+
 	for i, rule := range p.Spec.Rules {
 		// validate resource description
 		if path, err := validateResources(rule); err != nil {
@@ -48,6 +64,30 @@ func Validate(policyRaw []byte, client *dclient.Client, mock bool, openAPIContro
 			return fmt.Errorf("path: spec.rules[%d]: %v", i, err)
 		}
 
+		if path, err := validateRuleConditions(rule); err != nil {
+			return fmt.Errorf("path: spec.rules[%d].%s: %v", i, path, err)
+		}
+
+		if path, err := validateRuleContext(rule); err != nil {
+			return fmt.Errorf("path: spec.rules[%d].%s: %v", i, path, err)
+		}
+
+		if rule.ValidationFailureAction != "" {
+			if !rule.HasValidate() {
+				return fmt.Errorf("path: spec.rules[%d].validationFailureAction: only validate rules can set a validationFailureAction", i)
+			}
+			if err := validateFailureAction(rule.ValidationFailureAction); err != nil {
+				return fmt.Errorf("path: spec.rules[%d].validationFailureAction: %v", i, err)
+			}
+		}
+
+		// a namespaced policy can only refer to its own namespace
+		if p.Kind == "Policy" {
+			if path, err := validateNamespacedPolicyRule(rule, p.Namespace, client, mock); err != nil {
+				return fmt.Errorf("path: spec.rules[%d].%s: %v", i, path, err)
+			}
+		}
+
 		if doesMatchAndExcludeConflict(rule) {
 			return fmt.Errorf("path: spec.rules[%v]: rule is matching an empty set", rule.Name)
 		}
@@ -62,7 +102,7 @@ func Validate(policyRaw []byte, client *dclient.Client, mock bool, openAPIContro
 
 		// If a rules match block does not match any kind,
 		// we should only allow such rules to have metadata in its overlay
-		if len(rule.MatchResources.Kinds) == 0 {
+		if len(rule.MatchKinds()) == 0 {
 			if !ruleOnlyDealsWithResourceMetaData(rule) {
 				return fmt.Errorf("policy can only deal with the metadata field of the resource if" +
 					" the rule does not match an kind")
@@ -91,46 +131,112 @@ func doesMatchAndExcludeConflict(rule kyverno.Rule) bool {
 		return false
 	}
 
+	if !rule.HasResourceFilters() {
+		match := kyverno.ResourceFilter{UserInfo: rule.MatchResources.UserInfo, ResourceDescription: rule.MatchResources.ResourceDescription}
+		exclude := kyverno.ResourceFilter{UserInfo: rule.ExcludeResources.UserInfo, ResourceDescription: rule.ExcludeResources.ResourceDescription}
+		return doesResourceFilterConflict(match, exclude)
+	}
+
+	// with all, the matched set is contained in each filter, so a single excluded filter empties it
+	if len(rule.MatchResources.All) > 0 {
+		for _, match := range rule.MatchResources.All {
+			if isResourceFilterExcluded(match, rule.ExcludeResources) {
+				return true
+			}
+		}
+		return false
+	}
+
+	matchFilters := rule.MatchResources.Any
+	if len(matchFilters) == 0 {
+		matchFilters = kyverno.ResourceFilters{{UserInfo: rule.MatchResources.UserInfo, ResourceDescription: rule.MatchResources.ResourceDescription}}
+	}
+
+	for _, match := range matchFilters {
+		if !isResourceFilterExcluded(match, rule.ExcludeResources) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isResourceFilterExcluded checks if every resource selected by the match filter is excluded
+func isResourceFilterExcluded(match kyverno.ResourceFilter, exclude kyverno.ExcludeResources) bool {
+	if len(exclude.Any) > 0 {
+		for _, excludeFilter := range exclude.Any {
+			if doesResourceFilterConflict(match, excludeFilter) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(exclude.All) > 0 {
+		for _, excludeFilter := range exclude.All {
+			if !doesResourceFilterConflict(match, excludeFilter) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return doesResourceFilterConflict(match, kyverno.ResourceFilter{UserInfo: exclude.UserInfo, ResourceDescription: exclude.ResourceDescription})
+}
+
+// doesResourceFilterConflict checks if the exclude filter
+// removes every resource selected by the match filter
+func doesResourceFilterConflict(match, exclude kyverno.ResourceFilter) bool {
+
+	if reflect.DeepEqual(exclude, kyverno.ResourceFilter{}) {
+		return false
+	}
+
 	excludeRoles := make(map[string]bool)
-	for _, role := range rule.ExcludeResources.UserInfo.Roles {
+	for _, role := range exclude.UserInfo.Roles {
 		excludeRoles[role] = true
 	}
 
 	excludeClusterRoles := make(map[string]bool)
-	for _, clusterRoles := range rule.ExcludeResources.UserInfo.ClusterRoles {
+	for _, clusterRoles := range exclude.UserInfo.ClusterRoles {
 		excludeClusterRoles[clusterRoles] = true
 	}
 
 	excludeSubjects := make(map[string]bool)
-	for _, subject := range rule.ExcludeResources.UserInfo.Subjects {
+	for _, subject := range exclude.UserInfo.Subjects {
 		subjectRaw, _ := json.Marshal(subject)
 		excludeSubjects[string(subjectRaw)] = true
 	}
 
 	excludeKinds := make(map[string]bool)
-	for _, kind := range rule.ExcludeResources.ResourceDescription.Kinds {
+	for _, kind := range exclude.ResourceDescription.Kinds {
 		excludeKinds[kind] = true
 	}
 
 	excludeNamespaces := make(map[string]bool)
-	for _, namespace := range rule.ExcludeResources.ResourceDescription.Namespaces {
+	for _, namespace := range exclude.ResourceDescription.Namespaces {
 		excludeNamespaces[namespace] = true
 	}
 
+	excludeOperations := make(map[kyverno.AdmissionOperation]bool)
+	for _, op := range exclude.ResourceDescription.Operations {
+		excludeOperations[op] = true
+	}
+
 	excludeMatchExpressions := make(map[string]bool)
-	if rule.ExcludeResources.ResourceDescription.Selector != nil {
-		for _, matchExpression := range rule.ExcludeResources.ResourceDescription.Selector.MatchExpressions {
+	if exclude.ResourceDescription.Selector != nil {
+		for _, matchExpression := range exclude.ResourceDescription.Selector.MatchExpressions {
 			matchExpressionRaw, _ := json.Marshal(matchExpression)
 			excludeMatchExpressions[string(matchExpressionRaw)] = true
 		}
 	}
 
 	if len(excludeRoles) > 0 {
-		if len(rule.MatchResources.UserInfo.Roles) == 0 {
+		if len(match.UserInfo.Roles) == 0 {
 			return false
 		}
 
-		for _, role := range rule.MatchResources.UserInfo.Roles {
+		for _, role := range match.UserInfo.Roles {
 			if !excludeRoles[role] {
 				return false
 			}
@@ -138,11 +244,11 @@ func doesMatchAndExcludeConflict(rule kyverno.Rule) bool {
 	}
 
 	if len(excludeClusterRoles) > 0 {
-		if len(rule.MatchResources.UserInfo.ClusterRoles) == 0 {
+		if len(match.UserInfo.ClusterRoles) == 0 {
 			return false
 		}
 
-		for _, clusterRole := range rule.MatchResources.UserInfo.ClusterRoles {
+		for _, clusterRole := range match.UserInfo.ClusterRoles {
 			if !excludeClusterRoles[clusterRole] {
 				return false
 			}
@@ -150,11 +256,11 @@ func doesMatchAndExcludeConflict(rule kyverno.Rule) bool {
 	}
 
 	if len(excludeSubjects) > 0 {
-		if len(rule.MatchResources.UserInfo.Subjects) == 0 {
+		if len(match.UserInfo.Subjects) == 0 {
 			return false
 		}
 
-		for _, subject := range rule.MatchResources.UserInfo.Subjects {
+		for _, subject := range match.UserInfo.Subjects {
 			subjectRaw, _ := json.Marshal(subject)
 			if !excludeSubjects[string(subjectRaw)] {
 				return false
@@ -162,43 +268,67 @@ func doesMatchAndExcludeConflict(rule kyverno.Rule) bool {
 		}
 	}
 
-	if rule.ExcludeResources.ResourceDescription.Name != "" {
-		if !wildcard.Match(rule.ExcludeResources.ResourceDescription.Name, rule.MatchResources.ResourceDescription.Name) {
+	if exclude.ResourceDescription.Name != "" {
+		if !wildcard.Match(exclude.ResourceDescription.Name, match.ResourceDescription.Name) {
 			return false
 		}
 	}
 
 	if len(excludeNamespaces) > 0 {
-		if len(rule.MatchResources.ResourceDescription.Namespaces) == 0 {
+		if len(match.ResourceDescription.Namespaces) == 0 {
 			return false
 		}
 
-		for _, namespace := range rule.MatchResources.ResourceDescription.Namespaces {
+		for _, namespace := range match.ResourceDescription.Namespaces {
 			if !excludeNamespaces[namespace] {
 				return false
 			}
 		}
 	}
 
+	if len(excludeOperations) > 0 {
+		if len(match.ResourceDescription.Operations) == 0 {
+			return false
+		}
+
+		for _, op := range match.ResourceDescription.Operations {
+			if !excludeOperations[op] {
+				return false
+			}
+		}
+	}
+
 	if len(excludeKinds) > 0 {
-		if len(rule.MatchResources.ResourceDescription.Kinds) == 0 {
+		if len(match.ResourceDescription.Kinds) == 0 {
 			return false
 		}
 
-		for _, kind := range rule.MatchResources.ResourceDescription.Kinds {
+		for _, kind := range match.ResourceDescription.Kinds {
 			if !excludeKinds[kind] {
 				return false
 			}
 		}
 	}
 
-	if rule.MatchResources.ResourceDescription.Selector != nil && rule.ExcludeResources.ResourceDescription.Selector != nil {
+	// every matched resource must have the excluded annotations
+	for key, value := range exclude.ResourceDescription.Annotations {
+		if matchValue, ok := match.ResourceDescription.Annotations[key]; !ok || matchValue != value {
+			return false
+		}
+	}
+
+	if exclude.ResourceDescription.NamespaceSelector != nil &&
+		!reflect.DeepEqual(exclude.ResourceDescription.NamespaceSelector, match.ResourceDescription.NamespaceSelector) {
+		return false
+	}
+
+	if match.ResourceDescription.Selector != nil && exclude.ResourceDescription.Selector != nil {
 		if len(excludeMatchExpressions) > 0 {
-			if len(rule.MatchResources.ResourceDescription.Selector.MatchExpressions) == 0 {
+			if len(match.ResourceDescription.Selector.MatchExpressions) == 0 {
 				return false
 			}
 
-			for _, matchExpression := range rule.MatchResources.ResourceDescription.Selector.MatchExpressions {
+			for _, matchExpression := range match.ResourceDescription.Selector.MatchExpressions {
 				matchExpressionRaw, _ := json.Marshal(matchExpression)
 				if !excludeMatchExpressions[string(matchExpressionRaw)] {
 					return false
@@ -206,13 +336,13 @@ func doesMatchAndExcludeConflict(rule kyverno.Rule) bool {
 			}
 		}
 
-		if len(rule.ExcludeResources.ResourceDescription.Selector.MatchLabels) > 0 {
-			if len(rule.MatchResources.ResourceDescription.Selector.MatchLabels) == 0 {
+		if len(exclude.ResourceDescription.Selector.MatchLabels) > 0 {
+			if len(match.ResourceDescription.Selector.MatchLabels) == 0 {
 				return false
 			}
 
-			for label, value := range rule.MatchResources.ResourceDescription.Selector.MatchLabels {
-				if rule.ExcludeResources.ResourceDescription.Selector.MatchLabels[label] != value {
+			for label, value := range match.ResourceDescription.Selector.MatchLabels {
+				if exclude.ResourceDescription.Selector.MatchLabels[label] != value {
 					return false
 				}
 			}
@@ -252,6 +382,12 @@ func ruleOnlyDealsWithResourceMetaData(rule kyverno.Rule) bool {
 		}
 	}
 
+	for _, path := range rule.Validation.Immutable {
+		if !strings.HasPrefix(path, "/metadata") {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -262,16 +398,321 @@ func validateResources(rule kyverno.Rule) (string, error) {
 	}
 
 	// matched resources
-	if path, err := validateMatchedResourceDescription(rule.MatchResources.ResourceDescription); err != nil {
+	if len(rule.MatchResources.Any) > 0 || len(rule.MatchResources.All) > 0 {
+		if path, err := validateResourceFilters("match", rule.MatchResources.Any, rule.MatchResources.All, rule.MatchResources.UserInfo, rule.MatchResources.ResourceDescription); err != nil {
+			return fmt.Sprintf("resources.%s", path), err
+		}
+	} else if path, err := validateMatchedResourceDescription(rule.MatchResources.ResourceDescription); err != nil {
 		return fmt.Sprintf("resources.%s", path), err
 	}
 	// exclude resources
-	if path, err := validateExcludeResourceDescription(rule.ExcludeResources.ResourceDescription); err != nil {
+	if len(rule.ExcludeResources.Any) > 0 || len(rule.ExcludeResources.All) > 0 {
+		if path, err := validateResourceFilters("exclude", rule.ExcludeResources.Any, rule.ExcludeResources.All, rule.ExcludeResources.UserInfo, rule.ExcludeResources.ResourceDescription); err != nil {
+			return fmt.Sprintf("resources.%s", path), err
+		}
+	} else if path, err := validateExcludeResourceDescription(rule.ExcludeResources.ResourceDescription); err != nil {
 		return fmt.Sprintf("resources.%s", path), err
 	}
 	return "", nil
 }
 
+// validateResourceFilters checks the any and all filters of a match or exclude block
+// Returns error if
+// - any and all are both specified
+// - resources or user information are specified next to any or all
+// - a filter is empty or has an invalid user information or resource description
+func validateResourceFilters(block string, anyFilters, allFilters kyverno.ResourceFilters, userInfo kyverno.UserInfo, rd kyverno.ResourceDescription) (string, error) {
+	if len(anyFilters) > 0 && len(allFilters) > 0 {
+		return block, fmt.Errorf("any and all cannot be specified together")
+	}
+
+	if !reflect.DeepEqual(userInfo, kyverno.UserInfo{}) || !reflect.DeepEqual(rd, kyverno.ResourceDescription{}) {
+		return block, fmt.Errorf("resources and user information cannot be specified together with any or all")
+	}
+
+	filterType, filters := "any", anyFilters
+	if len(allFilters) > 0 {
+		filterType, filters = "all", allFilters
+	}
+
+	for i, filter := range filters {
+		path := fmt.Sprintf("%s.%s[%d]", block, filterType, i)
+		if reflect.DeepEqual(filter, kyverno.ResourceFilter{}) {
+			return path, fmt.Errorf("resource filter cannot be empty")
+		}
+
+		if err := validateRoles(filter.Roles); err != nil {
+			return path + ".roles", err
+		}
+
+		if err := validateSubjects(filter.Subjects); err != nil {
+			return path + ".subjects", err
+		}
+
+		if err := validateResourceDescription(filter.ResourceDescription); err != nil {
+			return path + ".resources", err
+		}
+	}
+
+	return "", nil
+}
+
+// isNamespacedURLPath checks that the API path only reads resources in the namespace,
+// the path must not contain variables or parent references that would resolve to another namespace
+func isNamespacedURLPath(urlPath, namespace string) bool {
+	if strings.Contains(urlPath, "{{") || strings.Contains(urlPath, "..") {
+		return false
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path.Clean(urlPath), "/"), "/")
+	switch {
+	case len(segments) >= 4 && segments[0] == "api":
+		// /api/v1/namespaces/<ns>
+		return segments[1] == "v1" && segments[2] == "namespaces" && segments[3] == namespace
+	case len(segments) >= 5 && segments[0] == "apis":
+		// /apis/<group>/<version>/namespaces/<ns>
+		return segments[3] == "namespaces" && segments[4] == namespace
+	default:
+		return false
+	}
+}
+
+// validateNamespacedPolicyRule checks that a rule of a namespaced policy
+// does not select or generate resources outside the policy namespace
+func validateNamespacedPolicyRule(rule kyverno.Rule, namespace string, client *dclient.Client, mock bool) (string, error) {
+	for i, ns := range rule.MatchResources.Namespaces {
+		if ns != namespace {
+			return fmt.Sprintf("match.resources.namespaces[%d]", i), fmt.Errorf("a namespaced policy cannot match resources in namespace '%s'", ns)
+		}
+	}
+
+	for i, ns := range rule.ExcludeResources.Namespaces {
+		if ns != namespace {
+			return fmt.Sprintf("exclude.resources.namespaces[%d]", i), fmt.Errorf("a namespaced policy cannot exclude resources in namespace '%s'", ns)
+		}
+	}
+
+	for _, block := range []struct {
+		name    string
+		filters kyverno.ResourceFilters
+	}{
+		{"match.any", rule.MatchResources.Any},
+		{"match.all", rule.MatchResources.All},
+		{"exclude.any", rule.ExcludeResources.Any},
+		{"exclude.all", rule.ExcludeResources.All},
+	} {
+		for i, filter := range block.filters {
+			for j, ns := range filter.Namespaces {
+				if ns != namespace {
+					return fmt.Sprintf("%s[%d].resources.namespaces[%d]", block.name, i, j), fmt.Errorf("a namespaced policy cannot select resources in namespace '%s'", ns)
+				}
+			}
+		}
+	}
+
+	if rule.HasGenerate() {
+		if rule.Generation.Namespace != namespace {
+			return "generate.namespace", fmt.Errorf("a namespaced policy can only generate resources in namespace '%s'", namespace)
+		}
+		if rule.Generation.Clone.Namespace != "" && rule.Generation.Clone.Namespace != namespace {
+			return "generate.clone.namespace", fmt.Errorf("a namespaced policy can only clone resources from namespace '%s'", namespace)
+		}
+		// the resources cannot be cloned in their own namespace
+		if len(rule.Generation.CloneList.Kinds) != 0 {
+			return "generate.cloneList", fmt.Errorf("a namespaced policy cannot clone a list of resources")
+		}
+	}
+
+	for i, target := range rule.Mutation.Targets {
+		if target.Namespace != "" && target.Namespace != namespace {
+			return fmt.Sprintf("mutate.targets[%d].namespace", i), fmt.Errorf("a namespaced policy can only mutate targets in namespace '%s'", namespace)
+		}
+	}
+
+	for i, entry := range rule.Context {
+		if entry.ConfigMap != nil && entry.ConfigMap.Namespace != namespace {
+			return fmt.Sprintf("context[%d].configMap.namespace", i), fmt.Errorf("a namespaced policy can only load configmaps from namespace '%s'", namespace)
+		}
+
+		if entry.APICall != nil && !isNamespacedURLPath(entry.APICall.URLPath, namespace) {
+			return fmt.Sprintf("context[%d].apiCall.urlPath", i), fmt.Errorf("a namespaced policy can only call APIs in namespace '%s'", namespace)
+		}
+	}
+
+	if mock || client == nil {
+		return "", nil
+	}
+
+	for _, kindSelector := range rule.MatchKinds() {
+		_, _, kind, _ := utils.ParseKindSelector(kindSelector)
+		resourceSchema, _, err := client.DiscoveryClient.FindResource("", kind)
+		if err != nil {
+			continue
+		}
+		if !resourceSchema.Namespaced {
+			return "match.resources.kinds", fmt.Errorf("a namespaced policy cannot match cluster-wide resource kind '%s'", kind)
+		}
+	}
+
+	return "", nil
+}
+
+// validateFailureAction checks that the ValidationFailureAction is enforce or audit
+func validateFailureAction(action string) error {
+	if action != "enforce" && action != "audit" {
+		return fmt.Errorf("invalid validationFailureAction '%s', supported values are enforce and audit", action)
+	}
+	return nil
+}
+
+// validateMutateExistingAction checks that the existing resources are only mutated
+// by policies with mutate rules that are processed in the background
+func validateMutateExistingAction(p kyverno.ClusterPolicy) error {
+	action := p.Spec.MutateExistingAction
+	if action == "" {
+		return nil
+	}
+
+	if action != kyverno.MutateExistingApply && action != kyverno.MutateExistingDryRun {
+		return fmt.Errorf("invalid mutateExistingAction '%s', supported values are %s and %s", action, kyverno.MutateExistingApply, kyverno.MutateExistingDryRun)
+	}
+
+	if !p.BackgroundProcessingEnabled() {
+		return fmt.Errorf("existing resources can only be mutated in background mode, set spec.background=true")
+	}
+
+	for _, rule := range p.Spec.Rules {
+		if rule.HasMutate() {
+			return nil
+		}
+	}
+	return fmt.Errorf("the policy has no mutate rules")
+}
+
+// validateGenerateExisting checks that generate requests are only created for existing
+// resources by policies with generate rules that are processed in the background
+func validateGenerateExisting(p kyverno.ClusterPolicy) error {
+	if !p.Spec.GenerateExistingOnPolicyUpdate {
+		return nil
+	}
+
+	if !p.BackgroundProcessingEnabled() {
+		return fmt.Errorf("existing resources can only be processed in background mode, set spec.background=true")
+	}
+
+	if !p.HasGenerate() {
+		return fmt.Errorf("the policy has no generate rules")
+	}
+	return nil
+}
+
+// validateFailureActionOverrides checks that the overrides have a valid action and select namespaces
+func validateFailureActionOverrides(overrides []kyverno.ValidationFailureActionOverride) (string, error) {
+	for i, override := range overrides {
+		if err := validateFailureAction(override.Action); err != nil {
+			return fmt.Sprintf("validationFailureActionOverrides[%d].action", i), err
+		}
+
+		if len(override.Namespaces) == 0 && override.NamespaceSelector == nil {
+			return fmt.Sprintf("validationFailureActionOverrides[%d]", i), errors.New("namespaces or namespaceSelector is required")
+		}
+
+		if override.NamespaceSelector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(override.NamespaceSelector); err != nil {
+				return fmt.Sprintf("validationFailureActionOverrides[%d].namespaceSelector", i), err
+			}
+		}
+	}
+	return "", nil
+}
+
+// validateRuleContext checks the context entries of a rule
+func validateRuleContext(rule kyverno.Rule) (string, error) {
+	reservedNames := []string{"request", "serviceAccountName", "serviceAccountNamespace", "element", "elementIndex"}
+	for i, entry := range rule.Context {
+		if entry.Name == "" {
+			return fmt.Sprintf("context[%d].name", i), fmt.Errorf("a name is required for context entries")
+		}
+
+		if containString(reservedNames, entry.Name) {
+			return fmt.Sprintf("context[%d].name", i), fmt.Errorf("entry name '%s' is reserved", entry.Name)
+		}
+
+		if (entry.ConfigMap == nil) == (entry.APICall == nil) {
+			return fmt.Sprintf("context[%d]", i), fmt.Errorf("either a configMap or an apiCall is required for context entries")
+		}
+
+		if entry.ConfigMap != nil && entry.ConfigMap.Name == "" {
+			return fmt.Sprintf("context[%d].configMap.name", i), fmt.Errorf("a name is required for configMap context entries")
+		}
+
+		if entry.APICall != nil {
+			if entry.APICall.URLPath == "" {
+				return fmt.Sprintf("context[%d].apiCall.urlPath", i), fmt.Errorf("a urlPath is required for apiCall context entries")
+			}
+
+			if entry.APICall.JMESPath != "" && !variables.IsVariable(entry.APICall.JMESPath) {
+				if _, err := jmespath.New(entry.APICall.JMESPath); err != nil {
+					return fmt.Sprintf("context[%d].apiCall.jmesPath", i), fmt.Errorf("invalid jmesPath %s: %v", entry.APICall.JMESPath, err)
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// validateRuleConditions checks the preconditions and deny conditions of a rule
+func validateRuleConditions(rule kyverno.Rule) (string, error) {
+	if path, err := validateConditions(rule.Conditions, "preconditions"); err != nil {
+		return path, err
+	}
+
+	if rule.Validation.Deny != nil {
+		if path, err := validateConditions(rule.Validation.Deny.Conditions, "validate.deny.conditions"); err != nil {
+			return path, err
+		}
+	}
+
+	if foreach := rule.Validation.ForEachValidation; foreach != nil {
+		if path, err := validateConditions(foreach.Preconditions, "validate.foreach.preconditions"); err != nil {
+			return path, err
+		}
+
+		if foreach.Deny != nil {
+			if path, err := validateConditions(foreach.Deny.Conditions, "validate.foreach.deny.conditions"); err != nil {
+				return path, err
+			}
+		}
+	}
+	return "", nil
+}
+
+// validateConditions checks that each condition either has an operator or is a group of any and all conditions
+func validateConditions(conditions []kyverno.Condition, path string) (string, error) {
+	for i, condition := range conditions {
+		conditionPath := fmt.Sprintf("%s[%d]", path, i)
+		if !condition.HasConditionGroups() {
+			if condition.Operator == "" {
+				return conditionPath, fmt.Errorf("a condition requires an operator, or any or all conditions")
+			}
+			continue
+		}
+
+		if condition.Key != nil || condition.Operator != "" || condition.Value != nil {
+			return conditionPath, fmt.Errorf("key, operator and value cannot be specified together with any or all")
+		}
+
+		if path, err := validateConditions(condition.AnyConditions, conditionPath+".any"); err != nil {
+			return path, err
+		}
+
+		if path, err := validateConditions(condition.AllConditions, conditionPath+".all"); err != nil {
+			return path, err
+		}
+	}
+	return "", nil
+}
+
 // ValidateUniqueRuleName checks if the rule names are unique across a policy
 func validateUniqueRuleName(p kyverno.ClusterPolicy) (string, error) {
 	var ruleNames []string
@@ -386,9 +827,23 @@ func validateExcludeResourceDescription(rd kyverno.ResourceDescription) (string,
 	return "", nil
 }
 
-// validateResourceDescription returns error if selector is invalid
+// validateResourceDescription returns error if selector or operations are invalid
 // field type is checked through openapi
 func validateResourceDescription(rd kyverno.ResourceDescription) error {
+	for _, kindSelector := range rd.Kinds {
+		if _, _, kind, _ := utils.ParseKindSelector(kindSelector); kind == "" {
+			return fmt.Errorf("invalid kind '%s', supported formats are Kind, version/Kind and group/version/Kind with an optional /subresource", kindSelector)
+		}
+	}
+
+	for _, op := range rd.Operations {
+		switch op {
+		case kyverno.Create, kyverno.Update, kyverno.Delete, kyverno.Connect:
+		default:
+			return fmt.Errorf("invalid operation '%s', supported operations are CREATE, UPDATE, DELETE and CONNECT", op)
+		}
+	}
+
 	if rd.Selector != nil {
 		selector, err := metav1.LabelSelectorAsSelector(rd.Selector)
 		if err != nil {
@@ -399,5 +854,16 @@ func validateResourceDescription(rd kyverno.ResourceDescription) error {
 			return errors.New("the requirements are not specified in selector")
 		}
 	}
+
+	if rd.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(rd.NamespaceSelector)
+		if err != nil {
+			return err
+		}
+		requirements, _ := selector.Requirements()
+		if len(requirements) == 0 {
+			return errors.New("the requirements are not specified in namespaceSelector")
+		}
+	}
 	return nil
 }
@@ -2,9 +2,12 @@ package validate
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	"github.com/nirmata/kyverno/pkg/engine/anchor"
+	enginevalidate "github.com/nirmata/kyverno/pkg/engine/validate"
 	"github.com/nirmata/kyverno/pkg/policy/common"
 )
 
@@ -31,26 +34,111 @@ func (v *Validate) Validate() (string, error) {
 	}
 
 	if rule.Pattern != nil {
-		if path, err := common.ValidatePattern(rule.Pattern, "/", []anchor.IsAnchor{anchor.IsConditionAnchor, anchor.IsExistenceAnchor, anchor.IsEqualityAnchor, anchor.IsNegationAnchor}); err != nil {
+		if path, err := validatePattern(rule.Pattern); err != nil {
 			return fmt.Sprintf("pattern.%s", path), err
 		}
 	}
 
 	if len(rule.AnyPattern) != 0 {
 		for i, pattern := range rule.AnyPattern {
-			if path, err := common.ValidatePattern(pattern, "/", []anchor.IsAnchor{anchor.IsConditionAnchor, anchor.IsExistenceAnchor, anchor.IsEqualityAnchor, anchor.IsNegationAnchor}); err != nil {
+			if path, err := validatePattern(pattern); err != nil {
 				return fmt.Sprintf("anyPattern[%d].%s", i, path), err
 			}
 		}
 	}
+
+	if rule.ForEachValidation != nil {
+		if path, err := validateForEach(rule.ForEachValidation); err != nil {
+			return fmt.Sprintf("foreach.%s", path), err
+		}
+	}
+
+	for i, path := range rule.Immutable {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Sprintf("immutable[%d]", i), fmt.Errorf("immutable path %s must start with '/'", path)
+		}
+	}
+	return "", nil
+}
+
+// validateForEach checks the list and the validation applied to each element
+func validateForEach(foreach *kyverno.ForEachValidation) (string, error) {
+	if foreach.List == "" {
+		return "list", fmt.Errorf("a list is required")
+	}
+
+	if foreach.Pattern == nil && len(foreach.AnyPattern) == 0 && foreach.Deny == nil {
+		return "", fmt.Errorf("pattern, anyPattern or deny must be specified")
+	}
+
+	if (foreach.Pattern != nil && len(foreach.AnyPattern) != 0) || (foreach.Pattern != nil && foreach.Deny != nil) || (len(foreach.AnyPattern) != 0 && foreach.Deny != nil) {
+		return "", fmt.Errorf("only one operation allowed per foreach validation(pattern, anyPattern or deny)")
+	}
+
+	if foreach.Pattern != nil {
+		if path, err := validatePattern(foreach.Pattern); err != nil {
+			return fmt.Sprintf("pattern.%s", path), err
+		}
+	}
+
+	for i, pattern := range foreach.AnyPattern {
+		if path, err := validatePattern(pattern); err != nil {
+			return fmt.Sprintf("anyPattern[%d].%s", i, path), err
+		}
+	}
+	return "", nil
+}
+
+// validatePattern checks the anchors of a validation pattern, and the regular expressions and ranges of its values
+func validatePattern(pattern interface{}) (string, error) {
+	if path, err := common.ValidatePattern(pattern, "/", []anchor.IsAnchor{anchor.IsConditionAnchor, anchor.IsExistenceAnchor, anchor.IsEqualityAnchor, anchor.IsNegationAnchor, anchor.IsGlobalAnchor}); err != nil {
+		return path, err
+	}
+
+	return validatePatternValues(pattern, "/")
+}
+
+func validatePatternValues(patternElement interface{}, path string) (string, error) {
+	switch typedPatternElement := patternElement.(type) {
+	case map[string]interface{}:
+		for key, value := range typedPatternElement {
+			if errPath, err := validatePatternValues(value, path+key+"/"); err != nil {
+				return errPath, err
+			}
+		}
+	case []interface{}:
+		for i, value := range typedPatternElement {
+			if errPath, err := validatePatternValues(value, path+strconv.Itoa(i)+"/"); err != nil {
+				return errPath, err
+			}
+		}
+	case string:
+		if err := enginevalidate.CheckStringPattern(typedPatternElement); err != nil {
+			return path, err
+		}
+	}
 	return "", nil
 }
 
-// validateOverlayPattern checks one of pattern/anyPattern must exist
+// validateOverlayPattern checks one of pattern/anyPattern/deny/foreach/immutable must exist
 func (v *Validate) validateOverlayPattern() error {
 	rule := v.rule
+	if len(rule.Immutable) != 0 {
+		if rule.Pattern != nil || len(rule.AnyPattern) != 0 || rule.Deny != nil || rule.ForEachValidation != nil {
+			return fmt.Errorf("immutable cannot be combined with pattern, anyPattern, deny or foreach")
+		}
+		return nil
+	}
+
+	if rule.ForEachValidation != nil {
+		if rule.Pattern != nil || len(rule.AnyPattern) != 0 || rule.Deny != nil {
+			return fmt.Errorf("foreach cannot be combined with pattern, anyPattern or deny")
+		}
+		return nil
+	}
+
 	if rule.Pattern == nil && len(rule.AnyPattern) == 0 && rule.Deny == nil {
-		return fmt.Errorf("pattern, anyPattern or deny must be specified")
+		return fmt.Errorf("pattern, anyPattern, deny, foreach or immutable must be specified")
 	}
 
 	if rule.Pattern != nil && len(rule.AnyPattern) != 0 {
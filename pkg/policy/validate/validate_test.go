@@ -379,3 +379,140 @@ func Test_Validate_Validate_Unsupported(t *testing.T) {
 	}
 
 }
+
+func TestValidateForEach(t *testing.T) {
+	testcases := []struct {
+		rawValidate []byte
+		valid       bool
+	}{
+		{
+			rawValidate: []byte(`{"foreach": {"list": "request.object.spec.containers", "pattern": {"image": "gcr.io/*"}}}`),
+			valid:       true,
+		},
+		{
+			rawValidate: []byte(`{"foreach": {"list": "request.object.spec.containers", "deny": {"conditions": [{"key": "{{element.name}}", "operator": "Equals", "value": "nginx"}]}}}`),
+			valid:       true,
+		},
+		{
+			rawValidate: []byte(`{"foreach": {"pattern": {"image": "gcr.io/*"}}}`),
+			valid:       false,
+		},
+		{
+			rawValidate: []byte(`{"foreach": {"list": "request.object.spec.containers"}}`),
+			valid:       false,
+		},
+		{
+			rawValidate: []byte(`{"foreach": {"list": "request.object.spec.containers", "pattern": {"image": "gcr.io/*"}, "deny": {}}}`),
+			valid:       false,
+		},
+		{
+			rawValidate: []byte(`{"pattern": {"metadata": {"name": "*"}}, "foreach": {"list": "request.object.spec.containers", "pattern": {"image": "gcr.io/*"}}}`),
+			valid:       false,
+		},
+	}
+
+	for i, tc := range testcases {
+		var validate kyverno.Validation
+		assert.NilError(t, json.Unmarshal(tc.rawValidate, &validate))
+		_, err := NewValidateFactory(validate).Validate()
+		assert.Equal(t, err == nil, tc.valid, "testcase %d", i)
+	}
+}
+
+func TestValidateImmutable(t *testing.T) {
+	testcases := []struct {
+		rawValidate []byte
+		valid       bool
+	}{
+		{
+			rawValidate: []byte(`{"immutable": ["/spec/selector", "/spec/containers/*/image"]}`),
+			valid:       true,
+		},
+		{
+			rawValidate: []byte(`{"immutable": ["spec/selector"]}`),
+			valid:       false,
+		},
+		{
+			rawValidate: []byte(`{"pattern": {"metadata": {"name": "*"}}, "immutable": ["/spec/selector"]}`),
+			valid:       false,
+		},
+	}
+
+	for i, tc := range testcases {
+		var validate kyverno.Validation
+		assert.NilError(t, json.Unmarshal(tc.rawValidate, &validate))
+		_, err := NewValidateFactory(validate).Validate()
+		assert.Equal(t, err == nil, tc.valid, "testcase %d", i)
+	}
+}
+
+func Test_Validate_GlobalAnchor(t *testing.T) {
+	rawValidation := []byte(`
+	{
+		"pattern": {
+			"spec": {
+				"containers": [
+					{
+						"<(image)": "*:latest",
+						"imagePullPolicy": "Always"
+					}
+				]
+			}
+		}
+	}`)
+
+	var validation kyverno.Validation
+	err := json.Unmarshal(rawValidation, &validation)
+	assert.NilError(t, err)
+
+	_, err = NewValidateFactory(validation).Validate()
+	assert.NilError(t, err)
+
+	rawValidation = []byte(`
+	{
+		"pattern": {
+			"spec": {
+				"<(securityContext)": {
+					"<(runAsNonRoot)": true
+				}
+			}
+		}
+	}`)
+
+	err = json.Unmarshal(rawValidation, &validation)
+	assert.NilError(t, err)
+
+	_, err = NewValidateFactory(validation).Validate()
+	assert.Assert(t, err != nil)
+}
+
+func Test_Validate_PatternValues(t *testing.T) {
+	testcases := []struct {
+		pattern string
+		path    string
+		err     string
+	}{
+		{pattern: `{"spec": {"replicas": "range:1-10", "name": "regex:^[a-z]+$"}}`},
+		{pattern: `{"spec": {"replicas": "range:10-1"}}`, path: "pattern./spec/replicas/", err: "greater than the maximum"},
+		{pattern: `{"spec": {"containers": [{"name": "!regex:^(abc"}]}}`, path: "pattern./spec/containers/0/name/", err: "invalid regular expression"},
+	}
+
+	for _, tc := range testcases {
+		var validation kyverno.Validation
+		assert.NilError(t, json.Unmarshal([]byte(`{"pattern": `+tc.pattern+`}`), &validation))
+
+		path, err := NewValidateFactory(validation).Validate()
+		if tc.err == "" {
+			assert.NilError(t, err)
+			continue
+		}
+		assert.ErrorContains(t, err, tc.err)
+		assert.Equal(t, path, tc.path)
+	}
+
+	var validation kyverno.Validation
+	assert.NilError(t, json.Unmarshal([]byte(`{"foreach": {"list": "request.object.spec.containers", "anyPattern": [{"image": "range:2-1"}]}}`), &validation))
+	path, err := NewValidateFactory(validation).Validate()
+	assert.ErrorContains(t, err, "greater than the maximum")
+	assert.Equal(t, path, "foreach.anyPattern[0]./image/")
+}
@@ -8,6 +8,7 @@ import (
 
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func Test_Validate_UniqueRuleName(t *testing.T) {
@@ -270,6 +271,135 @@ func Test_Validate_ResourceDescription_MissingKindsOnExclude(t *testing.T) {
 	assert.NilError(t, err)
 }
 
+func Test_Validate_ResourceDescription_InvalidOperation(t *testing.T) {
+	rd := kyverno.ResourceDescription{
+		Kinds:      []string{"Namespace"},
+		Operations: []kyverno.AdmissionOperation{kyverno.Delete, "PATCH"},
+	}
+	_, err := validateMatchedResourceDescription(rd)
+	assert.Assert(t, err != nil)
+
+	rd.Operations = []kyverno.AdmissionOperation{kyverno.Delete, kyverno.Connect}
+	_, err = validateMatchedResourceDescription(rd)
+	assert.NilError(t, err)
+}
+
+func Test_Validate_ValidationFailureActionOverrides(t *testing.T) {
+	testcases := []struct {
+		overrides []kyverno.ValidationFailureActionOverride
+		valid     bool
+	}{
+		{
+			overrides: []kyverno.ValidationFailureActionOverride{
+				{Action: "enforce", Namespaces: []string{"prod-*"}},
+				{Action: "audit", NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}}},
+			},
+			valid: true,
+		},
+		{
+			overrides: []kyverno.ValidationFailureActionOverride{{Action: "block", Namespaces: []string{"prod"}}},
+			valid:     false,
+		},
+		{
+			overrides: []kyverno.ValidationFailureActionOverride{{Action: "enforce"}},
+			valid:     false,
+		},
+	}
+
+	for i, tc := range testcases {
+		_, err := validateFailureActionOverrides(tc.overrides)
+		assert.Equal(t, err == nil, tc.valid, "testcase %d", i)
+	}
+}
+
+func Test_Validate_MutateExistingAction(t *testing.T) {
+	testcases := []struct {
+		spec  []byte
+		valid bool
+	}{
+		{spec: []byte(`{"rules":[{"name":"add-label","mutate":{"overlay":{"metadata":{"labels":{"team":"dev"}}}}}]}`), valid: true},
+		{spec: []byte(`{"mutateExistingAction":"apply","rules":[{"name":"add-label","mutate":{"overlay":{"metadata":{"labels":{"team":"dev"}}}}}]}`), valid: true},
+		{spec: []byte(`{"mutateExistingAction":"dryRun","rules":[{"name":"add-label","mutate":{"overlay":{"metadata":{"labels":{"team":"dev"}}}}}]}`), valid: true},
+		{spec: []byte(`{"mutateExistingAction":"patch","rules":[{"name":"add-label","mutate":{"overlay":{"metadata":{"labels":{"team":"dev"}}}}}]}`), valid: false},
+		{spec: []byte(`{"mutateExistingAction":"apply","background":false,"rules":[{"name":"add-label","mutate":{"overlay":{"metadata":{"labels":{"team":"dev"}}}}}]}`), valid: false},
+		{spec: []byte(`{"mutateExistingAction":"apply","rules":[{"name":"require-label","validate":{"pattern":{"metadata":{"labels":{"team":"?*"}}}}}]}`), valid: false},
+	}
+
+	for i, tc := range testcases {
+		var policy kyverno.ClusterPolicy
+		err := json.Unmarshal(tc.spec, &policy.Spec)
+		assert.NilError(t, err)
+
+		err = validateMutateExistingAction(policy)
+		assert.Equal(t, err == nil, tc.valid, "testcase %d", i)
+	}
+}
+
+func Test_Validate_GenerateExisting(t *testing.T) {
+	testcases := []struct {
+		spec  []byte
+		valid bool
+	}{
+		{spec: []byte(`{"generateExistingOnPolicyUpdate":true,"rules":[{"name":"default-netpol","generate":{"kind":"NetworkPolicy","name":"default-deny","namespace":"{{request.object.metadata.name}}","data":{"spec":{"podSelector":{}}}}}]}`), valid: true},
+		{spec: []byte(`{"generateExistingOnPolicyUpdate":true,"background":false,"rules":[{"name":"default-netpol","generate":{"kind":"NetworkPolicy","name":"default-deny","namespace":"{{request.object.metadata.name}}","data":{"spec":{"podSelector":{}}}}}]}`), valid: false},
+		{spec: []byte(`{"generateExistingOnPolicyUpdate":true,"rules":[{"name":"add-label","mutate":{"overlay":{"metadata":{"labels":{"team":"dev"}}}}}]}`), valid: false},
+		{spec: []byte(`{"rules":[{"name":"add-label","mutate":{"overlay":{"metadata":{"labels":{"team":"dev"}}}}}]}`), valid: true},
+	}
+
+	for i, tc := range testcases {
+		var policy kyverno.ClusterPolicy
+		err := json.Unmarshal(tc.spec, &policy.Spec)
+		assert.NilError(t, err)
+
+		err = validateGenerateExisting(policy)
+		assert.Equal(t, err == nil, tc.valid, "testcase %d", i)
+	}
+}
+
+func Test_Validate_ResourceFilters(t *testing.T) {
+	testcases := []struct {
+		match []byte
+		valid bool
+	}{
+		{match: []byte(`{"any":[{"resources":{"kinds":["Deployment"],"namespaces":["prod"]}},{"resources":{"kinds":["StatefulSet"]}}]}`), valid: true},
+		{match: []byte(`{"all":[{"resources":{"kinds":["Deployment"]}},{"subjects":[{"kind":"User","name":"dev"}]}]}`), valid: true},
+		{match: []byte(`{"any":[{"resources":{"kinds":["Deployment"]}}],"all":[{"resources":{"kinds":["StatefulSet"]}}]}`), valid: false},
+		{match: []byte(`{"any":[{"resources":{"kinds":["Deployment"]}}],"resources":{"kinds":["StatefulSet"]}}`), valid: false},
+		{match: []byte(`{"any":[{}]}`), valid: false},
+		{match: []byte(`{"any":[{"resources":{"kinds":["Deployment"],"operations":["PATCH"]}}]}`), valid: false},
+	}
+
+	for i, tc := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(tc.match, &rule.MatchResources)
+		assert.NilError(t, err)
+
+		_, err = validateResources(rule)
+		assert.Equal(t, err == nil, tc.valid, "testcase %d: %v", i, err)
+	}
+}
+
+func Test_Validate_Conditions(t *testing.T) {
+	testcases := []struct {
+		conditions []byte
+		valid      bool
+	}{
+		{conditions: []byte(`[{"key":"{{request.operation}}","operator":"Equals","value":"DELETE","message":"deletes are not allowed"}]`), valid: true},
+		{conditions: []byte(`[{"any":[{"key":"a","operator":"Equals","value":"a"},{"all":[{"key":"b","operator":"Equals","value":"b"}]}]}]`), valid: true},
+		{conditions: []byte(`[{"key":"a","value":"a"}]`), valid: false},
+		{conditions: []byte(`[{"key":"a","operator":"Equals","value":"a","any":[{"key":"b","operator":"Equals","value":"b"}]}]`), valid: false},
+		{conditions: []byte(`[{"all":[{"any":[{"key":"a"}]}]}]`), valid: false},
+	}
+
+	for i, tc := range testcases {
+		var conditions []kyverno.Condition
+		assert.NilError(t, json.Unmarshal(tc.conditions, &conditions))
+
+		_, err := validateConditions(conditions, "preconditions")
+		assert.Equal(t, err == nil, tc.valid, "testcase %d: %v", i, err)
+	}
+}
+
 func Test_Validate_ResourceDescription_InvalidSelector(t *testing.T) {
 	rawResourcedescirption := []byte(`
 	{
@@ -1027,6 +1157,51 @@ func Test_doesMatchExcludeConflict(t *testing.T) {
 			rule:           []byte(`{"name":"check-allow-deletes","match":{"resources":{"selector":{"matchLabels":{"allow-deletes":"false"}}}},"exclude":{"clusterRoles":["random"]},"validate":{"message":"Deleting {{request.object.kind}}/{{request.object.metadata.name}} is not allowed","deny":{"conditions":[{"key":"{{request.operation}}","operator":"Equal","value":"DELETE"}]}}}`),
 			expectedOutput: false,
 		},
+		{
+			description:    "same operations",
+			rule:           []byte(`{"name":"block-deletes","match":{"resources":{"kinds":["Namespace"],"operations":["DELETE"]}},"exclude":{"resources":{"kinds":["Namespace"],"operations":["DELETE","UPDATE"]}}}`),
+			expectedOutput: true,
+		},
+		{
+			description:    "Failed to exclude operations",
+			rule:           []byte(`{"name":"block-deletes","match":{"resources":{"kinds":["Namespace"],"operations":["DELETE","UPDATE"]}},"exclude":{"resources":{"kinds":["Namespace"],"operations":["DELETE"]}}}`),
+			expectedOutput: false,
+		},
+		{
+			description:    "same namespace selector",
+			rule:           []byte(`{"name":"require-labels","match":{"resources":{"kinds":["Pod"],"namespaceSelector":{"matchLabels":{"env":"prod"}}}},"exclude":{"resources":{"kinds":["Pod"],"namespaceSelector":{"matchLabels":{"env":"prod"}}}}}`),
+			expectedOutput: true,
+		},
+		{
+			description:    "Failed to exclude namespace selector",
+			rule:           []byte(`{"name":"require-labels","match":{"resources":{"kinds":["Pod"]}},"exclude":{"resources":{"kinds":["Pod"],"namespaceSelector":{"matchLabels":{"env":"prod"}}}}}`),
+			expectedOutput: false,
+		},
+		{
+			description:    "Failed to exclude annotations",
+			rule:           []byte(`{"name":"require-labels","match":{"resources":{"kinds":["Pod"],"annotations":{"owner":"team-a"}}},"exclude":{"resources":{"kinds":["Pod"],"annotations":{"owner":"team-b"}}}}`),
+			expectedOutput: false,
+		},
+		{
+			description:    "every match any filter is excluded",
+			rule:           []byte(`{"name":"require-labels","match":{"any":[{"resources":{"kinds":["Deployment"],"namespaces":["prod"]}},{"resources":{"kinds":["StatefulSet"]}}]},"exclude":{"any":[{"resources":{"kinds":["Deployment"]}},{"resources":{"kinds":["StatefulSet"]}}]}}`),
+			expectedOutput: true,
+		},
+		{
+			description:    "Failed to exclude a match any filter",
+			rule:           []byte(`{"name":"require-labels","match":{"any":[{"resources":{"kinds":["Deployment"],"namespaces":["prod"]}},{"resources":{"kinds":["StatefulSet"]}}]},"exclude":{"any":[{"resources":{"kinds":["Deployment"]}}]}}`),
+			expectedOutput: false,
+		},
+		{
+			description:    "a match all filter is excluded",
+			rule:           []byte(`{"name":"require-labels","match":{"all":[{"resources":{"kinds":["Deployment"]}},{"resources":{"namespaces":["prod"]}}]},"exclude":{"resources":{"kinds":["Deployment"]}}}`),
+			expectedOutput: true,
+		},
+		{
+			description:    "Failed to exclude with exclude all",
+			rule:           []byte(`{"name":"require-labels","match":{"resources":{"kinds":["Deployment"]}},"exclude":{"all":[{"resources":{"kinds":["Deployment"]}},{"resources":{"namespaces":["prod"]}}]}}`),
+			expectedOutput: false,
+		},
 	}
 
 	for i, testcase := range testcases {
@@ -1038,3 +1213,186 @@ func Test_doesMatchExcludeConflict(t *testing.T) {
 		}
 	}
 }
+
+func Test_validateNamespacedPolicyRule(t *testing.T) {
+	testcases := []struct {
+		description string
+		rule        []byte
+		expectedErr bool
+	}{
+		{
+			description: "Match policy namespace",
+			rule:        []byte(`{"name":"require-labels","match":{"resources":{"kinds":["Pod"],"namespaces":["test"]}},"validate":{"pattern":{"metadata":{"labels":{"app":"?*"}}}}}`),
+			expectedErr: false,
+		},
+		{
+			description: "Match other namespace",
+			rule:        []byte(`{"name":"require-labels","match":{"resources":{"kinds":["Pod"],"namespaces":["default"]}},"validate":{"pattern":{"metadata":{"labels":{"app":"?*"}}}}}`),
+			expectedErr: true,
+		},
+		{
+			description: "Exclude other namespace",
+			rule:        []byte(`{"name":"require-labels","match":{"resources":{"kinds":["Pod"]}},"exclude":{"resources":{"namespaces":["default"]}},"validate":{"pattern":{"metadata":{"labels":{"app":"?*"}}}}}`),
+			expectedErr: true,
+		},
+		{
+			description: "Generate in other namespace",
+			rule:        []byte(`{"name":"generate-cm","match":{"resources":{"kinds":["Secret"]}},"generate":{"kind":"ConfigMap","name":"cm","namespace":"default","data":{"data":{"a":"b"}}}}`),
+			expectedErr: true,
+		},
+		{
+			description: "Clone from other namespace",
+			rule:        []byte(`{"name":"clone-cm","match":{"resources":{"kinds":["Secret"]}},"generate":{"kind":"ConfigMap","name":"cm","namespace":"test","clone":{"namespace":"default","name":"cm"}}}`),
+			expectedErr: true,
+		},
+		{
+			description: "Generate in policy namespace",
+			rule:        []byte(`{"name":"clone-cm","match":{"resources":{"kinds":["Secret"]}},"generate":{"kind":"ConfigMap","name":"cm","namespace":"test","clone":{"namespace":"test","name":"cm"}}}`),
+			expectedErr: false,
+		},
+		{
+			description: "Clone list",
+			rule:        []byte(`{"name":"clone-cms","match":{"resources":{"kinds":["Secret"]}},"generate":{"namespace":"test","cloneList":{"namespace":"test","kinds":["ConfigMap"]}}}`),
+			expectedErr: true,
+		},
+		{
+			description: "API call in policy namespace",
+			rule:        []byte(`{"name":"count-pods","match":{"resources":{"kinds":["Pod"]}},"context":[{"name":"pods","apiCall":{"urlPath":"/api/v1/namespaces/test/pods"}}],"validate":{"deny":{}}}`),
+			expectedErr: false,
+		},
+		{
+			description: "API call to a group in policy namespace",
+			rule:        []byte(`{"name":"count-deployments","match":{"resources":{"kinds":["Pod"]}},"context":[{"name":"deployments","apiCall":{"urlPath":"/apis/apps/v1/namespaces/test/deployments"}}],"validate":{"deny":{}}}`),
+			expectedErr: false,
+		},
+		{
+			description: "API call in other namespace",
+			rule:        []byte(`{"name":"count-pods","match":{"resources":{"kinds":["Pod"]}},"context":[{"name":"pods","apiCall":{"urlPath":"/api/v1/namespaces/default/pods"}}],"validate":{"deny":{}}}`),
+			expectedErr: true,
+		},
+		{
+			description: "API call with path traversal",
+			rule:        []byte(`{"name":"read-secrets","match":{"resources":{"kinds":["Pod"]}},"context":[{"name":"secrets","apiCall":{"urlPath":"/api/v1/namespaces/test/../kube-system/secrets"}}],"validate":{"deny":{}}}`),
+			expectedErr: true,
+		},
+		{
+			description: "API call with policy namespace in a sub path",
+			rule:        []byte(`{"name":"read-secrets","match":{"resources":{"kinds":["Pod"]}},"context":[{"name":"secrets","apiCall":{"urlPath":"/api/v1/secrets/namespaces/test/"}}],"validate":{"deny":{}}}`),
+			expectedErr: true,
+		},
+		{
+			description: "API call with variable namespace",
+			rule:        []byte(`{"name":"read-secrets","match":{"resources":{"kinds":["Pod"]}},"context":[{"name":"secrets","apiCall":{"urlPath":"/api/v1/namespaces/{{request.object.metadata.labels.ns}}/secrets"}}],"validate":{"deny":{}}}`),
+			expectedErr: true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		var rule kyverno.Rule
+		err := json.Unmarshal(testcase.rule, &rule)
+		assert.NilError(t, err)
+
+		_, err = validateNamespacedPolicyRule(rule, "test", nil, true)
+		if (err != nil) != testcase.expectedErr {
+			t.Errorf("Testcase [%s] failed, error: %v", testcase.description, err)
+		}
+	}
+}
+
+func Test_Validate_RuleContext(t *testing.T) {
+	testcases := []struct {
+		context []kyverno.ContextEntry
+		valid   bool
+	}{
+		{
+			context: []kyverno.ContextEntry{{Name: "teamconfig", ConfigMap: &kyverno.ConfigMapReference{Name: "team-config", Namespace: "default"}}},
+			valid:   true,
+		},
+		{
+			context: []kyverno.ContextEntry{{ConfigMap: &kyverno.ConfigMapReference{Name: "team-config"}}},
+			valid:   false,
+		},
+		{
+			context: []kyverno.ContextEntry{{Name: "request", ConfigMap: &kyverno.ConfigMapReference{Name: "team-config"}}},
+			valid:   false,
+		},
+		{
+			context: []kyverno.ContextEntry{{Name: "teamconfig"}},
+			valid:   false,
+		},
+		{
+			context: []kyverno.ContextEntry{{Name: "teamconfig", ConfigMap: &kyverno.ConfigMapReference{Namespace: "default"}}},
+			valid:   false,
+		},
+		{
+			context: []kyverno.ContextEntry{{Name: "lbcount", APICall: &kyverno.APICall{URLPath: "/api/v1/services", JMESPath: "items | length(@)"}}},
+			valid:   true,
+		},
+		{
+			context: []kyverno.ContextEntry{{Name: "lbcount", APICall: &kyverno.APICall{JMESPath: "items | length(@)"}}},
+			valid:   false,
+		},
+		{
+			context: []kyverno.ContextEntry{{Name: "lbcount", APICall: &kyverno.APICall{URLPath: "/api/v1/services", JMESPath: "items[?"}}},
+			valid:   false,
+		},
+		{
+			context: []kyverno.ContextEntry{{Name: "both", ConfigMap: &kyverno.ConfigMapReference{Name: "team-config"}, APICall: &kyverno.APICall{URLPath: "/api/v1/services"}}},
+			valid:   false,
+		},
+	}
+
+	for i, tc := range testcases {
+		_, err := validateRuleContext(kyverno.Rule{Name: "test", Context: tc.context})
+		assert.Equal(t, err == nil, tc.valid, "testcase %d", i)
+	}
+}
+
+func Test_BackgroundMode_RuleContext(t *testing.T) {
+	rawPolicy := []byte(`
+	{
+		"spec": {
+		   "background": true,
+		   "rules": [
+			  {
+				 "name": "check-registries",
+				 "context": [
+					{
+					   "name": "teamconfig",
+					   "configMap": {
+						  "name": "team-config",
+						  "namespace": "default"
+					   }
+					}
+				 ],
+				 "match": {
+					"resources": {
+					   "kinds": [
+						  "Pod"
+					   ]
+					}
+				 },
+				 "validate": {
+					"pattern": {
+					   "spec": {
+						  "containers": [
+							 {
+								"image": "{{teamconfig.data.registry}}/*"
+							 }
+						  ]
+					   }
+					}
+				 }
+			  }
+		   ]
+		}
+	 }
+	`)
+
+	var policy kyverno.ClusterPolicy
+	err := json.Unmarshal(rawPolicy, &policy)
+	assert.NilError(t, err)
+
+	err = ContainsVariablesOtherThanObject(policy)
+	assert.NilError(t, err)
+}
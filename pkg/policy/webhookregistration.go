@@ -14,7 +14,13 @@ func (pc *PolicyController) removeResourceWebhookConfiguration() error {
 		return err
 	}
 
-	if len(policies) == 0 {
+	nsPolicies, err := pc.npLister.List(labels.NewSelector())
+	if err != nil {
+		logger.Error(err, "failed to list namespaced policies")
+		return err
+	}
+
+	if len(policies) == 0 && len(nsPolicies) == 0 {
 		logger.V(4).Info("no policies loaded, removing resource webhook configuration if one exists")
 		pc.resourceWebhookWatcher.RemoveResourceWebhookConfiguration()
 	}
@@ -7,13 +7,15 @@ import (
 
 	"github.com/go-logr/logr"
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/metrics"
 )
 
 type pMap struct {
 	sync.RWMutex
 	dataMap map[PolicyType][]*kyverno.ClusterPolicy
 
-	// nameCacheMap stores the names of all existing policies in dataMap
+	// nameCacheMap stores the keys of all existing policies in dataMap,
+	// the key is namespace/name for namespaced policies
 	nameCacheMap map[PolicyType]map[string]bool
 }
 
@@ -27,7 +29,8 @@ type policyCache struct {
 type Interface interface {
 	Add(policy *kyverno.ClusterPolicy)
 	Remove(policy *kyverno.ClusterPolicy)
-	Get(pkey PolicyType) []*kyverno.ClusterPolicy
+	Get(pkey PolicyType, nspace string) []*kyverno.ClusterPolicy
+	List(pkey PolicyType) []*kyverno.ClusterPolicy
 }
 
 // newPolicyCache ...
@@ -54,9 +57,15 @@ func (pc *policyCache) Add(policy *kyverno.ClusterPolicy) {
 	pc.Logger.V(4).Info("policy is added to cache", "name", policy.GetName())
 }
 
-// Get the list of matched policies
-func (pc *policyCache) Get(pkey PolicyType) []*kyverno.ClusterPolicy {
-	return pc.pMap.get(pkey)
+// Get the list of matched policies, namespaced policies are
+// only returned for the namespace they are defined in
+func (pc *policyCache) Get(pkey PolicyType, nspace string) []*kyverno.ClusterPolicy {
+	return pc.pMap.get(pkey, nspace)
+}
+
+// List returns all the policies of the type, including the namespaced policies of all namespaces
+func (pc *policyCache) List(pkey PolicyType) []*kyverno.ClusterPolicy {
+	return pc.pMap.list(pkey)
 }
 
 // Remove a policy from cache
@@ -69,13 +78,12 @@ func (m *pMap) add(policy *kyverno.ClusterPolicy) {
 	m.Lock()
 	defer m.Unlock()
 
-	enforcePolicy := policy.Spec.ValidationFailureAction == "enforce"
 	mutateMap := m.nameCacheMap[Mutate]
 	validateEnforceMap := m.nameCacheMap[ValidateEnforce]
 	validateAuditMap := m.nameCacheMap[ValidateAudit]
 	generateMap := m.nameCacheMap[Generate]
 
-	pName := policy.GetName()
+	pName := kyverno.PolicyKey(policy.GetNamespace(), policy.GetName())
 	for _, rule := range policy.Spec.Rules {
 		if rule.HasMutate() {
 			if !mutateMap[pName] {
@@ -88,18 +96,17 @@ func (m *pMap) add(policy *kyverno.ClusterPolicy) {
 		}
 
 		if rule.HasValidate() {
-			if enforcePolicy {
-				if !validateEnforceMap[pName] {
-					validateEnforceMap[pName] = true
+			// a policy is cached for both actions if its rules or namespace overrides use both
+			enforce, audit := validationFailureActions(policy, rule)
+			if enforce && !validateEnforceMap[pName] {
+				validateEnforceMap[pName] = true
 
-					validatePolicy := m.dataMap[ValidateEnforce]
-					m.dataMap[ValidateEnforce] = append(validatePolicy, policy)
-				}
-				continue
+				validatePolicy := m.dataMap[ValidateEnforce]
+				m.dataMap[ValidateEnforce] = append(validatePolicy, policy)
 			}
 
 			// ValidateAudit
-			if !validateAuditMap[pName] {
+			if audit && !validateAuditMap[pName] {
 				validateAuditMap[pName] = true
 
 				validatePolicy := m.dataMap[ValidateAudit]
@@ -123,26 +130,42 @@ func (m *pMap) add(policy *kyverno.ClusterPolicy) {
 	m.nameCacheMap[ValidateEnforce] = validateEnforceMap
 	m.nameCacheMap[ValidateAudit] = validateAuditMap
 	m.nameCacheMap[Generate] = generateMap
+	m.recordSize()
+}
+
+func (m *pMap) get(key PolicyType, nspace string) []*kyverno.ClusterPolicy {
+	m.RLock()
+	defer m.RUnlock()
+
+	var policies []*kyverno.ClusterPolicy
+	for _, p := range m.dataMap[key] {
+		if p.GetNamespace() == "" || p.GetNamespace() == nspace {
+			policies = append(policies, p)
+		}
+	}
+	return policies
 }
 
-func (m *pMap) get(key PolicyType) []*kyverno.ClusterPolicy {
+func (m *pMap) list(key PolicyType) []*kyverno.ClusterPolicy {
 	m.RLock()
 	defer m.RUnlock()
 
-	return m.dataMap[key]
+	policies := make([]*kyverno.ClusterPolicy, len(m.dataMap[key]))
+	copy(policies, m.dataMap[key])
+	return policies
 }
 
 func (m *pMap) remove(policy *kyverno.ClusterPolicy) {
 	m.Lock()
 	defer m.Unlock()
 
-	pName := policy.GetName()
+	pName := kyverno.PolicyKey(policy.GetNamespace(), policy.GetName())
 	dataMap := m.dataMap
 	for k, policies := range dataMap {
 
 		var newPolicies []*kyverno.ClusterPolicy
 		for _, p := range policies {
-			if p.GetName() == pName {
+			if kyverno.PolicyKey(p.GetNamespace(), p.GetName()) == pName {
 				continue
 			}
 			newPolicies = append(newPolicies, p)
@@ -156,4 +179,33 @@ func (m *pMap) remove(policy *kyverno.ClusterPolicy) {
 			delete(nameCache, pName)
 		}
 	}
+	m.recordSize()
+}
+
+// recordSize updates the metrics with the number of cached policies of each type
+func (m *pMap) recordSize() {
+	for policyType, names := range m.nameCacheMap {
+		metrics.SetPolicyCacheSize(policyType.String(), len(names))
+	}
+}
+
+// validationFailureActions returns whether the rule can be enforced or audited,
+// the rule action has precedence over the policy action and its namespace overrides
+func validationFailureActions(policy *kyverno.ClusterPolicy, rule kyverno.Rule) (enforce, audit bool) {
+	actions := []string{rule.ValidationFailureAction}
+	if rule.ValidationFailureAction == "" {
+		actions = []string{policy.Spec.ValidationFailureAction}
+		for _, override := range policy.Spec.ValidationFailureActionOverrides {
+			actions = append(actions, override.Action)
+		}
+	}
+
+	for _, action := range actions {
+		if action == "enforce" {
+			enforce = true
+		} else {
+			audit = true
+		}
+	}
+	return enforce, audit
 }
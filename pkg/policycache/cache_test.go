@@ -17,21 +17,21 @@ func Test_All(t *testing.T) {
 	pCache.Add(policy)
 
 	// get
-	if len(pCache.Get(Mutate)) != 1 {
-		t.Errorf("expected 1 mutate policy, found %v", len(pCache.Get(Mutate)))
+	if len(pCache.Get(Mutate, "")) != 1 {
+		t.Errorf("expected 1 mutate policy, found %v", len(pCache.Get(Mutate, "")))
 	}
 
-	if len(pCache.Get(ValidateEnforce)) != 1 {
-		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce)))
+	if len(pCache.Get(ValidateEnforce, "")) != 1 {
+		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce, "")))
 	}
 
-	if len(pCache.Get(Generate)) != 1 {
-		t.Errorf("expected 1 generate policy, found %v", len(pCache.Get(Generate)))
+	if len(pCache.Get(Generate, "")) != 1 {
+		t.Errorf("expected 1 generate policy, found %v", len(pCache.Get(Generate, "")))
 	}
 
 	// remove
 	pCache.Remove(policy)
-	assert.Assert(t, len(pCache.Get(ValidateEnforce)) == 0)
+	assert.Assert(t, len(pCache.Get(ValidateEnforce, "")) == 0)
 }
 
 func Test_Add_Duplicate_Policy(t *testing.T) {
@@ -42,16 +42,16 @@ func Test_Add_Duplicate_Policy(t *testing.T) {
 	pCache.Add(policy)
 	pCache.Add(policy)
 
-	if len(pCache.Get(Mutate)) != 1 {
-		t.Errorf("expected 1 mutate policy, found %v", len(pCache.Get(Mutate)))
+	if len(pCache.Get(Mutate, "")) != 1 {
+		t.Errorf("expected 1 mutate policy, found %v", len(pCache.Get(Mutate, "")))
 	}
 
-	if len(pCache.Get(ValidateEnforce)) != 1 {
-		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce)))
+	if len(pCache.Get(ValidateEnforce, "")) != 1 {
+		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce, "")))
 	}
 
-	if len(pCache.Get(Generate)) != 1 {
-		t.Errorf("expected 1 generate policy, found %v", len(pCache.Get(Generate)))
+	if len(pCache.Get(Generate, "")) != 1 {
+		t.Errorf("expected 1 generate policy, found %v", len(pCache.Get(Generate, "")))
 	}
 }
 
@@ -66,12 +66,45 @@ func Test_Add_Validate_Audit(t *testing.T) {
 	pCache.Add(policy)
 	pCache.Add(policy)
 
-	if len(pCache.Get(ValidateEnforce)) != 1 {
-		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce)))
+	if len(pCache.Get(ValidateEnforce, "")) != 1 {
+		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce, "")))
 	}
 
-	if len(pCache.Get(ValidateAudit)) != 1 {
-		t.Errorf("expected 1 validate audit policy, found %v", len(pCache.Get(ValidateAudit)))
+	if len(pCache.Get(ValidateAudit, "")) != 1 {
+		t.Errorf("expected 1 validate audit policy, found %v", len(pCache.Get(ValidateAudit, "")))
+	}
+}
+
+func Test_Add_Validate_Overrides(t *testing.T) {
+	pCache := newPolicyCache(log.Log)
+	policy := newPolicy(t)
+	policy.Spec.ValidationFailureAction = "audit"
+	policy.Spec.ValidationFailureActionOverrides = []kyverno.ValidationFailureActionOverride{
+		{Action: "enforce", Namespaces: []string{"prod-*"}},
+	}
+
+	pCache.Add(policy)
+	if len(pCache.Get(ValidateEnforce, "")) != 1 {
+		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce, "")))
+	}
+
+	if len(pCache.Get(ValidateAudit, "")) != 1 {
+		t.Errorf("expected 1 validate audit policy, found %v", len(pCache.Get(ValidateAudit, "")))
+	}
+
+	// the rule action has precedence over the overrides
+	pCache.Remove(policy)
+	for i := range policy.Spec.Rules {
+		policy.Spec.Rules[i].ValidationFailureAction = "audit"
+	}
+
+	pCache.Add(policy)
+	if len(pCache.Get(ValidateEnforce, "")) != 0 {
+		t.Errorf("expected 0 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce, "")))
+	}
+
+	if len(pCache.Get(ValidateAudit, "")) != 1 {
+		t.Errorf("expected 1 validate audit policy, found %v", len(pCache.Get(ValidateAudit, "")))
 	}
 }
 
@@ -80,18 +113,18 @@ func Test_Add_Remove(t *testing.T) {
 	policy := newPolicy(t)
 
 	pCache.Add(policy)
-	if len(pCache.Get(ValidateEnforce)) != 1 {
-		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce)))
+	if len(pCache.Get(ValidateEnforce, "")) != 1 {
+		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce, "")))
 	}
 
 	pCache.Remove(policy)
-	if len(pCache.Get(ValidateEnforce)) != 0 {
-		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce)))
+	if len(pCache.Get(ValidateEnforce, "")) != 0 {
+		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce, "")))
 	}
 
 	pCache.Add(policy)
-	if len(pCache.Get(ValidateEnforce)) != 1 {
-		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce)))
+	if len(pCache.Get(ValidateEnforce, "")) != 1 {
+		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce, "")))
 	}
 }
 
@@ -102,6 +135,33 @@ func Test_Remove_From_Empty_Cache(t *testing.T) {
 	pCache.Remove(policy)
 }
 
+func Test_Namespaced_Policy(t *testing.T) {
+	pCache := newPolicyCache(log.Log)
+	policy := newPolicy(t)
+	nsPolicy := newPolicy(t)
+	nsPolicy.SetNamespace("test")
+
+	pCache.Add(policy)
+	pCache.Add(nsPolicy)
+
+	if len(pCache.Get(ValidateEnforce, "test")) != 2 {
+		t.Errorf("expected 2 validate enforce policies, found %v", len(pCache.Get(ValidateEnforce, "test")))
+	}
+
+	if len(pCache.Get(ValidateEnforce, "default")) != 1 {
+		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce, "default")))
+	}
+
+	if len(pCache.List(ValidateEnforce)) != 2 {
+		t.Errorf("expected 2 validate enforce policies, found %v", len(pCache.List(ValidateEnforce)))
+	}
+
+	pCache.Remove(nsPolicy)
+	if len(pCache.Get(ValidateEnforce, "test")) != 1 {
+		t.Errorf("expected 1 validate enforce policy, found %v", len(pCache.Get(ValidateEnforce, "test")))
+	}
+}
+
 func newPolicy(t *testing.T) *kyverno.ClusterPolicy {
 	rawPolicy := []byte(`{
 		"metadata": {
@@ -205,3 +265,23 @@ func newPolicy(t *testing.T) *kyverno.ClusterPolicy {
 
 	return policy
 }
+
+func Test_ChangeHandlers(t *testing.T) {
+	pc := &Controller{Cache: newPolicyCache(log.Log), log: log.Log}
+	var changes int
+	pc.AddChangeHandler(func() {
+		// the handlers see the updated cache
+		changes++
+		assert.Equal(t, len(pc.Cache.List(Mutate)), changes%2)
+	})
+
+	policy := newPolicy(t)
+	pc.addPolicy(policy)
+	pc.deletePolicy(policy)
+	pc.addPolicy(policy)
+	assert.Equal(t, changes, 3)
+
+	// the handlers are not called if the spec is unchanged
+	pc.updatePolicy(policy, policy.DeepCopy())
+	assert.Equal(t, changes, 3)
+}
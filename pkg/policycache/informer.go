@@ -15,14 +15,19 @@ import (
 // This cache is only used in the admission webhook to fast retrieve
 // policies based on types (Mutate/ValidateEnforce/Generate).
 type Controller struct {
-	pSynched cache.InformerSynced
-	Cache    Interface
-	log      logr.Logger
+	pSynched  cache.InformerSynced
+	npSynched cache.InformerSynced
+	Cache     Interface
+	log       logr.Logger
+
+	// changeHandlers are called after the cache is updated
+	changeHandlers []func()
 }
 
 // NewPolicyCacheController create a new PolicyController
 func NewPolicyCacheController(
 	pInformer kyvernoinformer.ClusterPolicyInformer,
+	npInformer kyvernoinformer.PolicyInformer,
 	log logr.Logger) *Controller {
 
 	pc := Controller{
@@ -36,14 +41,39 @@ func NewPolicyCacheController(
 		DeleteFunc: pc.deletePolicy,
 	})
 
+	npInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    pc.addNsPolicy,
+		UpdateFunc: pc.updateNsPolicy,
+		DeleteFunc: pc.deleteNsPolicy,
+	})
+
 	pc.pSynched = pInformer.Informer().HasSynced
+	pc.npSynched = npInformer.Informer().HasSynced
 
 	return &pc
 }
 
+// AddChangeHandler registers a handler called after a policy is added, updated or removed from the cache,
+// the handlers must be registered before the informers are started
+func (c *Controller) AddChangeHandler(handler func()) {
+	c.changeHandlers = append(c.changeHandlers, handler)
+}
+
+// HasSynced returns true once the policy informers are synced
+func (c *Controller) HasSynced() bool {
+	return c.pSynched() && c.npSynched()
+}
+
+func (c *Controller) changed() {
+	for _, handler := range c.changeHandlers {
+		handler()
+	}
+}
+
 func (c *Controller) addPolicy(obj interface{}) {
 	p := obj.(*kyverno.ClusterPolicy)
 	c.Cache.Add(p)
+	c.changed()
 }
 
 func (c *Controller) updatePolicy(old, cur interface{}) {
@@ -56,11 +86,50 @@ func (c *Controller) updatePolicy(old, cur interface{}) {
 
 	c.Cache.Remove(pOld)
 	c.Cache.Add(pNew)
+	c.changed()
 }
 
 func (c *Controller) deletePolicy(obj interface{}) {
 	p := obj.(*kyverno.ClusterPolicy)
 	c.Cache.Remove(p)
+	c.changed()
+}
+
+func (c *Controller) addNsPolicy(obj interface{}) {
+	p := obj.(*kyverno.Policy)
+	c.Cache.Add(kyverno.ConvertPolicyToClusterPolicy(p))
+	c.changed()
+}
+
+func (c *Controller) updateNsPolicy(old, cur interface{}) {
+	pOld := old.(*kyverno.Policy)
+	pNew := cur.(*kyverno.Policy)
+
+	if reflect.DeepEqual(pOld.Spec, pNew.Spec) {
+		return
+	}
+
+	c.Cache.Remove(kyverno.ConvertPolicyToClusterPolicy(pOld))
+	c.Cache.Add(kyverno.ConvertPolicyToClusterPolicy(pNew))
+	c.changed()
+}
+
+func (c *Controller) deleteNsPolicy(obj interface{}) {
+	p, ok := obj.(*kyverno.Policy)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.log.Info("couldn't get object from tombstone", "obj", obj)
+			return
+		}
+		p, ok = tombstone.Obj.(*kyverno.Policy)
+		if !ok {
+			c.log.Info("tombstone contained object that is not a Policy", "obj", obj)
+			return
+		}
+	}
+	c.Cache.Remove(kyverno.ConvertPolicyToClusterPolicy(p))
+	c.changed()
 }
 
 // Run waits until policy informer to be synced
@@ -69,7 +138,7 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	logger.Info("starting")
 	defer logger.Info("shutting down")
 
-	if !cache.WaitForCacheSync(stopCh, c.pSynched) {
+	if !cache.WaitForCacheSync(stopCh, c.pSynched, c.npSynched) {
 		logger.Info("failed to sync informer cache")
 		return
 	}
@@ -8,3 +8,17 @@ const (
 	ValidateAudit
 	Generate
 )
+
+func (t PolicyType) String() string {
+	switch t {
+	case Mutate:
+		return "Mutate"
+	case ValidateEnforce:
+		return "ValidateEnforce"
+	case ValidateAudit:
+		return "ValidateAudit"
+	case Generate:
+		return "Generate"
+	}
+	return "Unknown"
+}
@@ -246,6 +246,8 @@ type kyvernoRule struct {
 	ExcludeResources *kyverno.ExcludeResources `json:"exclude,omitempty"`
 	Mutation         *kyverno.Mutation         `json:"mutate,omitempty"`
 	Validation       *kyverno.Validation       `json:"validate,omitempty"`
+	// ValidationFailureAction of the rule, if it overrides the policy action
+	ValidationFailureAction string `json:"validationFailureAction,omitempty"`
 }
 
 func generateRuleForControllers(rule kyverno.Rule, controllers string, log logr.Logger) kyvernoRule {
@@ -253,6 +255,11 @@ func generateRuleForControllers(rule kyverno.Rule, controllers string, log logr.
 		return kyvernoRule{}
 	}
 
+	if rule.HasResourceFilters() {
+		log.V(4).Info("skip generating rule on pod controllers: any / all in match and exclude are not supported", "rule", rule.Name)
+		return kyvernoRule{}
+	}
+
 	match := rule.MatchResources
 	exclude := rule.ExcludeResources
 	if !utils.ContainsString(match.ResourceDescription.Kinds, "Pod") ||
@@ -282,9 +289,9 @@ func generateRuleForControllers(rule kyverno.Rule, controllers string, log logr.
 	}
 
 	if skipAutoGeneration {
-		if match.ResourceDescription.Name != "" || match.ResourceDescription.Selector != nil ||
-			exclude.ResourceDescription.Name != "" || exclude.ResourceDescription.Selector != nil {
-			log.Info("skip generating rule on pod controllers: Name / Selector in resource decription may not be applicable.", "rule", rule.Name)
+		if match.ResourceDescription.Name != "" || match.ResourceDescription.Selector != nil || len(match.ResourceDescription.Annotations) != 0 ||
+			exclude.ResourceDescription.Name != "" || exclude.ResourceDescription.Selector != nil || len(exclude.ResourceDescription.Annotations) != 0 {
+			log.Info("skip generating rule on pod controllers: Name / Selector / Annotations in resource decription may not be applicable.", "rule", rule.Name)
 			return kyvernoRule{}
 		}
 		if controllers == "all" {
@@ -295,8 +302,9 @@ func generateRuleForControllers(rule kyverno.Rule, controllers string, log logr.
 	}
 
 	controllerRule := &kyvernoRule{
-		Name:           fmt.Sprintf("autogen-%s", rule.Name),
-		MatchResources: match.DeepCopy(),
+		Name:                    fmt.Sprintf("autogen-%s", rule.Name),
+		MatchResources:          match.DeepCopy(),
+		ValidationFailureAction: rule.ValidationFailureAction,
 	}
 
 	// overwrite Kinds by pod controllers defined in the annotation
@@ -351,6 +359,31 @@ func generateRuleForControllers(rule kyverno.Rule, controllers string, log logr.
 		return *controllerRule
 	}
 
+	if len(rule.Validation.Immutable) != 0 {
+		var paths []string
+		for _, path := range rule.Validation.Immutable {
+			paths = append(paths, "/spec/template"+path)
+		}
+
+		controllerRule.Validation = &kyverno.Validation{
+			Message:   rule.Validation.Message,
+			Immutable: paths,
+		}
+		return *controllerRule
+	}
+
+	if rule.Validation.ForEachValidation != nil {
+		// the elements are validated as is, only the list moves to the pod template
+		foreach := rule.Validation.ForEachValidation.DeepCopy()
+		foreach.List = strings.ReplaceAll(foreach.List, "request.object.spec", "request.object.spec.template.spec")
+
+		controllerRule.Validation = &kyverno.Validation{
+			Message:           rule.Validation.Message,
+			ForEachValidation: foreach,
+		}
+		return *controllerRule
+	}
+
 	return kyvernoRule{}
 }
 
@@ -0,0 +1,155 @@
+package policyreport
+
+import (
+	"strings"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	report "github.com/nirmata/kyverno/pkg/api/policyreport/v1alpha1"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/nirmata/kyverno/pkg/engine/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// categoryAnnotation is the policy annotation with the category of the results
+	categoryAnnotation = "policies.kyverno.io/category"
+	// severityAnnotation is the policy annotation with the severity of the results
+	severityAnnotation = "policies.kyverno.io/severity"
+	// scoredAnnotation is the policy annotation to report the failures as warnings when set to "false"
+	scoredAnnotation = "policies.kyverno.io/scored"
+)
+
+// Info is a request to report the results of a policy for a resource
+type Info struct {
+	PolicyName string
+	// PolicyNamespace is set for the results of a namespaced policy
+	PolicyNamespace string
+	Resource        corev1.ObjectReference
+	Results         []RuleResult
+
+	// deleted removes the results of the resource
+	deleted bool
+}
+
+// RuleResult is the result of a policy rule for a resource
+type RuleResult struct {
+	Name    string
+	Status  report.PolicyStatus
+	Message string
+}
+
+func (i Info) policyKey() string {
+	return kyverno.PolicyKey(i.PolicyNamespace, i.PolicyName)
+}
+
+//BuildRuleResults returns the results of the rules of an engine response, the failures
+// of validation rules are reported as fail and the failures of other rules as error
+func BuildRuleResults(er response.EngineResponse) []RuleResult {
+	var results []RuleResult
+	for _, rule := range er.PolicyResponse.Rules {
+		result := RuleResult{
+			Name:    rule.Name,
+			Status:  report.StatusPass,
+			Message: rule.Message,
+		}
+		if !rule.Success {
+			result.Status = report.StatusError
+			if rule.Type == utils.Validation.String() {
+				result.Status = report.StatusFail
+			}
+		}
+		results = append(results, result)
+	}
+
+	for _, rule := range er.PolicyResponse.RulesSkipped {
+		results = append(results, RuleResult{
+			Name:    rule,
+			Status:  report.StatusSkip,
+			Message: "preconditions not met",
+		})
+	}
+	return results
+}
+
+// resultKey identifies the result of a policy rule for a resource in a report
+func resultKey(policy, rule string, resource *corev1.ObjectReference) string {
+	return strings.Join([]string{policy, rule, resource.Kind, resource.Namespace, resource.Name}, "/")
+}
+
+// mergeResults replaces the results of the reported policy rules and resources,
+// and removes the results of the deleted resources
+func mergeResults(results []*report.PolicyReportResult, infos []Info) []*report.PolicyReportResult {
+	keyToResult := make(map[string]*report.PolicyReportResult, len(results))
+	var keys []string
+	add := func(key string, result *report.PolicyReportResult) {
+		if _, ok := keyToResult[key]; !ok {
+			keys = append(keys, key)
+		}
+		keyToResult[key] = result
+	}
+	remove := func(resource *corev1.ObjectReference) {
+		for key, result := range keyToResult {
+			r := result.Resources[0]
+			if r.Kind == resource.Kind && r.Namespace == resource.Namespace && r.Name == resource.Name {
+				delete(keyToResult, key)
+			}
+		}
+	}
+
+	for _, result := range results {
+		if len(result.Resources) == 0 {
+			continue
+		}
+		add(resultKey(result.Policy, result.Rule, result.Resources[0]), result)
+	}
+
+	for _, info := range infos {
+		if info.deleted {
+			remove(&info.Resource)
+			continue
+		}
+
+		for _, rule := range info.Results {
+			resource := info.Resource
+			result := &report.PolicyReportResult{
+				Policy:    info.policyKey(),
+				Rule:      rule.Name,
+				Message:   rule.Message,
+				Status:    rule.Status,
+				Resources: []*corev1.ObjectReference{&resource},
+				Scored:    true,
+			}
+			add(resultKey(result.Policy, result.Rule, &resource), result)
+		}
+	}
+
+	merged := make([]*report.PolicyReportResult, 0, len(keyToResult))
+	for _, key := range keys {
+		// a key is listed again if a result is added after the resource was deleted
+		if result, ok := keyToResult[key]; ok {
+			merged = append(merged, result)
+			delete(keyToResult, key)
+		}
+	}
+	return merged
+}
+
+// summarize counts the results by status
+func summarize(results []*report.PolicyReportResult) report.PolicyReportSummary {
+	var summary report.PolicyReportSummary
+	for _, result := range results {
+		switch result.Status {
+		case report.StatusPass:
+			summary.Pass++
+		case report.StatusFail:
+			summary.Fail++
+		case report.StatusWarn:
+			summary.Warn++
+		case report.StatusError:
+			summary.Error++
+		case report.StatusSkip:
+			summary.Skip++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,91 @@
+package policyreport
+
+import (
+	"testing"
+
+	report "github.com/nirmata/kyverno/pkg/api/policyreport/v1alpha1"
+	"github.com/nirmata/kyverno/pkg/engine/response"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_BuildRuleResults(t *testing.T) {
+	er := response.EngineResponse{
+		PolicyResponse: response.PolicyResponse{
+			Policy: "check-labels",
+			Rules: []response.RuleResponse{
+				{Name: "require-app", Type: "Validation", Message: "validation rule 'require-app' passed.", Success: true},
+				{Name: "require-team", Type: "Validation", Message: "label 'team' is required", Success: false},
+				{Name: "add-owner", Type: "Mutation", Message: "referenced paths are not present: request.object.metadata.name1", Success: false},
+			},
+			RulesSkipped: []string{"require-env"},
+		},
+	}
+
+	results := BuildRuleResults(er)
+	assert.Equal(t, len(results), 4)
+	assert.Equal(t, results[0].Status, report.StatusPass)
+	assert.Equal(t, results[1].Status, report.StatusFail)
+	assert.Equal(t, results[1].Message, "label 'team' is required")
+	assert.Equal(t, results[2].Status, report.StatusError)
+	assert.Equal(t, results[3].Name, "require-env")
+	assert.Equal(t, results[3].Status, report.StatusSkip)
+}
+
+func Test_MergeResults(t *testing.T) {
+	pod := corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "nginx"}
+	existing := []*report.PolicyReportResult{
+		{Policy: "check-labels", Rule: "require-app", Status: report.StatusFail, Resources: []*corev1.ObjectReference{&pod}},
+		{Policy: "check-labels", Rule: "require-team", Status: report.StatusPass, Resources: []*corev1.ObjectReference{&pod}},
+	}
+
+	infos := []Info{
+		{
+			PolicyName: "check-labels",
+			Resource:   pod,
+			Results:    []RuleResult{{Name: "require-app", Status: report.StatusPass}},
+		},
+		{
+			PolicyName:      "check-image",
+			PolicyNamespace: "default",
+			Resource:        pod,
+			Results:         []RuleResult{{Name: "require-tag", Status: report.StatusSkip}},
+		},
+	}
+
+	results := mergeResults(existing, infos)
+	assert.Equal(t, len(results), 3)
+	assert.Equal(t, results[0].Rule, "require-app")
+	assert.Equal(t, results[0].Status, report.StatusPass)
+	assert.Equal(t, results[1].Rule, "require-team")
+	assert.Equal(t, results[1].Status, report.StatusPass)
+	assert.Equal(t, results[2].Policy, "default/check-image")
+	assert.Equal(t, results[2].Status, report.StatusSkip)
+
+	summary := summarize(results)
+	assert.Equal(t, summary, report.PolicyReportSummary{Pass: 2, Skip: 1})
+}
+
+func Test_MergeResults_DeletedResource(t *testing.T) {
+	pod := corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "nginx"}
+	other := corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "redis"}
+	existing := []*report.PolicyReportResult{
+		{Policy: "check-labels", Rule: "require-app", Status: report.StatusFail, Resources: []*corev1.ObjectReference{&pod}},
+		{Policy: "check-labels", Rule: "require-app", Status: report.StatusPass, Resources: []*corev1.ObjectReference{&other}},
+		{Policy: "check-labels", Rule: "require-team", Status: report.StatusFail, Resources: []*corev1.ObjectReference{&pod}},
+	}
+
+	results := mergeResults(existing, []Info{{Resource: pod, deleted: true}})
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Resources[0].Name, "redis")
+
+	// the results of a resource created again after its deletion are kept
+	results = mergeResults(existing, []Info{
+		{Resource: pod, deleted: true},
+		{PolicyName: "check-labels", Resource: pod, Results: []RuleResult{{Name: "require-app", Status: report.StatusPass}}},
+	})
+	assert.Equal(t, len(results), 2)
+	assert.Equal(t, results[0].Resources[0].Name, "nginx")
+	assert.Equal(t, results[0].Status, report.StatusPass)
+	assert.Equal(t, results[1].Resources[0].Name, "redis")
+}
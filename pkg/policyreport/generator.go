@@ -0,0 +1,417 @@
+package policyreport
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	report "github.com/nirmata/kyverno/pkg/api/policyreport/v1alpha1"
+	kyvernoinformer "github.com/nirmata/kyverno/pkg/client/informers/externalversions/kyverno/v1"
+	kyvernolister "github.com/nirmata/kyverno/pkg/client/listers/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/constant"
+	dclient "github.com/nirmata/kyverno/pkg/dclient"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	workQueueName       = "policy-report-controller"
+	workQueueRetryLimit = 5
+	// batchPeriod is the period during which the results of a report are batched before the report is written
+	batchPeriod = 5 * time.Second
+
+	// clusterReportName is the name of the report with the results of the cluster-wide resources
+	clusterReportName = "clusterpolicyreport"
+	// reportNamePrefix is the prefix of the name of the report with the results of the resources of a namespace
+	reportNamePrefix = "policyreport-ns-"
+)
+
+//GeneratorInterface provides API to report policy results
+type GeneratorInterface interface {
+	Add(infos ...Info)
+	RemovePolicy(policyKey string)
+	RemoveResource(resource corev1.ObjectReference)
+}
+
+//Generator batches the policy results into a PolicyReport per namespace and a ClusterPolicyReport
+// for the cluster-wide resources
+type Generator struct {
+	dclient *dclient.Client
+	// get/list cluster policies
+	pLister kyvernolister.ClusterPolicyLister
+	// get/list namespaced policies
+	npLister kyvernolister.PolicyLister
+	pSynced  cache.InformerSynced
+	npSynced cache.InformerSynced
+	queue    workqueue.RateLimitingInterface
+	// the results pending for each report, by namespace
+	dataStore *dataStore
+	log       logr.Logger
+}
+
+type dataStore struct {
+	data map[string][]Info
+	// rebuild is set for the reports to check for the results of deleted resources
+	rebuild map[string]bool
+	mu      sync.Mutex
+}
+
+func (ds *dataStore) add(namespace string, infos ...Info) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.data[namespace] = append(ds.data[namespace], infos...)
+}
+
+func (ds *dataStore) setRebuild(namespace string) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.rebuild[namespace] = true
+}
+
+// requeue adds back the results of a report that was not written, before the results added since
+func (ds *dataStore) requeue(namespace string, infos []Info, rebuild bool) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.data[namespace] = append(infos, ds.data[namespace]...)
+	if rebuild {
+		ds.rebuild[namespace] = true
+	}
+}
+
+// pop returns and removes the results pending for a report, and if the report must be rebuilt
+func (ds *dataStore) pop(namespace string) ([]Info, bool) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	infos, rebuild := ds.data[namespace], ds.rebuild[namespace]
+	delete(ds.data, namespace)
+	delete(ds.rebuild, namespace)
+	return infos, rebuild
+}
+
+// NewGenerator returns a new instance of policy report generator
+func NewGenerator(dclient *dclient.Client,
+	pInformer kyvernoinformer.ClusterPolicyInformer,
+	npInformer kyvernoinformer.PolicyInformer,
+	log logr.Logger) *Generator {
+	gen := Generator{
+		dclient:   dclient,
+		pLister:   pInformer.Lister(),
+		npLister:  npInformer.Lister(),
+		pSynced:   pInformer.Informer().HasSynced,
+		npSynced:  npInformer.Informer().HasSynced,
+		queue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), workQueueName),
+		dataStore: &dataStore{data: make(map[string][]Info), rebuild: make(map[string]bool)},
+		log:       log,
+	}
+	return &gen
+}
+
+//Add queues the results of policies, the results for the same report are written together
+func (gen *Generator) Add(infos ...Info) {
+	for _, info := range infos {
+		if len(info.Results) == 0 {
+			continue
+		}
+		gen.dataStore.add(info.Resource.Namespace, info)
+		gen.queue.AddAfter(info.Resource.Namespace, batchPeriod)
+	}
+}
+
+//RemovePolicy queues all the reports, the results of the policies and rules that no
+// longer exist are removed when a report is written
+func (gen *Generator) RemovePolicy(policyKey string) {
+	namespaces, err := gen.reportNamespaces()
+	if err != nil {
+		gen.log.Error(err, "failed to list policy reports", "policy", policyKey)
+		return
+	}
+
+	for _, namespace := range namespaces {
+		gen.queue.Add(namespace)
+	}
+}
+
+//RemoveResource queues the removal of the results of a deleted resource from its report
+func (gen *Generator) RemoveResource(resource corev1.ObjectReference) {
+	gen.dataStore.add(resource.Namespace, Info{Resource: resource, deleted: true})
+	gen.queue.AddAfter(resource.Namespace, batchPeriod)
+}
+
+// rebuildReports queues all the reports to remove the results of the resources that were deleted
+// while no request was received for them, the results of the existing resources are updated
+// by the background scans of the policies
+func (gen *Generator) rebuildReports() {
+	namespaces, err := gen.reportNamespaces()
+	if err != nil {
+		gen.log.Error(err, "failed to list policy reports")
+		return
+	}
+
+	for _, namespace := range namespaces {
+		gen.dataStore.setRebuild(namespace)
+		gen.queue.Add(namespace)
+	}
+}
+
+// reportNamespaces returns the namespaces of the policy reports, and the empty namespace of the cluster report
+func (gen *Generator) reportNamespaces() ([]string, error) {
+	reports, err := gen.dclient.ListResource(report.SchemeGroupVersion.String(), "PolicyReport", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := []string{""}
+	for _, r := range reports.Items {
+		namespaces = append(namespaces, r.GetNamespace())
+	}
+	return namespaces, nil
+}
+
+// Run starts the workers
+func (gen *Generator) Run(workers int, stopCh <-chan struct{}) {
+	logger := gen.log
+	defer utilruntime.HandleCrash()
+	logger.Info("start")
+	defer logger.Info("shutting down")
+
+	if !cache.WaitForCacheSync(stopCh, gen.pSynced, gen.npSynced) {
+		logger.Info("failed to sync informer cache")
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(gen.runWorker, constant.PolicyReportControllerResync, stopCh)
+	}
+	go wait.Until(gen.rebuildReports, constant.PolicyReportControllerResync, stopCh)
+	<-stopCh
+	gen.queue.ShutDown()
+}
+
+func (gen *Generator) runWorker() {
+	for gen.processNextWorkItem() {
+	}
+}
+
+func (gen *Generator) processNextWorkItem() bool {
+	obj, shutdown := gen.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer gen.queue.Done(obj)
+
+	namespace, ok := obj.(string)
+	if !ok {
+		gen.queue.Forget(obj)
+		gen.log.Info("incorrect type; expecting type 'string'", "obj", obj)
+		return true
+	}
+
+	infos, rebuild := gen.dataStore.pop(namespace)
+	err := gen.syncHandler(namespace, infos, rebuild)
+	gen.handleErr(err, namespace, infos, rebuild)
+	return true
+}
+
+func (gen *Generator) handleErr(err error, namespace string, infos []Info, rebuild bool) {
+	logger := gen.log.WithValues("namespace", namespace)
+	if err == nil {
+		gen.queue.Forget(namespace)
+		return
+	}
+
+	// the results are kept until the report is written, unless newer results replace them
+	if gen.queue.NumRequeues(namespace) < workQueueRetryLimit {
+		logger.Error(err, "failed to sync policy report")
+		gen.dataStore.requeue(namespace, infos, rebuild)
+		gen.queue.AddRateLimited(namespace)
+		return
+	}
+
+	gen.queue.Forget(namespace)
+	logger.Error(err, "dropping policy results out of the queue", "count", len(infos))
+}
+
+// syncHandler merges the results in the report of the namespace, the report is created if needed.
+// The results of deleted resources are removed when the report is rebuilt.
+func (gen *Generator) syncHandler(namespace string, infos []Info, rebuild bool) error {
+	kind, name := "PolicyReport", reportNamePrefix+namespace
+	if namespace == "" {
+		kind, name = "ClusterPolicyReport", clusterReportName
+	}
+	logger := gen.log.WithValues("kind", kind, "namespace", namespace, "name", name)
+
+	var polr report.PolicyReport
+	obj, err := gen.dclient.GetResource(report.SchemeGroupVersion.String(), kind, namespace, name)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	create := errors.IsNotFound(err)
+	if create {
+		polr.SetName(name)
+		polr.SetNamespace(namespace)
+		polr.SetLabels(map[string]string{"app.kubernetes.io/managed-by": "kyverno"})
+	} else if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &polr); err != nil {
+		return err
+	}
+
+	existing := polr.DeepCopy().Results
+	results := gen.updateResults(mergeResults(polr.Results, infos))
+	if rebuild {
+		results = gen.removeDeletedResources(namespace, results)
+	}
+	if !create && reflect.DeepEqual(results, existing) {
+		logger.V(4).Info("policy report is up to date")
+		return nil
+	}
+	if create && len(results) == 0 {
+		return nil
+	}
+	polr.Results = results
+	polr.Summary = summarize(results)
+
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&polr)
+	if err != nil {
+		return err
+	}
+	newObj := &unstructured.Unstructured{Object: data}
+	newObj.SetAPIVersion(report.SchemeGroupVersion.String())
+	newObj.SetKind(kind)
+
+	if create {
+		_, err = gen.dclient.CreateResource(report.SchemeGroupVersion.String(), kind, namespace, newObj, false)
+	} else {
+		_, err = gen.dclient.UpdateResource(report.SchemeGroupVersion.String(), kind, namespace, newObj, false)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.V(4).Info("updated policy report", "results", len(results))
+	return nil
+}
+
+// updateResults removes the results of the policies and rules that do not exist anymore, and sets
+// the category, severity and scoring of the results from the annotations of their policy
+func (gen *Generator) updateResults(results []*report.PolicyReportResult) []*report.PolicyReportResult {
+	policies := map[string]*kyverno.ClusterPolicy{}
+	updated := make([]*report.PolicyReportResult, 0, len(results))
+	for _, result := range results {
+		policy, ok := policies[result.Policy]
+		if !ok {
+			policy = gen.getPolicy(result.Policy)
+			policies[result.Policy] = policy
+		}
+		if policy == nil || !hasRule(policy, result.Rule) {
+			continue
+		}
+
+		annotations := policy.GetAnnotations()
+		result.Category = annotations[categoryAnnotation]
+		result.Severity = report.PolicySeverity(annotations[severityAnnotation])
+		result.Scored = annotations[scoredAnnotation] != "false"
+		if result.Status == report.StatusFail || result.Status == report.StatusWarn {
+			result.Status = report.StatusFail
+			if !result.Scored {
+				result.Status = report.StatusWarn
+			}
+		}
+		updated = append(updated, result)
+	}
+	return updated
+}
+
+// getPolicy returns the cluster policy for a name key, and the namespaced policy for a namespace/name key,
+// it returns nil if the policy does not exist
+func (gen *Generator) getPolicy(key string) *kyverno.ClusterPolicy {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil
+	}
+
+	if namespace == "" {
+		policy, err := gen.pLister.Get(name)
+		if err != nil {
+			return nil
+		}
+		return policy
+	}
+
+	policy, err := gen.npLister.Policies(namespace).Get(name)
+	if err != nil {
+		return nil
+	}
+	return &kyverno.ClusterPolicy{ObjectMeta: policy.ObjectMeta, Spec: policy.Spec}
+}
+
+// removeDeletedResources removes the results of the resources that do not exist anymore, the resources
+// are listed once per kind and the results are kept if the resources of a kind cannot be listed
+func (gen *Generator) removeDeletedResources(namespace string, results []*report.PolicyReportResult) []*report.PolicyReportResult {
+	logger := gen.log.WithValues("namespace", namespace)
+	// the UIDs of the existing resources by name, for each apiVersion and kind
+	existing := map[string]map[string]types.UID{}
+	updated := make([]*report.PolicyReportResult, 0, len(results))
+	for _, result := range results {
+		if len(result.Resources) == 0 {
+			updated = append(updated, result)
+			continue
+		}
+
+		resource := result.Resources[0]
+		kindKey := resource.APIVersion + "/" + resource.Kind
+		uids, ok := existing[kindKey]
+		if !ok {
+			uids = gen.listResources(resource.APIVersion, resource.Kind, namespace)
+			if uids == nil {
+				logger.V(4).Info("failed to list resources, keeping their results", "apiVersion", resource.APIVersion, "kind", resource.Kind)
+			}
+			existing[kindKey] = uids
+		}
+
+		if uids != nil {
+			uid, found := uids[resource.Name]
+			// a resource created again with the same name has a different UID
+			if !found || (resource.UID != "" && uid != resource.UID) {
+				continue
+			}
+		}
+		updated = append(updated, result)
+	}
+	return updated
+}
+
+// listResources returns the UIDs of the resources of a kind by name, or nil if the resources cannot be listed
+func (gen *Generator) listResources(apiVersion, kind, namespace string) map[string]types.UID {
+	// the results are kept if the kind is not found, e.g. until the discovery cache is refreshed
+	if gen.dclient.DiscoveryClient.GetGVRFromAPIVersionKind(apiVersion, kind).Resource == "" {
+		return nil
+	}
+
+	list, err := gen.dclient.ListResource(apiVersion, kind, namespace, nil)
+	if err != nil {
+		return nil
+	}
+
+	uids := make(map[string]types.UID, len(list.Items))
+	for _, item := range list.Items {
+		uids[item.GetName()] = item.GetUID()
+	}
+	return uids
+}
+
+func hasRule(policy *kyverno.ClusterPolicy, name string) bool {
+	for _, rule := range policy.Spec.Rules {
+		if rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package policyreport
+
+import (
+	"testing"
+
+	report "github.com/nirmata/kyverno/pkg/api/policyreport/v1alpha1"
+	client "github.com/nirmata/kyverno/pkg/dclient"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func Test_RemoveDeletedResources(t *testing.T) {
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetNamespace("default")
+	secret.SetName("token")
+	secret.SetUID("1")
+
+	dclient, err := client.NewMockClient(runtime.NewScheme(), secret)
+	assert.NilError(t, err)
+	dclient.SetDiscovery(client.NewFakeDiscoveryClient(nil))
+	gen := &Generator{dclient: dclient, log: log.Log}
+
+	existing := corev1.ObjectReference{APIVersion: "v1", Kind: "Secret", Namespace: "default", Name: "token", UID: "1"}
+	deleted := corev1.ObjectReference{APIVersion: "v1", Kind: "Secret", Namespace: "default", Name: "password", UID: "2"}
+	recreated := corev1.ObjectReference{APIVersion: "v1", Kind: "Secret", Namespace: "default", Name: "token", UID: "3"}
+	unknown := corev1.ObjectReference{APIVersion: "example.com/v1", Kind: "Unknown", Namespace: "default", Name: "test"}
+
+	results := gen.removeDeletedResources("default", []*report.PolicyReportResult{
+		{Policy: "check-secrets", Rule: "r1", Resources: []*corev1.ObjectReference{&existing}},
+		{Policy: "check-secrets", Rule: "r1", Resources: []*corev1.ObjectReference{&deleted}},
+		{Policy: "check-secrets", Rule: "r2", Resources: []*corev1.ObjectReference{&recreated}},
+		{Policy: "check-secrets", Rule: "r1", Resources: []*corev1.ObjectReference{&unknown}},
+	})
+
+	// the results of a kind that cannot be listed are kept
+	assert.Equal(t, len(results), 2)
+	assert.Equal(t, results[0].Resources[0].Name, "token")
+	assert.Equal(t, string(results[0].Resources[0].UID), "1")
+	assert.Equal(t, results[1].Resources[0].Kind, "Unknown")
+}
@@ -1,14 +1,15 @@
 package policystatus
 
 import (
-	"encoding/json"
-	"fmt"
 	"sync"
 	"time"
 
 	kyvernolister "github.com/nirmata/kyverno/pkg/client/listers/kyverno/v1"
 
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
 
 	"github.com/nirmata/kyverno/pkg/client/clientset/versioned"
 
@@ -17,21 +18,33 @@ import (
 )
 
 // Policy status implementation works in the following way,
-//Currently policy status maintains a cache of the status of
-//each policy.
-//Every x unit of time the status of policy is updated using
-//the data from the cache.
+//Currently policy status maintains a cache of the pending status
+//updates of each policy.
+//Every x unit of time the status of policy is updated by applying
+//the pending updates to the current status of the policy.
 //The sync exposes a listener which accepts a statusUpdater
 //interface which dictates how the status should be updated.
 //The status is updated by a worker that receives the interface
 //on a channel.
-//The worker then updates the current status using the methods
-//exposed by the interface.
-//Current implementation is designed to be threadsafe with optimised
-//locking for each policy.
+//The worker then queues the update for the policy, the updates
+//are applied in the order they are received.
+//With several instances of Kyverno, each instance applies its own
+//updates to the latest status, so the status aggregates the stats
+//of all the instances. The update is retried on conflicts.
+//The updates of a failed status update are kept for the next sync,
+//they are dropped if the status update keeps failing.
+
+const (
+	// maxStatusUpdateFailures is the number of consecutive failed status updates of a policy
+	// after which its pending updates are dropped
+	maxStatusUpdateFailures = 5
+	// maxPendingUpdates is the maximum number of updates kept for a policy whose status update failed
+	maxPendingUpdates = 1000
+)
 
 // statusUpdater defines a type to have a method which
-//updates the given status
+//updates the given status, PolicyName returns the policy
+//name for cluster policies and namespace/name for namespaced policies
 type statusUpdater interface {
 	PolicyName() string
 	UpdateStatus(status v1.PolicyStatus) v1.PolicyStatus
@@ -52,23 +65,27 @@ type Sync struct {
 	Listener Listener
 	client   *versioned.Clientset
 	lister   kyvernolister.ClusterPolicyLister
+	nsLister kyvernolister.PolicyLister
 }
 
 type cache struct {
-	dataMu     sync.RWMutex
-	data       map[string]v1.PolicyStatus
-	keyToMutex *keyToMutex
+	dataMu sync.Mutex
+	// data holds the updates that are not yet applied to the policy status
+	data map[string][]statusUpdater
+	// failures counts the consecutive failed status updates of a policy
+	failures map[string]int
 }
 
-func NewSync(c *versioned.Clientset, lister kyvernolister.ClusterPolicyLister) *Sync {
+func NewSync(c *versioned.Clientset, lister kyvernolister.ClusterPolicyLister, nsLister kyvernolister.PolicyLister) *Sync {
 	return &Sync{
 		cache: &cache{
-			dataMu:     sync.RWMutex{},
-			data:       make(map[string]v1.PolicyStatus),
-			keyToMutex: newKeyToMutex(),
+			dataMu:   sync.Mutex{},
+			data:     make(map[string][]statusUpdater),
+			failures: make(map[string]int),
 		},
 		client:   c,
 		lister:   lister,
+		nsLister: nsLister,
 		Listener: make(chan statusUpdater, 20),
 	}
 }
@@ -82,63 +99,137 @@ func (s *Sync) Run(workers int, stopCh <-chan struct{}) {
 	<-stopCh
 }
 
-// updateStatusCache is a worker which updates the current status
-//using the statusUpdater interface
+// updateStatusCache is a worker which queues the updates
+//received from the listener
 func (s *Sync) updateStatusCache(stopCh <-chan struct{}) {
 	for {
 		select {
 		case statusUpdater := <-s.Listener:
-			s.cache.keyToMutex.Get(statusUpdater.PolicyName()).Lock()
-
-			s.cache.dataMu.RLock()
-			status, exist := s.cache.data[statusUpdater.PolicyName()]
-			s.cache.dataMu.RUnlock()
-			if !exist {
-				policy, _ := s.lister.Get(statusUpdater.PolicyName())
-				if policy != nil {
-					status = policy.Status
-				}
-			}
-
-			updatedStatus := statusUpdater.UpdateStatus(status)
-
 			s.cache.dataMu.Lock()
-			s.cache.data[statusUpdater.PolicyName()] = updatedStatus
+			s.cache.data[statusUpdater.PolicyName()] = append(s.cache.data[statusUpdater.PolicyName()], statusUpdater)
 			s.cache.dataMu.Unlock()
-
-			s.cache.keyToMutex.Get(statusUpdater.PolicyName()).Unlock()
-			oldStatus, _ := json.Marshal(status)
-			newStatus, _ := json.Marshal(updatedStatus)
-			log.Log.V(4).Info(fmt.Sprintf("\nupdated status of policy - %v\noldStatus:\n%v\nnewStatus:\n%v\n", statusUpdater.PolicyName(), string(oldStatus), string(newStatus)))
+			log.Log.V(4).Info("queued status update", "policy", statusUpdater.PolicyName())
 		case <-stopCh:
 			return
 		}
 	}
 }
 
-// updatePolicyStatus updates the status in the policy resource definition
-//from the status cache, syncing them
+// updatePolicyStatus applies the pending updates to the status
+//in the policy resource definition
 func (s *Sync) updatePolicyStatus() {
 	s.cache.dataMu.Lock()
-	var nameToStatus = make(map[string]v1.PolicyStatus, len(s.cache.data))
-	for k, v := range s.cache.data {
-		nameToStatus[k] = v
-	}
+	pending := s.cache.data
+	s.cache.data = make(map[string][]statusUpdater)
 	s.cache.dataMu.Unlock()
 
-	for policyName, status := range nameToStatus {
-		policy, err := s.lister.Get(policyName)
-		if err != nil {
+	for policyName, updaters := range pending {
+		err := s.updateStatus(policyName, updaters)
+		if err == nil {
+			s.cache.resetFailures(policyName)
 			continue
 		}
 
-		policy.Status = status
-		_, err = s.client.KyvernoV1().ClusterPolicies().UpdateStatus(policy)
+		log.Log.Error(err, "failed to update policy status", "policy", policyName)
+		if dropped := s.cache.requeue(policyName, updaters); dropped > 0 {
+			log.Log.Info("dropped pending policy status updates", "policy", policyName, "count", dropped)
+		}
+	}
+}
+
+func (c *cache) resetFailures(policyName string) {
+	c.dataMu.Lock()
+	defer c.dataMu.Unlock()
+	delete(c.failures, policyName)
+}
+
+// requeue keeps the updates of a failed status update, so they are applied with the next sync.
+// The updates are dropped after maxStatusUpdateFailures consecutive failures, and only the latest
+// maxPendingUpdates are kept. It returns the number of dropped updates.
+func (c *cache) requeue(policyName string, updaters []statusUpdater) int {
+	c.dataMu.Lock()
+	defer c.dataMu.Unlock()
+
+	c.failures[policyName]++
+	if c.failures[policyName] >= maxStatusUpdateFailures {
+		delete(c.failures, policyName)
+		return len(updaters)
+	}
+
+	data := append(updaters, c.data[policyName]...)
+	dropped := 0
+	if len(data) > maxPendingUpdates {
+		dropped = len(data) - maxPendingUpdates
+		data = data[dropped:]
+	}
+	c.data[policyName] = data
+	return dropped
+}
+
+// applyUpdates returns the status with the updates applied in order
+func applyUpdates(status v1.PolicyStatus, updaters []statusUpdater) v1.PolicyStatus {
+	for _, updater := range updaters {
+		status = updater.UpdateStatus(status)
+	}
+	return status
+}
+
+// updateStatus applies the updates to the latest status of the policy resource identified by key
+func (s *Sync) updateStatus(key string, updaters []statusUpdater) error {
+	namespace, name := v1.ParsePolicyKey(key)
+	// the policy is read from the lister first, and from the API server
+	// when the status was changed by another instance
+	fromLister := true
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		defer func() { fromLister = false }()
+		if namespace == "" {
+			policy, err := s.getClusterPolicy(name, fromLister)
+			if err != nil {
+				if errors.IsNotFound(err) {
+					return nil
+				}
+				return err
+			}
+
+			policy.Status = applyUpdates(policy.Status, updaters)
+			_, err = s.client.KyvernoV1().ClusterPolicies().UpdateStatus(policy)
+			return err
+		}
+
+		policy, err := s.getPolicy(namespace, name, fromLister)
 		if err != nil {
-			s.cache.dataMu.Lock()
-			delete(s.cache.data, policyName)
-			s.cache.dataMu.Unlock()
-			log.Log.Error(err, "failed to update policy status")
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
 		}
+
+		policy.Status = applyUpdates(policy.Status, updaters)
+		_, err = s.client.KyvernoV1().Policies(namespace).UpdateStatus(policy)
+		return err
+	})
+}
+
+func (s *Sync) getClusterPolicy(name string, fromLister bool) (*v1.ClusterPolicy, error) {
+	if !fromLister {
+		return s.client.KyvernoV1().ClusterPolicies().Get(name, metav1.GetOptions{})
+	}
+
+	policy, err := s.lister.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return policy.DeepCopy(), nil
+}
+
+func (s *Sync) getPolicy(namespace, name string, fromLister bool) (*v1.Policy, error) {
+	if !fromLister {
+		return s.client.KyvernoV1().Policies(namespace).Get(name, metav1.GetOptions{})
+	}
+
+	policy, err := s.nsLister.Policies(namespace).Get(name)
+	if err != nil {
+		return nil, err
 	}
+	return policy.DeepCopy(), nil
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	v1 "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	kyvernolister "github.com/nirmata/kyverno/pkg/client/listers/kyverno/v1"
 )
 
 type dummyStore struct {
@@ -52,11 +53,22 @@ func (dl dummyLister) ListResources(selector labels.Selector) (ret []*v1.Cluster
 	return nil, fmt.Errorf("not implemented")
 }
 
-func TestKeyToMutex(t *testing.T) {
-	expectedCache := `{"policy1":{"rulesAppliedCount":100}}`
+type dummyNsLister struct {
+}
+
+func (dl dummyNsLister) List(selector labels.Selector) (ret []*v1.Policy, err error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (dl dummyNsLister) Policies(namespace string) kyvernolister.PolicyNamespaceLister {
+	return nil
+}
+
+func TestStatusCache(t *testing.T) {
+	expectedStatus := `{"rulesAppliedCount":100}`
 
 	stopCh := make(chan struct{})
-	s := NewSync(nil, dummyLister{})
+	s := NewSync(nil, dummyLister{}, dummyNsLister{})
 	for i := 0; i < 100; i++ {
 		go s.updateStatusCache(stopCh)
 	}
@@ -68,8 +80,62 @@ func TestKeyToMutex(t *testing.T) {
 	<-time.After(time.Second * 3)
 	stopCh <- struct{}{}
 
-	cacheRaw, _ := json.Marshal(s.cache.data)
-	if string(cacheRaw) != expectedCache {
-		t.Errorf("\nTestcase Failed\nGot:\n%v\nExpected:\n%v\n", string(cacheRaw), expectedCache)
+	s.cache.dataMu.Lock()
+	updaters := s.cache.data["policy1"]
+	s.cache.dataMu.Unlock()
+	if len(updaters) != 100 {
+		t.Errorf("Testcase Failed, expected 100 pending updates, got %d", len(updaters))
+	}
+
+	// the updates are applied to the current status of the policy
+	statusRaw, _ := json.Marshal(applyUpdates(v1.PolicyStatus{}, updaters))
+	if string(statusRaw) != expectedStatus {
+		t.Errorf("\nTestcase Failed\nGot:\n%v\nExpected:\n%v\n", string(statusRaw), expectedStatus)
+	}
+
+	statusRaw, _ = json.Marshal(applyUpdates(v1.PolicyStatus{RulesAppliedCount: 50}, updaters))
+	if string(statusRaw) != `{"rulesAppliedCount":150}` {
+		t.Errorf("Testcase Failed, updates of another instance are lost: %v", string(statusRaw))
+	}
+}
+
+func TestStatusCacheRequeue(t *testing.T) {
+	c := &cache{
+		data:     make(map[string][]statusUpdater),
+		failures: make(map[string]int),
+	}
+
+	updaters := make([]statusUpdater, maxPendingUpdates)
+	for i := range updaters {
+		updaters[i] = dummyStatusUpdater{}
+	}
+
+	// the updates received during the failed update are kept after the failed ones, up to the limit
+	c.data["policy1"] = []statusUpdater{dummyStatusUpdater{}}
+	if dropped := c.requeue("policy1", updaters); dropped != 1 {
+		t.Errorf("expected 1 dropped update, got %d", dropped)
+	}
+	if len(c.data["policy1"]) != maxPendingUpdates {
+		t.Errorf("expected %d pending updates, got %d", maxPendingUpdates, len(c.data["policy1"]))
+	}
+
+	// the updates are dropped after consecutive failures
+	for i := 1; i < maxStatusUpdateFailures-1; i++ {
+		c.requeue("policy1", c.data["policy1"])
+	}
+	pending := c.data["policy1"]
+	c.data["policy1"] = nil
+	if dropped := c.requeue("policy1", pending); dropped != len(pending) {
+		t.Errorf("expected %d dropped updates, got %d", len(pending), dropped)
+	}
+	if len(c.data["policy1"]) != 0 {
+		t.Errorf("expected no pending updates, got %d", len(c.data["policy1"]))
+	}
+
+	// the failures are counted again after a successful update
+	c.requeue("policy2", updaters[:1])
+	c.resetFailures("policy2")
+	if c.failures["policy2"] != 0 {
+		t.Errorf("expected no failures, got %d", c.failures["policy2"])
 	}
 }
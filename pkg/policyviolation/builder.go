@@ -6,9 +6,11 @@ import (
 	"github.com/go-logr/logr"
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	"github.com/nirmata/kyverno/pkg/engine/response"
+	"github.com/nirmata/kyverno/pkg/policyreport"
 )
 
-//GeneratePVsFromEngineResponse generate Violations from engine responses
+//GeneratePVsFromEngineResponse generate Violations from engine responses, the infos of the
+// successful responses have no violated rules and are only reported in the policy reports
 func GeneratePVsFromEngineResponse(ers []response.EngineResponse, log logr.Logger) (pvInfos []Info) {
 	for _, er := range ers {
 		// ignore creation of PV for resources that are yet to be assigned a name
@@ -16,8 +18,8 @@ func GeneratePVsFromEngineResponse(ers []response.EngineResponse, log logr.Logge
 			log.V(4).Info("resource does no have a name assigned yet, not creating a policy violation", "resource", er.PolicyResponse.Resource)
 			continue
 		}
-		// skip when response succeed
-		if er.IsSuccessful() {
+		// skip when no rule applies
+		if len(er.PolicyResponse.Rules) == 0 && len(er.PolicyResponse.RulesSkipped) == 0 {
 			continue
 		}
 		// build policy violation info
@@ -71,9 +73,11 @@ func (pvb *pvBuilder) build(policy, kind, namespace, name string, rules []kyvern
 
 func buildPVInfo(er response.EngineResponse) Info {
 	info := Info{
-		PolicyName: er.PolicyResponse.Policy,
-		Resource:   er.PatchedResource,
-		Rules:      buildViolatedRules(er),
+		PolicyName:      er.PolicyResponse.Policy,
+		PolicyNamespace: er.PolicyResponse.PolicyNamespace,
+		Resource:        er.PatchedResource,
+		Rules:           buildViolatedRules(er),
+		Results:         policyreport.BuildRuleResults(er),
 	}
 	return info
 }
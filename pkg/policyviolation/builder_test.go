@@ -54,5 +54,8 @@ func Test_GeneratePVsFromEngineResponse_PathNotExist(t *testing.T) {
 	}
 
 	pvInfos := GeneratePVsFromEngineResponse(ers, log.Log)
-	assert.Assert(t, len(pvInfos) == 1)
+	assert.Assert(t, len(pvInfos) == 2)
+	assert.Assert(t, len(pvInfos[0].Rules) == 1)
+	assert.Assert(t, len(pvInfos[1].Rules) == 0)
+	assert.Assert(t, len(pvInfos[1].Results) == 1)
 }
@@ -14,9 +14,11 @@ import (
 	kyvernoinformer "github.com/nirmata/kyverno/pkg/client/informers/externalversions/kyverno/v1"
 	kyvernolister "github.com/nirmata/kyverno/pkg/client/listers/kyverno/v1"
 	"github.com/nirmata/kyverno/pkg/constant"
+	"github.com/nirmata/kyverno/pkg/policyreport"
 	"github.com/nirmata/kyverno/pkg/policystatus"
 
 	dclient "github.com/nirmata/kyverno/pkg/dclient"
+	corev1 "k8s.io/api/core/v1"
 	unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -43,6 +45,10 @@ type Generator struct {
 	queue                workqueue.RateLimitingInterface
 	dataStore            *dataStore
 	policyStatusListener policystatus.Listener
+	// the results of the policies are reported in the policy reports
+	reportGenerator policyreport.GeneratorInterface
+	// policy violations are only created if enabled
+	createViolations bool
 }
 
 //NewDataStore returns an instance of data store
@@ -80,13 +86,33 @@ func (ds *dataStore) delete(keyHash string) {
 //Info is a request to create PV
 type Info struct {
 	PolicyName string
-	Resource   unstructured.Unstructured
-	Rules      []kyverno.ViolatedRule
-	FromSync   bool
+	// PolicyNamespace is set for violations of a namespaced policy
+	PolicyNamespace string
+	Resource        unstructured.Unstructured
+	Rules           []kyverno.ViolatedRule
+	// Results are the results of all the rules, for the policy reports
+	Results  []policyreport.RuleResult
+	FromSync bool
+}
+
+func (i Info) toReportInfo() policyreport.Info {
+	return policyreport.Info{
+		PolicyName:      i.PolicyName,
+		PolicyNamespace: i.PolicyNamespace,
+		Resource: corev1.ObjectReference{
+			APIVersion: i.Resource.GetAPIVersion(),
+			Kind:       i.Resource.GetKind(),
+			Namespace:  i.Resource.GetNamespace(),
+			Name:       i.Resource.GetName(),
+			UID:        i.Resource.GetUID(),
+		},
+		Results: i.Results,
+	}
 }
 
 func (i Info) toKey() string {
 	keys := []string{
+		i.PolicyNamespace,
 		i.PolicyName,
 		i.Resource.GetKind(),
 		i.Resource.GetNamespace(),
@@ -98,9 +124,11 @@ func (i Info) toKey() string {
 
 // make the struct hashable
 
-//GeneratorInterface provides API to create PVs
+//GeneratorInterface provides API to create PVs and policy reports
 type GeneratorInterface interface {
 	Add(infos ...Info)
+	RemovePolicy(policyKey string)
+	RemoveResource(resource corev1.ObjectReference)
 }
 
 // NewPVGenerator returns a new instance of policy violation generator
@@ -109,6 +137,8 @@ func NewPVGenerator(client *kyvernoclient.Clientset,
 	pvInformer kyvernoinformer.ClusterPolicyViolationInformer,
 	nspvInformer kyvernoinformer.PolicyViolationInformer,
 	policyStatus policystatus.Listener,
+	reportGenerator policyreport.GeneratorInterface,
+	createViolations bool,
 	log logr.Logger) *Generator {
 	gen := Generator{
 		kyvernoInterface:     client.KyvernoV1(),
@@ -121,6 +151,8 @@ func NewPVGenerator(client *kyvernoclient.Clientset,
 		dataStore:            newDataStore(),
 		log:                  log,
 		policyStatusListener: policyStatus,
+		reportGenerator:      reportGenerator,
+		createViolations:     createViolations,
 	}
 	return &gen
 }
@@ -134,13 +166,27 @@ func (gen *Generator) enqueue(info Info) {
 	gen.queue.Add(keyHash)
 }
 
-//Add queues a policy violation create request
+//Add reports the results of the policies, and queues a policy violation create request for the violated rules
 func (gen *Generator) Add(infos ...Info) {
 	for _, info := range infos {
+		gen.reportGenerator.Add(info.toReportInfo())
+		if !gen.createViolations || len(info.Rules) == 0 {
+			continue
+		}
 		gen.enqueue(info)
 	}
 }
 
+//RemovePolicy removes the results of a deleted policy from the policy reports
+func (gen *Generator) RemovePolicy(policyKey string) {
+	gen.reportGenerator.RemovePolicy(policyKey)
+}
+
+//RemoveResource removes the results of a deleted resource from the policy reports
+func (gen *Generator) RemoveResource(resource corev1.ObjectReference) {
+	gen.reportGenerator.RemoveResource(resource)
+}
+
 // Run starts the workers
 func (gen *Generator) Run(workers int, stopCh <-chan struct{}) {
 	logger := gen.log
@@ -247,6 +293,13 @@ func (gen *Generator) syncHandler(info Info) error {
 		}
 	}
 
+	if info.PolicyNamespace != "" {
+		if pv.Annotations == nil {
+			pv.Annotations = map[string]string{}
+		}
+		pv.Annotations[kyverno.PolicyNamespaceAnnotation] = info.PolicyNamespace
+	}
+
 	// Create Policy Violations
 	logger.V(4).Info("creating policy violation", "key", info.toKey())
 	if err := handler.create(pv); err != nil {
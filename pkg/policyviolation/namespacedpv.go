@@ -73,8 +73,10 @@ func (nspv *namespacedPV) getExisting(newPv kyverno.PolicyViolation) (*kyverno.P
 	}
 
 	for _, pv := range pvs {
-		// find a policy on same resource and policy combination
+		// find a policy on same resource and policy combination, a cluster policy and
+		// a namespaced policy with the same name are distinguished by the policy namespace
 		if pv.Spec.Policy == newPv.Spec.Policy &&
+			pv.Annotations[kyverno.PolicyNamespaceAnnotation] == newPv.Annotations[kyverno.PolicyNamespaceAnnotation] &&
 			pv.Spec.ResourceSpec.Kind == newPv.Spec.ResourceSpec.Kind &&
 			pv.Spec.ResourceSpec.Name == newPv.Spec.ResourceSpec.Name {
 			return pv, nil
@@ -112,7 +114,7 @@ func (nspv *namespacedPV) createPV(newPv *kyverno.PolicyViolation) error {
 	}
 
 	if newPv.Annotations["fromSync"] != "true" {
-		nspv.policyStatusListener.Send(violationCount{policyName: newPv.Spec.Policy, violatedRules: newPv.Spec.ViolatedRules})
+		nspv.policyStatusListener.Send(violationCount{policyName: policyStatusKey(newPv), violatedRules: newPv.Spec.ViolatedRules})
 	}
 	logger.Info("namespaced policy violation created")
 	return nil
@@ -137,8 +139,13 @@ func (nspv *namespacedPV) updatePV(newPv, oldPv *kyverno.PolicyViolation) error
 	}
 
 	if newPv.Annotations["fromSync"] != "true" {
-		nspv.policyStatusListener.Send(violationCount{policyName: newPv.Spec.Policy, violatedRules: newPv.Spec.ViolatedRules})
+		nspv.policyStatusListener.Send(violationCount{policyName: policyStatusKey(newPv), violatedRules: newPv.Spec.ViolatedRules})
 	}
 	logger.Info("namespaced policy violation updated")
 	return nil
 }
+
+// policyStatusKey returns the key of the policy whose status is updated for the violation
+func policyStatusKey(pv *kyverno.PolicyViolation) string {
+	return kyverno.PolicyKey(pv.Annotations[kyverno.PolicyNamespaceAnnotation], pv.Spec.Policy)
+}
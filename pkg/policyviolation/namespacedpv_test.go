@@ -0,0 +1,63 @@
+package policyviolation
+
+import (
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	kyvernolister "github.com/nirmata/kyverno/pkg/client/listers/kyverno/v1"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func Test_GetExisting_SameNamePolicies(t *testing.T) {
+	resource := kyverno.ResourceSpec{Kind: "Pod", Namespace: "dev", Name: "nginx"}
+	newPV := func(name, policyNamespace string) kyverno.PolicyViolation {
+		pv := kyverno.PolicyViolation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "dev",
+				Labels:    map[string]string{"policy": "require-labels", "resource": resource.ToKey()},
+			},
+			Spec: kyverno.PolicyViolationSpec{
+				Policy:       "require-labels",
+				ResourceSpec: resource,
+			},
+		}
+		if policyNamespace != "" {
+			pv.Annotations = map[string]string{kyverno.PolicyNamespaceAnnotation: policyNamespace}
+		}
+		return pv
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	nspv := &namespacedPV{
+		nspvLister: kyvernolister.NewPolicyViolationLister(indexer),
+		log:        log.Log,
+	}
+
+	// only the violation of the cluster policy exists
+	clusterPV := newPV("require-labels-cluster", "")
+	assert.NilError(t, indexer.Add(&clusterPV))
+
+	existing, err := nspv.getExisting(newPV("", "dev"))
+	assert.NilError(t, err)
+	assert.Assert(t, existing == nil)
+
+	existing, err = nspv.getExisting(newPV("", ""))
+	assert.NilError(t, err)
+	assert.Equal(t, existing.Name, "require-labels-cluster")
+
+	// the violations of the cluster policy and the namespaced policy on the same resource
+	namespacedPV := newPV("require-labels-namespaced", "dev")
+	assert.NilError(t, indexer.Add(&namespacedPV))
+
+	existing, err = nspv.getExisting(newPV("", "dev"))
+	assert.NilError(t, err)
+	assert.Equal(t, existing.Name, "require-labels-namespaced")
+
+	existing, err = nspv.getExisting(newPV("", ""))
+	assert.NilError(t, err)
+	assert.Equal(t, existing.Name, "require-labels-cluster")
+}
@@ -5,6 +5,8 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
+	"unicode"
 
 	engineutils "github.com/nirmata/kyverno/pkg/engine/utils"
 	"k8s.io/api/admission/v1beta1"
@@ -16,6 +18,7 @@ import (
 	dclient "github.com/nirmata/kyverno/pkg/dclient"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+	listerv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 )
 
@@ -34,6 +37,43 @@ func ContainsNamepace(patterns []string, ns string) bool {
 	return contains(patterns, ns, compareNamespaces)
 }
 
+// ParseKindSelector splits a kind of a match or exclude block into group, version, kind and subresource.
+// Supported forms are Kind, version/Kind and group/version/Kind, each with an optional /subresource suffix.
+// The kind is the first part starting with an upper case letter, the last part otherwise, e.g. apps/v1/*.
+// Group and version default to *, the subresource is empty if not specified.
+func ParseKindSelector(selector string) (group, version, kind, subresource string) {
+	parts := strings.Split(selector, "/")
+	kindIndex := len(parts) - 1
+	for i, part := range parts {
+		if part != "" && unicode.IsUpper(rune(part[0])) {
+			kindIndex = i
+			break
+		}
+	}
+
+	group, version = "*", "*"
+	if kindIndex == 1 {
+		version = parts[0]
+	} else if kindIndex > 1 {
+		group, version = parts[kindIndex-2], parts[kindIndex-1]
+	}
+
+	kind = parts[kindIndex]
+	if kindIndex < len(parts)-1 {
+		subresource = strings.Join(parts[kindIndex+1:], "/")
+	}
+	return group, version, kind, subresource
+}
+
+// MatchKindSelector checks if the group, version, kind and subresource match the kind selector
+func MatchKindSelector(selector string, gvk schema.GroupVersionKind, subresource string) bool {
+	group, version, kind, sub := ParseKindSelector(selector)
+	return wildcard.Match(group, gvk.Group) &&
+		wildcard.Match(version, gvk.Version) &&
+		wildcard.Match(kind, gvk.Kind) &&
+		wildcard.Match(sub, subresource)
+}
+
 //ContainsString check if the string is contains in a list
 func ContainsString(list []string, element string) bool {
 	return contains(list, element, compareString)
@@ -76,7 +116,8 @@ func CRDInstalled(discovery client.IDiscovery, log logr.Logger) bool {
 		logger.Info("CRD found", "kind", kind)
 		return true
 	}
-	if !check("ClusterPolicy") || !check("ClusterPolicyViolation") || !check("PolicyViolation") {
+	if !check("ClusterPolicy") || !check("Policy") || !check("ClusterPolicyViolation") || !check("PolicyViolation") ||
+		!check("ClusterPolicyReport") || !check("PolicyReport") {
 		return false
 	}
 	return true
@@ -111,6 +152,11 @@ func ExtractResources(newRaw []byte, request *v1beta1.AdmissionRequest) (unstruc
 		if err != nil {
 			return emptyResource, emptyResource, fmt.Errorf("failed to convert new raw to unstructured: %v", err)
 		}
+
+		// the options of a CONNECT request, e.g. PodExecOptions, do not carry the name of the target resource
+		if request.Operation == v1beta1.Connect && newResource.GetName() == "" {
+			newResource.SetName(request.Name)
+		}
 	}
 
 	// Old Resource
@@ -189,3 +235,17 @@ func SliceContains(slice []string, values ...string) bool {
 
 	return false
 }
+
+// GetNamespaceLabels returns the labels of the namespace, cluster-wide resources have no namespace labels
+func GetNamespaceLabels(nsLister listerv1.NamespaceLister, namespace string, log logr.Logger) map[string]string {
+	if nsLister == nil || namespace == "" {
+		return nil
+	}
+
+	ns, err := nsLister.Get(namespace)
+	if err != nil {
+		log.Error(err, "failed to get namespace", "namespace", namespace)
+		return nil
+	}
+	return ns.GetLabels()
+}
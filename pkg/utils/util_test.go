@@ -67,3 +67,27 @@ func Test_containsNs(t *testing.T) {
 	assert.Assert(t, res == false)
 
 }
+
+func Test_ParseKindSelector(t *testing.T) {
+	testcases := []struct {
+		selector                          string
+		group, version, kind, subresource string
+	}{
+		{selector: "Pod", group: "*", version: "*", kind: "Pod"},
+		{selector: "*", group: "*", version: "*", kind: "*"},
+		{selector: "v1/Pod", group: "*", version: "v1", kind: "Pod"},
+		{selector: "apps/v1/Deployment", group: "apps", version: "v1", kind: "Deployment"},
+		{selector: "apps/*/Deployment", group: "apps", version: "*", kind: "Deployment"},
+		{selector: "apps/v1/*", group: "apps", version: "v1", kind: "*"},
+		{selector: "Pod/exec", group: "*", version: "*", kind: "Pod", subresource: "exec"},
+		{selector: "apps/v1/Deployment/scale", group: "apps", version: "v1", kind: "Deployment", subresource: "scale"},
+	}
+
+	for _, tc := range testcases {
+		group, version, kind, subresource := ParseKindSelector(tc.selector)
+		assert.Equal(t, group, tc.group, tc.selector)
+		assert.Equal(t, version, tc.version, tc.selector)
+		assert.Equal(t, kind, tc.kind, tc.selector)
+		assert.Equal(t, subresource, tc.subresource, tc.selector)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/nirmata/kyverno/pkg/config"
+	"github.com/nirmata/kyverno/pkg/metrics"
 	admregapi "k8s.io/api/admissionregistration/v1beta1"
 	errorsapi "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,7 +26,7 @@ func (wrc *WebhookRegistrationClient) constructVerifyMutatingWebhookConfig(caDat
 				caData,
 				true,
 				wrc.timeoutSeconds,
-				"deployments/*",
+				[]string{"deployments/*"},
 				"apps",
 				"v1",
 				[]admregapi.OperationType{admregapi.Update},
@@ -49,7 +50,7 @@ func (wrc *WebhookRegistrationClient) constructDebugVerifyMutatingWebhookConfig(
 				caData,
 				true,
 				wrc.timeoutSeconds,
-				"deployments/*",
+				[]string{"deployments/*"},
 				"apps",
 				"v1",
 				[]admregapi.OperationType{admregapi.Update},
@@ -70,9 +71,10 @@ func (wrc *WebhookRegistrationClient) removeVerifyWebhookMutatingWebhookConfig(w
 	}
 
 	logger := wrc.log.WithValues("name", mutatingConfig)
-	err = wrc.client.DeleteResource("", MutatingWebhookConfigurationKind, "", mutatingConfig, false)
+	err = wrc.client.DeleteResource(wrc.webhookConfigAPIVersion(), MutatingWebhookConfigurationKind, "", mutatingConfig, false)
 	if errorsapi.IsNotFound(err) {
 		logger.V(5).Info("verify webhook configuration not found")
+		metrics.SetWebhookRegistered(mutatingConfig, false)
 		return
 	}
 
@@ -80,6 +82,7 @@ func (wrc *WebhookRegistrationClient) removeVerifyWebhookMutatingWebhookConfig(w
 		logger.Error(err, "failed to delete verify wwebhook configuration")
 		return
 	}
+	metrics.SetWebhookRegistered(mutatingConfig, false)
 
 	logger.V(4).Info("successfully deleted verify webhook configuration")
 }
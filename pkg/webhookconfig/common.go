@@ -9,6 +9,24 @@ import (
 	rest "k8s.io/client-go/rest"
 )
 
+const (
+	admissionRegistrationV1      = "admissionregistration.k8s.io/v1"
+	admissionRegistrationV1beta1 = "admissionregistration.k8s.io/v1beta1"
+)
+
+// admissionReviewVersions lists the AdmissionReview versions served by the webhooks,
+// the API server sends the first version in the list it supports
+var admissionReviewVersions = []string{"v1", "v1beta1"}
+
+// webhookConfigAPIVersion returns the API version the webhook configurations are registered with,
+// admissionregistration.k8s.io/v1 is used if the API server supports it
+func (wrc *WebhookRegistrationClient) webhookConfigAPIVersion() string {
+	if _, _, err := wrc.client.DiscoveryClient.FindResource(admissionRegistrationV1, MutatingWebhookConfigurationKind); err == nil {
+		return admissionRegistrationV1
+	}
+	return admissionRegistrationV1beta1
+}
+
 func (wrc *WebhookRegistrationClient) readCaData() []byte {
 	logger := wrc.log
 	var caData []byte
@@ -63,7 +81,7 @@ func (wrc *WebhookRegistrationClient) constructOwner() v1.OwnerReference {
 }
 
 // debug mutating webhook
-func generateDebugMutatingWebhook(name, url string, caData []byte, validate bool, timeoutSeconds int32, resource, apiGroups, apiVersions string, operationTypes []admregapi.OperationType) admregapi.MutatingWebhook {
+func generateDebugMutatingWebhook(name, url string, caData []byte, validate bool, timeoutSeconds int32, resources []string, apiGroups, apiVersions string, operationTypes []admregapi.OperationType) admregapi.MutatingWebhook {
 	sideEffect := admregapi.SideEffectClassNoneOnDryRun
 	failurePolicy := admregapi.Ignore
 	reinvocationPolicy := admregapi.NeverReinvocationPolicy
@@ -86,19 +104,17 @@ func generateDebugMutatingWebhook(name, url string, caData []byte, validate bool
 					APIVersions: []string{
 						apiVersions,
 					},
-					Resources: []string{
-						resource,
-					},
+					Resources: resources,
 				},
 			},
 		},
-		AdmissionReviewVersions: []string{"v1beta1"},
+		AdmissionReviewVersions: admissionReviewVersions,
 		TimeoutSeconds:          &timeoutSeconds,
 		FailurePolicy:           &failurePolicy,
 	}
 }
 
-func generateDebugValidatingWebhook(name, url string, caData []byte, validate bool, timeoutSeconds int32, resource, apiGroups, apiVersions string, operationTypes []admregapi.OperationType) admregapi.ValidatingWebhook {
+func generateDebugValidatingWebhook(name, url string, caData []byte, validate bool, timeoutSeconds int32, resources []string, apiGroups, apiVersions string, operationTypes []admregapi.OperationType) admregapi.ValidatingWebhook {
 	sideEffect := admregapi.SideEffectClassNoneOnDryRun
 	failurePolicy := admregapi.Ignore
 	return admregapi.ValidatingWebhook{
@@ -118,13 +134,11 @@ func generateDebugValidatingWebhook(name, url string, caData []byte, validate bo
 					APIVersions: []string{
 						apiVersions,
 					},
-					Resources: []string{
-						resource,
-					},
+					Resources: resources,
 				},
 			},
 		},
-		AdmissionReviewVersions: []string{"v1beta1"},
+		AdmissionReviewVersions: admissionReviewVersions,
 		TimeoutSeconds:          &timeoutSeconds,
 		FailurePolicy:           &failurePolicy,
 	}
@@ -167,7 +181,7 @@ func generateDebugValidatingWebhook(name, url string, caData []byte, validate bo
 // }
 
 // mutating webhook
-func generateMutatingWebhook(name, servicePath string, caData []byte, validation bool, timeoutSeconds int32, resource, apiGroups, apiVersions string, operationTypes []admregapi.OperationType) admregapi.MutatingWebhook {
+func generateMutatingWebhook(name, servicePath string, caData []byte, validation bool, timeoutSeconds int32, resources []string, apiGroups, apiVersions string, operationTypes []admregapi.OperationType) admregapi.MutatingWebhook {
 	sideEffect := admregapi.SideEffectClassNoneOnDryRun
 	failurePolicy := admregapi.Ignore
 	reinvocationPolicy := admregapi.NeverReinvocationPolicy
@@ -194,20 +208,18 @@ func generateMutatingWebhook(name, servicePath string, caData []byte, validation
 					APIVersions: []string{
 						apiVersions,
 					},
-					Resources: []string{
-						resource,
-					},
+					Resources: resources,
 				},
 			},
 		},
-		AdmissionReviewVersions: []string{"v1beta1"},
+		AdmissionReviewVersions: admissionReviewVersions,
 		TimeoutSeconds:          &timeoutSeconds,
 		FailurePolicy:           &failurePolicy,
 	}
 }
 
 // validating webhook
-func generateValidatingWebhook(name, servicePath string, caData []byte, validation bool, timeoutSeconds int32, resource, apiGroups, apiVersions string, operationTypes []admregapi.OperationType) admregapi.ValidatingWebhook {
+func generateValidatingWebhook(name, servicePath string, caData []byte, validation bool, timeoutSeconds int32, resources []string, apiGroups, apiVersions string, operationTypes []admregapi.OperationType) admregapi.ValidatingWebhook {
 	sideEffect := admregapi.SideEffectClassNoneOnDryRun
 	failurePolicy := admregapi.Ignore
 	return admregapi.ValidatingWebhook{
@@ -231,13 +243,11 @@ func generateValidatingWebhook(name, servicePath string, caData []byte, validati
 					APIVersions: []string{
 						apiVersions,
 					},
-					Resources: []string{
-						resource,
-					},
+					Resources: resources,
 				},
 			},
 		},
-		AdmissionReviewVersions: []string{"v1beta1"},
+		AdmissionReviewVersions: admissionReviewVersions,
 		TimeoutSeconds:          &timeoutSeconds,
 		FailurePolicy:           &failurePolicy,
 	}
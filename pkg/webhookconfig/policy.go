@@ -24,7 +24,7 @@ func (wrc *WebhookRegistrationClient) contructPolicyValidatingWebhookConfig(caDa
 				caData,
 				true,
 				wrc.timeoutSeconds,
-				"clusterpolicies/*",
+				[]string{"clusterpolicies/*", "policies/*"},
 				"kyverno.io",
 				"v1",
 				[]admregapi.OperationType{admregapi.Create, admregapi.Update},
@@ -49,7 +49,7 @@ func (wrc *WebhookRegistrationClient) contructDebugPolicyValidatingWebhookConfig
 				caData,
 				true,
 				wrc.timeoutSeconds,
-				"clusterpolicies/*",
+				[]string{"clusterpolicies/*", "policies/*"},
 				"kyverno.io",
 				"v1",
 				[]admregapi.OperationType{admregapi.Create, admregapi.Update},
@@ -73,7 +73,7 @@ func (wrc *WebhookRegistrationClient) contructPolicyMutatingWebhookConfig(caData
 				caData,
 				true,
 				wrc.timeoutSeconds,
-				"clusterpolicies/*",
+				[]string{"clusterpolicies/*", "policies/*"},
 				"kyverno.io",
 				"v1",
 				[]admregapi.OperationType{admregapi.Create, admregapi.Update},
@@ -97,7 +97,7 @@ func (wrc *WebhookRegistrationClient) contructDebugPolicyMutatingWebhookConfig(c
 				caData,
 				true,
 				wrc.timeoutSeconds,
-				"clusterpolicies/*",
+				[]string{"clusterpolicies/*", "policies/*"},
 				"kyverno.io",
 				"v1",
 				[]admregapi.OperationType{admregapi.Create, admregapi.Update},
@@ -9,8 +9,10 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/nirmata/kyverno/pkg/config"
 	client "github.com/nirmata/kyverno/pkg/dclient"
+	"github.com/nirmata/kyverno/pkg/metrics"
 	admregapi "k8s.io/api/admissionregistration/v1beta1"
 	errorsapi "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	rest "k8s.io/client-go/rest"
 )
 
@@ -89,10 +91,10 @@ func (wrc *WebhookRegistrationClient) RemoveWebhookConfigurations(cleanUp chan<-
 	close(cleanUp)
 }
 
-//CreateResourceMutatingWebhookConfiguration create a Mutatingwebhookconfiguration resource for all resource type
+//CreateResourceMutatingWebhookConfiguration create a Mutatingwebhookconfiguration resource for the resources matched by the rules
 // used to forward request to kyverno webhooks to apply policeis
 // Mutationg webhook is be used for Mutating purpose
-func (wrc *WebhookRegistrationClient) CreateResourceMutatingWebhookConfiguration() error {
+func (wrc *WebhookRegistrationClient) CreateResourceMutatingWebhookConfiguration(rules []admregapi.RuleWithOperations, namespaceSelector *v1.LabelSelector) error {
 	logger := wrc.log
 	var caData []byte
 	var config *admregapi.MutatingWebhookConfiguration
@@ -107,25 +109,27 @@ func (wrc *WebhookRegistrationClient) CreateResourceMutatingWebhookConfiguration
 	if wrc.serverIP != "" {
 		// debug mode
 		// clientConfig - URL
-		config = wrc.constructDebugMutatingWebhookConfig(caData)
+		config = wrc.constructDebugMutatingWebhookConfig(caData, rules, namespaceSelector)
 	} else {
 		// clientConfig - service
-		config = wrc.constructMutatingWebhookConfig(caData)
+		config = wrc.constructMutatingWebhookConfig(caData, rules, namespaceSelector)
 	}
-	_, err := wrc.client.CreateResource("", MutatingWebhookConfigurationKind, "", *config, false)
+	_, err := wrc.client.CreateResource(wrc.webhookConfigAPIVersion(), MutatingWebhookConfigurationKind, "", *config, false)
 	if errorsapi.IsAlreadyExists(err) {
 		logger.V(6).Info("resource mutating webhook configuration already exists", "name", config.Name)
+		metrics.SetWebhookRegistered(config.Name, true)
 		return nil
 	}
 	if err != nil {
 		logger.Error(err, "failed to create resource mutating webhook configuration", "name", config.Name)
 		return err
 	}
+	metrics.SetWebhookRegistered(config.Name, true)
 	return nil
 }
 
-//CreateResourceValidatingWebhookConfiguration ...
-func (wrc *WebhookRegistrationClient) CreateResourceValidatingWebhookConfiguration() error {
+//CreateResourceValidatingWebhookConfiguration create a Validatingwebhookconfiguration resource for the resources matched by the rules
+func (wrc *WebhookRegistrationClient) CreateResourceValidatingWebhookConfiguration(rules []admregapi.RuleWithOperations, namespaceSelector *v1.LabelSelector) error {
 	var caData []byte
 	var config *admregapi.ValidatingWebhookConfiguration
 
@@ -136,22 +140,54 @@ func (wrc *WebhookRegistrationClient) CreateResourceValidatingWebhookConfigurati
 	if wrc.serverIP != "" {
 		// debug mode
 		// clientConfig - URL
-		config = wrc.constructDebugValidatingWebhookConfig(caData)
+		config = wrc.constructDebugValidatingWebhookConfig(caData, rules, namespaceSelector)
 	} else {
 		// clientConfig - service
-		config = wrc.constructValidatingWebhookConfig(caData)
+		config = wrc.constructValidatingWebhookConfig(caData, rules, namespaceSelector)
 	}
 	logger := wrc.log.WithValues("kind", ValidatingWebhookConfigurationKind, "name", config.Name)
 
-	_, err := wrc.client.CreateResource("", ValidatingWebhookConfigurationKind, "", *config, false)
+	_, err := wrc.client.CreateResource(wrc.webhookConfigAPIVersion(), ValidatingWebhookConfigurationKind, "", *config, false)
 	if errorsapi.IsAlreadyExists(err) {
 		logger.V(6).Info("resource validating webhook configuration already exists", "name", config.Name)
+		metrics.SetWebhookRegistered(config.Name, true)
 		return nil
 	}
 	if err != nil {
 		logger.Error(err, "failed to create resource")
 		return err
 	}
+	metrics.SetWebhookRegistered(config.Name, true)
+	return nil
+}
+
+//UpdateResourceMutatingWebhookConfiguration updates the rules of the resource mutating webhook configuration
+func (wrc *WebhookRegistrationClient) UpdateResourceMutatingWebhookConfiguration(existing *admregapi.MutatingWebhookConfiguration, rules []admregapi.RuleWithOperations, namespaceSelector *v1.LabelSelector) error {
+	config := existing.DeepCopy()
+	for i := range config.Webhooks {
+		config.Webhooks[i].Rules = rules
+		config.Webhooks[i].NamespaceSelector = namespaceSelector
+	}
+
+	if _, err := wrc.client.UpdateResource(wrc.webhookConfigAPIVersion(), MutatingWebhookConfigurationKind, "", *config, false); err != nil {
+		wrc.log.Error(err, "failed to update resource mutating webhook configuration", "name", config.Name)
+		return err
+	}
+	return nil
+}
+
+//UpdateResourceValidatingWebhookConfiguration updates the rules of the resource validating webhook configuration
+func (wrc *WebhookRegistrationClient) UpdateResourceValidatingWebhookConfiguration(existing *admregapi.ValidatingWebhookConfiguration, rules []admregapi.RuleWithOperations, namespaceSelector *v1.LabelSelector) error {
+	config := existing.DeepCopy()
+	for i := range config.Webhooks {
+		config.Webhooks[i].Rules = rules
+		config.Webhooks[i].NamespaceSelector = namespaceSelector
+	}
+
+	if _, err := wrc.client.UpdateResource(wrc.webhookConfigAPIVersion(), ValidatingWebhookConfigurationKind, "", *config, false); err != nil {
+		wrc.log.Error(err, "failed to update resource validating webhook configuration", "name", config.Name)
+		return err
+	}
 	return nil
 }
 
@@ -179,9 +215,10 @@ func (wrc *WebhookRegistrationClient) createPolicyValidatingWebhookConfiguration
 	logger := wrc.log.WithValues("kind", ValidatingWebhookConfigurationKind, "name", config.Name)
 
 	// create validating webhook configuration resource
-	if _, err := wrc.client.CreateResource("", ValidatingWebhookConfigurationKind, "", *config, false); err != nil {
+	if _, err := wrc.client.CreateResource(wrc.webhookConfigAPIVersion(), ValidatingWebhookConfigurationKind, "", *config, false); err != nil {
 		return err
 	}
+	metrics.SetWebhookRegistered(config.Name, true)
 	logger.V(4).Info("created resource")
 	return nil
 }
@@ -207,9 +244,10 @@ func (wrc *WebhookRegistrationClient) createPolicyMutatingWebhookConfiguration()
 	}
 
 	// create mutating webhook configuration resource
-	if _, err := wrc.client.CreateResource("", MutatingWebhookConfigurationKind, "", *config, false); err != nil {
+	if _, err := wrc.client.CreateResource(wrc.webhookConfigAPIVersion(), MutatingWebhookConfigurationKind, "", *config, false); err != nil {
 		return err
 	}
+	metrics.SetWebhookRegistered(config.Name, true)
 	wrc.log.V(4).Info("reated Mutating Webhook Configuration", "name", config.Name)
 	return nil
 }
@@ -236,9 +274,10 @@ func (wrc *WebhookRegistrationClient) createVerifyMutatingWebhookConfiguration()
 	}
 
 	// create mutating webhook configuration resource
-	if _, err := wrc.client.CreateResource("", MutatingWebhookConfigurationKind, "", *config, false); err != nil {
+	if _, err := wrc.client.CreateResource(wrc.webhookConfigAPIVersion(), MutatingWebhookConfigurationKind, "", *config, false); err != nil {
 		return err
 	}
+	metrics.SetWebhookRegistered(config.Name, true)
 
 	wrc.log.V(4).Info("reated Mutating Webhook Configuration", "name", config.Name)
 	return nil
@@ -295,9 +334,10 @@ func (wrc *WebhookRegistrationClient) removePolicyMutatingWebhookConfiguration(w
 	}
 
 	logger := wrc.log.WithValues("name", mutatingConfig)
-	err := wrc.client.DeleteResource("", MutatingWebhookConfigurationKind, "", mutatingConfig, false)
+	err := wrc.client.DeleteResource(wrc.webhookConfigAPIVersion(), MutatingWebhookConfigurationKind, "", mutatingConfig, false)
 	if errorsapi.IsNotFound(err) {
 		logger.V(5).Info("policy mutating webhook configuration not found")
+		metrics.SetWebhookRegistered(mutatingConfig, false)
 		return
 	}
 
@@ -305,6 +345,7 @@ func (wrc *WebhookRegistrationClient) removePolicyMutatingWebhookConfiguration(w
 		logger.Error(err, "failed to delete policy mutating webhook configuration")
 		return
 	}
+	metrics.SetWebhookRegistered(mutatingConfig, false)
 
 	logger.V(4).Info("successfully deleted policy mutating webhook configutation")
 }
@@ -321,9 +362,10 @@ func (wrc *WebhookRegistrationClient) removePolicyValidatingWebhookConfiguration
 
 	logger := wrc.log.WithValues("name", validatingConfig)
 	logger.V(4).Info("removing validating webhook configuration")
-	err := wrc.client.DeleteResource("", ValidatingWebhookConfigurationKind, "", validatingConfig, false)
+	err := wrc.client.DeleteResource(wrc.webhookConfigAPIVersion(), ValidatingWebhookConfigurationKind, "", validatingConfig, false)
 	if errorsapi.IsNotFound(err) {
 		logger.V(5).Info("policy validating webhook configuration not found")
+		metrics.SetWebhookRegistered(validatingConfig, false)
 		return
 	}
 
@@ -331,11 +373,12 @@ func (wrc *WebhookRegistrationClient) removePolicyValidatingWebhookConfiguration
 		logger.Error(err, "failed to delete policy validating webhook configuration")
 		return
 	}
+	metrics.SetWebhookRegistered(validatingConfig, false)
 
 	logger.V(4).Info("successfully deleted policy validating webhook configutation")
 }
 
 // GetWebhookTimeOut returns the value of webhook timeout
 func (wrc *WebhookRegistrationClient) GetWebhookTimeOut() time.Duration {
-	return time.Duration(wrc.timeoutSeconds)
+	return time.Duration(wrc.timeoutSeconds) * time.Second
 }
@@ -4,16 +4,25 @@ import (
 	"fmt"
 
 	"github.com/nirmata/kyverno/pkg/config"
+	"github.com/nirmata/kyverno/pkg/metrics"
 	admregapi "k8s.io/api/admissionregistration/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
-func (wrc *WebhookRegistrationClient) constructDebugMutatingWebhookConfig(caData []byte) *admregapi.MutatingWebhookConfiguration {
+var (
+	// mutatingWebhookOperations are the operations sent to the resource mutating webhook
+	mutatingWebhookOperations = []admregapi.OperationType{admregapi.Create, admregapi.Update}
+	// validatingWebhookOperations are the operations sent to the resource validating webhook
+	validatingWebhookOperations = []admregapi.OperationType{admregapi.Create, admregapi.Update, admregapi.Delete, admregapi.Connect}
+)
+
+func (wrc *WebhookRegistrationClient) constructDebugMutatingWebhookConfig(caData []byte, rules []admregapi.RuleWithOperations, namespaceSelector *v1.LabelSelector) *admregapi.MutatingWebhookConfiguration {
 	logger := wrc.log
 	url := fmt.Sprintf("https://%s%s", wrc.serverIP, config.MutatingWebhookServicePath)
 	logger.V(4).Info("Debug MutatingWebhookConfig registered", "url", url)
-	return &admregapi.MutatingWebhookConfiguration{
+	webhookConfig := &admregapi.MutatingWebhookConfiguration{
 		ObjectMeta: v1.ObjectMeta{
 			Name: config.MutatingWebhookConfigurationDebugName,
 		},
@@ -24,17 +33,20 @@ func (wrc *WebhookRegistrationClient) constructDebugMutatingWebhookConfig(caData
 				caData,
 				true,
 				wrc.timeoutSeconds,
-				"*/*",
+				[]string{"*/*"},
 				"*",
 				"*",
-				[]admregapi.OperationType{admregapi.Create, admregapi.Update},
+				mutatingWebhookOperations,
 			),
 		},
 	}
+	webhookConfig.Webhooks[0].Rules = rules
+	webhookConfig.Webhooks[0].NamespaceSelector = namespaceSelector
+	return webhookConfig
 }
 
-func (wrc *WebhookRegistrationClient) constructMutatingWebhookConfig(caData []byte) *admregapi.MutatingWebhookConfiguration {
-	return &admregapi.MutatingWebhookConfiguration{
+func (wrc *WebhookRegistrationClient) constructMutatingWebhookConfig(caData []byte, rules []admregapi.RuleWithOperations, namespaceSelector *v1.LabelSelector) *admregapi.MutatingWebhookConfiguration {
+	webhookConfig := &admregapi.MutatingWebhookConfiguration{
 		ObjectMeta: v1.ObjectMeta{
 			Name: config.MutatingWebhookConfigurationName,
 			OwnerReferences: []v1.OwnerReference{
@@ -48,13 +60,16 @@ func (wrc *WebhookRegistrationClient) constructMutatingWebhookConfig(caData []by
 				caData,
 				false,
 				wrc.timeoutSeconds,
-				"*/*",
+				[]string{"*/*"},
 				"*",
 				"*",
-				[]admregapi.OperationType{admregapi.Create, admregapi.Update},
+				mutatingWebhookOperations,
 			),
 		},
 	}
+	webhookConfig.Webhooks[0].Rules = rules
+	webhookConfig.Webhooks[0].NamespaceSelector = namespaceSelector
+	return webhookConfig
 }
 
 //GetResourceMutatingWebhookConfigName returns the webhook configuration name
@@ -65,14 +80,34 @@ func (wrc *WebhookRegistrationClient) GetResourceMutatingWebhookConfigName() str
 	return config.MutatingWebhookConfigurationName
 }
 
+// getResourceMutatingWebhookConfiguration returns the resource mutating webhook configuration, or nil if it is not registered.
+// It is read in the API version the configurations are registered with, as the API server may not serve v1beta1.
+func (wrc *WebhookRegistrationClient) getResourceMutatingWebhookConfiguration() (*admregapi.MutatingWebhookConfiguration, error) {
+	obj, err := wrc.client.GetResource(wrc.webhookConfigAPIVersion(), MutatingWebhookConfigurationKind, "", wrc.GetResourceMutatingWebhookConfigName())
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the webhook fields used by Kyverno are the same in v1 and v1beta1
+	webhookConfig := &admregapi.MutatingWebhookConfiguration{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), webhookConfig); err != nil {
+		return nil, err
+	}
+	return webhookConfig, nil
+}
+
 //RemoveResourceMutatingWebhookConfiguration removes mutating webhook configuration for all resources
 func (wrc *WebhookRegistrationClient) RemoveResourceMutatingWebhookConfiguration() {
 	configName := wrc.GetResourceMutatingWebhookConfigName()
 	logger := wrc.log.WithValues("kind", MutatingWebhookConfigurationKind, "name", configName)
 	// delete webhook configuration
-	err := wrc.client.DeleteResource("", MutatingWebhookConfigurationKind, "", configName, false)
+	err := wrc.client.DeleteResource(wrc.webhookConfigAPIVersion(), MutatingWebhookConfigurationKind, "", configName, false)
 	if errors.IsNotFound(err) {
 		logger.V(4).Info("webhook configuration not found")
+		metrics.SetWebhookRegistered(configName, false)
 		return
 	}
 
@@ -80,14 +115,15 @@ func (wrc *WebhookRegistrationClient) RemoveResourceMutatingWebhookConfiguration
 		logger.Error(err, "failed to delete the mutating webhook configuration")
 		return
 	}
+	metrics.SetWebhookRegistered(configName, false)
 
 	logger.Info("mutating webhook configuration deleted")
 }
 
-func (wrc *WebhookRegistrationClient) constructDebugValidatingWebhookConfig(caData []byte) *admregapi.ValidatingWebhookConfiguration {
+func (wrc *WebhookRegistrationClient) constructDebugValidatingWebhookConfig(caData []byte, rules []admregapi.RuleWithOperations, namespaceSelector *v1.LabelSelector) *admregapi.ValidatingWebhookConfiguration {
 	url := fmt.Sprintf("https://%s%s", wrc.serverIP, config.ValidatingWebhookServicePath)
 
-	return &admregapi.ValidatingWebhookConfiguration{
+	webhookConfig := &admregapi.ValidatingWebhookConfiguration{
 		ObjectMeta: v1.ObjectMeta{
 			Name: config.ValidatingWebhookConfigurationDebugName,
 		},
@@ -98,17 +134,20 @@ func (wrc *WebhookRegistrationClient) constructDebugValidatingWebhookConfig(caDa
 				caData,
 				true,
 				wrc.timeoutSeconds,
-				"*/*",
+				[]string{"*/*"},
 				"*",
 				"*",
-				[]admregapi.OperationType{admregapi.Create, admregapi.Update, admregapi.Delete},
+				validatingWebhookOperations,
 			),
 		},
 	}
+	webhookConfig.Webhooks[0].Rules = rules
+	webhookConfig.Webhooks[0].NamespaceSelector = namespaceSelector
+	return webhookConfig
 }
 
-func (wrc *WebhookRegistrationClient) constructValidatingWebhookConfig(caData []byte) *admregapi.ValidatingWebhookConfiguration {
-	return &admregapi.ValidatingWebhookConfiguration{
+func (wrc *WebhookRegistrationClient) constructValidatingWebhookConfig(caData []byte, rules []admregapi.RuleWithOperations, namespaceSelector *v1.LabelSelector) *admregapi.ValidatingWebhookConfiguration {
+	webhookConfig := &admregapi.ValidatingWebhookConfiguration{
 		ObjectMeta: v1.ObjectMeta{
 			Name: config.ValidatingWebhookConfigurationName,
 			OwnerReferences: []v1.OwnerReference{
@@ -122,13 +161,16 @@ func (wrc *WebhookRegistrationClient) constructValidatingWebhookConfig(caData []
 				caData,
 				false,
 				wrc.timeoutSeconds,
-				"*/*",
+				[]string{"*/*"},
 				"*",
 				"*",
-				[]admregapi.OperationType{admregapi.Create, admregapi.Update, admregapi.Delete},
+				validatingWebhookOperations,
 			),
 		},
 	}
+	webhookConfig.Webhooks[0].Rules = rules
+	webhookConfig.Webhooks[0].NamespaceSelector = namespaceSelector
+	return webhookConfig
 }
 
 // GetResourceValidatingWebhookConfigName returns the webhook configuration name
@@ -140,13 +182,31 @@ func (wrc *WebhookRegistrationClient) GetResourceValidatingWebhookConfigName() s
 	return config.ValidatingWebhookConfigurationName
 }
 
+// getResourceValidatingWebhookConfiguration returns the resource validating webhook configuration, or nil if it is not registered
+func (wrc *WebhookRegistrationClient) getResourceValidatingWebhookConfiguration() (*admregapi.ValidatingWebhookConfiguration, error) {
+	obj, err := wrc.client.GetResource(wrc.webhookConfigAPIVersion(), ValidatingWebhookConfigurationKind, "", wrc.GetResourceValidatingWebhookConfigName())
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	webhookConfig := &admregapi.ValidatingWebhookConfiguration{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), webhookConfig); err != nil {
+		return nil, err
+	}
+	return webhookConfig, nil
+}
+
 // RemoveResourceValidatingWebhookConfiguration deletes an existing webhook configuration
 func (wrc *WebhookRegistrationClient) RemoveResourceValidatingWebhookConfiguration() {
 	configName := wrc.GetResourceValidatingWebhookConfigName()
 	logger := wrc.log.WithValues("kind", ValidatingWebhookConfigurationKind, "name", configName)
-	err := wrc.client.DeleteResource("", ValidatingWebhookConfigurationKind, "", configName, false)
+	err := wrc.client.DeleteResource(wrc.webhookConfigAPIVersion(), ValidatingWebhookConfigurationKind, "", configName, false)
 	if errors.IsNotFound(err) {
 		logger.V(5).Info("webhook configuration not found")
+		metrics.SetWebhookRegistered(configName, false)
 		return
 	}
 
@@ -154,6 +214,7 @@ func (wrc *WebhookRegistrationClient) RemoveResourceValidatingWebhookConfigurati
 		logger.Error(err, "failed to delete the validating webhook configuration")
 		return
 	}
+	metrics.SetWebhookRegistered(configName, false)
 
 	logger.Info("validating webhook configuration deleted")
 	return
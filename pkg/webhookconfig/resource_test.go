@@ -0,0 +1,67 @@
+package webhookconfig
+
+import (
+	"testing"
+
+	"github.com/nirmata/kyverno/pkg/config"
+	client "github.com/nirmata/kyverno/pkg/dclient"
+	"gotest.tools/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// v1Discovery reports that the API server supports admissionregistration.k8s.io/v1
+type v1Discovery struct {
+	client.IDiscovery
+}
+
+func (d v1Discovery) FindResource(apiVersion string, kind string) (*metav1.APIResource, schema.GroupVersionResource, error) {
+	return &metav1.APIResource{Kind: kind}, schema.GroupVersionResource{}, nil
+}
+
+func Test_GetResourceWebhookConfiguration_V1(t *testing.T) {
+	mutatingConfig := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": admissionRegistrationV1,
+		"kind":       MutatingWebhookConfigurationKind,
+		"metadata":   map[string]interface{}{"name": config.MutatingWebhookConfigurationName},
+		"webhooks": []interface{}{
+			map[string]interface{}{
+				"name":                    "mutate.kyverno.svc",
+				"admissionReviewVersions": []interface{}{"v1", "v1beta1"},
+				"sideEffects":             "None",
+				"rules": []interface{}{
+					map[string]interface{}{
+						"apiGroups":   []interface{}{"*"},
+						"apiVersions": []interface{}{"*"},
+						"resources":   []interface{}{"pods"},
+						"operations":  []interface{}{"CREATE"},
+					},
+				},
+			},
+		},
+	}}
+
+	dclient, err := client.NewMockClient(runtime.NewScheme(), mutatingConfig)
+	assert.NilError(t, err)
+	dclient.SetDiscovery(v1Discovery{
+		IDiscovery: client.NewFakeDiscoveryClient([]schema.GroupVersionResource{
+			{Group: "admissionregistration.k8s.io", Version: "v1", Resource: "mutatingwebhookconfigurations"},
+			{Group: "admissionregistration.k8s.io", Version: "v1", Resource: "validatingwebhookconfigurations"},
+		}),
+	})
+	wrc := &WebhookRegistrationClient{client: dclient, log: log.Log}
+
+	mutating, err := wrc.getResourceMutatingWebhookConfiguration()
+	assert.NilError(t, err)
+	assert.Equal(t, mutating.APIVersion, admissionRegistrationV1)
+	assert.Equal(t, len(mutating.Webhooks), 1)
+	assert.DeepEqual(t, mutating.Webhooks[0].Rules[0].Resources, []string{"pods"})
+
+	// the validating webhook configuration is not registered
+	validating, err := wrc.getResourceValidatingWebhookConfiguration()
+	assert.NilError(t, err)
+	assert.Assert(t, validating == nil)
+}
@@ -0,0 +1,306 @@
+package webhookconfig
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/utils"
+	admregapi "k8s.io/api/admissionregistration/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// namespaceNameLabel is set by the API server on all namespaces from Kubernetes 1.21
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+// matchDescription is a resource description of a policy rule, the webhook
+// must receive the requests for its kinds, operations and namespaces
+type matchDescription struct {
+	kinds      []string
+	operations []kyverno.AdmissionOperation
+	namespaces []string
+}
+
+// policyDescriptions returns the match descriptions of the policy rules accepted by the filter
+func policyDescriptions(policies []*kyverno.ClusterPolicy, filter func(kyverno.Rule) bool) []matchDescription {
+	var descriptions []matchDescription
+	for _, policy := range policies {
+		for _, rule := range policy.Spec.Rules {
+			if !filter(rule) {
+				continue
+			}
+
+			for _, description := range matchDescriptions(rule) {
+				// namespaced policies only apply to their namespace
+				if policy.GetNamespace() != "" {
+					description.namespaces = []string{policy.GetNamespace()}
+				}
+				descriptions = append(descriptions, description)
+			}
+		}
+	}
+
+	return descriptions
+}
+
+// resourceWebhookRules returns the webhook rules and namespace selector for the requests matched by the
+// descriptions, the operations of the rules are limited to the webhook operations
+func (wrc *WebhookRegistrationClient) resourceWebhookRules(descriptions []matchDescription, operations []admregapi.OperationType) ([]admregapi.RuleWithOperations, *v1.LabelSelector) {
+	if len(descriptions) == 0 {
+		return nil, nil
+	}
+
+	return wrc.buildRules(descriptions, operations), wrc.buildNamespaceSelector(descriptions)
+}
+
+// generatedResourceDescriptions returns the descriptions of the resources created by the generate rules,
+// the validating webhook must receive their updates and deletions to protect synchronized resources
+func generatedResourceDescriptions(policies []*kyverno.ClusterPolicy) []matchDescription {
+	var descriptions []matchDescription
+	for _, policy := range policies {
+		for _, rule := range policy.Spec.Rules {
+			if !rule.HasGenerate() {
+				continue
+			}
+
+			kind := rule.Generation.Kind
+			if rule.Generation.APIVersion != "" {
+				kind = rule.Generation.APIVersion + "/" + kind
+			}
+
+			description := matchDescription{
+				kinds:      []string{kind},
+				operations: []kyverno.AdmissionOperation{kyverno.Update, kyverno.Delete},
+			}
+			// the namespace may be a variable resolved from the trigger
+			if ns := rule.Generation.Namespace; ns != "" && !strings.Contains(ns, "{{") {
+				description.namespaces = []string{ns}
+			}
+			descriptions = append(descriptions, description)
+		}
+	}
+
+	return descriptions
+}
+
+// matchDescriptions returns the resource descriptions of the match block of the rule,
+// a rule matching all filters is described by their union which may match more requests
+func matchDescriptions(rule kyverno.Rule) []matchDescription {
+	match := rule.MatchResources
+	if len(match.Any) > 0 {
+		var descriptions []matchDescription
+		for _, filter := range match.Any {
+			descriptions = append(descriptions, newMatchDescription(filter.ResourceDescription))
+		}
+		return descriptions
+	}
+
+	if len(match.All) > 0 {
+		var description matchDescription
+		for _, filter := range match.All {
+			description.kinds = append(description.kinds, filter.Kinds...)
+			description.operations = append(description.operations, filter.Operations...)
+			description.namespaces = append(description.namespaces, filter.Namespaces...)
+		}
+		return []matchDescription{description}
+	}
+
+	return []matchDescription{newMatchDescription(match.ResourceDescription)}
+}
+
+func newMatchDescription(resource kyverno.ResourceDescription) matchDescription {
+	return matchDescription{
+		kinds:      resource.Kinds,
+		operations: resource.Operations,
+		namespaces: resource.Namespaces,
+	}
+}
+
+// buildRules converts the kinds of the descriptions to webhook rules, the rules with the same
+// API group, version and operations are merged. A catch-all rule is returned if a kind has a
+// wildcard or is not found in the API server, or if a description has no kinds.
+func (wrc *WebhookRegistrationClient) buildRules(descriptions []matchDescription, operations []admregapi.OperationType) []admregapi.RuleWithOperations {
+	type ruleKey struct {
+		group, version, operations string
+	}
+
+	rules := make(map[ruleKey]map[string]bool)
+	for _, description := range descriptions {
+		ops := webhookOperations(description.operations, operations)
+		if len(ops) == 0 {
+			continue
+		}
+
+		if len(description.kinds) == 0 {
+			return catchAllRules(operations)
+		}
+
+		for _, kind := range description.kinds {
+			group, version, resource, ok := wrc.kindToResource(kind)
+			if !ok {
+				wrc.log.V(4).Info("registering the webhooks for all resources", "kind", kind)
+				return catchAllRules(operations)
+			}
+
+			key := ruleKey{group: group, version: version, operations: joinOperations(ops)}
+			if rules[key] == nil {
+				rules[key] = make(map[string]bool)
+			}
+			rules[key][resource] = true
+		}
+	}
+
+	var keys []ruleKey
+	for key := range rules {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].group != keys[j].group {
+			return keys[i].group < keys[j].group
+		}
+		if keys[i].version != keys[j].version {
+			return keys[i].version < keys[j].version
+		}
+		return keys[i].operations < keys[j].operations
+	})
+
+	var result []admregapi.RuleWithOperations
+	for _, key := range keys {
+		result = append(result, newRule(key.group, key.version, sortedKeys(rules[key]), splitOperations(key.operations)))
+	}
+	return result
+}
+
+// kindToResource returns the API group, version and resource of a kind selector,
+// the group and version default to all, which is a superset of the requests matched by the kind
+func (wrc *WebhookRegistrationClient) kindToResource(kind string) (group, version, resource string, ok bool) {
+	group, version, kind, subresource := utils.ParseKindSelector(kind)
+	if kind == "" || strings.ContainsAny(kind, "*?") {
+		return "", "", "", false
+	}
+
+	gvr := wrc.client.DiscoveryClient.GetGVRFromKind(kind)
+	if gvr.Resource == "" {
+		return "", "", "", false
+	}
+
+	resource = gvr.Resource
+	if subresource != "" {
+		resource = resource + "/" + subresource
+	}
+	return group, version, resource, true
+}
+
+// buildNamespaceSelector returns a selector for the namespaces of the descriptions, or nil for all namespaces.
+// The namespaces are selected by name, the label is only set on Kubernetes 1.21 and above.
+func (wrc *WebhookRegistrationClient) buildNamespaceSelector(descriptions []matchDescription) *v1.LabelSelector {
+	namespaces := make(map[string]bool)
+	for _, description := range descriptions {
+		if len(description.namespaces) == 0 {
+			return nil
+		}
+
+		for _, ns := range description.namespaces {
+			if strings.ContainsAny(ns, "*?") {
+				return nil
+			}
+			namespaces[ns] = true
+		}
+	}
+
+	if !wrc.hasNamespaceNameLabel() {
+		return nil
+	}
+
+	return &v1.LabelSelector{
+		MatchExpressions: []v1.LabelSelectorRequirement{
+			{
+				Key:      namespaceNameLabel,
+				Operator: v1.LabelSelectorOpIn,
+				Values:   sortedKeys(namespaces),
+			},
+		},
+	}
+}
+
+// hasNamespaceNameLabel returns true if the API server labels the namespaces with their name
+func (wrc *WebhookRegistrationClient) hasNamespaceNameLabel() bool {
+	serverVersion, err := wrc.client.DiscoveryClient.GetServerVersion()
+	if err != nil || serverVersion == nil {
+		return false
+	}
+
+	major, err := strconv.Atoi(serverVersion.Major)
+	if err != nil {
+		return false
+	}
+	// managed clusters may report a minor version such as "21+"
+	minor, err := strconv.Atoi(strings.TrimSuffix(serverVersion.Minor, "+"))
+	if err != nil {
+		return false
+	}
+
+	return major > 1 || (major == 1 && minor >= 21)
+}
+
+// webhookOperations returns the operations of the description supported by the webhook,
+// all the webhook operations if the description has none
+func webhookOperations(operations []kyverno.AdmissionOperation, supported []admregapi.OperationType) []admregapi.OperationType {
+	if len(operations) == 0 {
+		return supported
+	}
+
+	var result []admregapi.OperationType
+	for _, op := range supported {
+		for _, operation := range operations {
+			if string(operation) == string(op) {
+				result = append(result, op)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func catchAllRules(operations []admregapi.OperationType) []admregapi.RuleWithOperations {
+	return []admregapi.RuleWithOperations{newRule("*", "*", []string{"*/*"}, operations)}
+}
+
+func newRule(group, version string, resources []string, operations []admregapi.OperationType) admregapi.RuleWithOperations {
+	scope := admregapi.AllScopes
+	return admregapi.RuleWithOperations{
+		Operations: operations,
+		Rule: admregapi.Rule{
+			APIGroups:   []string{group},
+			APIVersions: []string{version},
+			Resources:   resources,
+			Scope:       &scope,
+		},
+	}
+}
+
+func joinOperations(operations []admregapi.OperationType) string {
+	var ops []string
+	for _, op := range operations {
+		ops = append(ops, string(op))
+	}
+	return strings.Join(ops, ",")
+}
+
+func splitOperations(operations string) []admregapi.OperationType {
+	var ops []admregapi.OperationType
+	for _, op := range strings.Split(operations, ",") {
+		ops = append(ops, admregapi.OperationType(op))
+	}
+	return ops
+}
+
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,180 @@
+package webhookconfig
+
+import (
+	"encoding/json"
+	"testing"
+
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	client "github.com/nirmata/kyverno/pkg/dclient"
+	"gotest.tools/assert"
+	admregapi "k8s.io/api/admissionregistration/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type versionedDiscovery struct {
+	client.IDiscovery
+	serverVersion *version.Info
+}
+
+func (d versionedDiscovery) GetServerVersion() (*version.Info, error) {
+	return d.serverVersion, nil
+}
+
+func newTestRegistrationClient(t *testing.T, serverVersion *version.Info) *WebhookRegistrationClient {
+	dclient, err := client.NewMockClient(runtime.NewScheme())
+	assert.NilError(t, err)
+	dclient.SetDiscovery(versionedDiscovery{
+		IDiscovery:    client.NewFakeDiscoveryClient([]schema.GroupVersionResource{{Version: "v1", Resource: "pods"}}),
+		serverVersion: serverVersion,
+	})
+	return &WebhookRegistrationClient{client: dclient, log: log.Log}
+}
+
+func newTestPolicy(t *testing.T, raw string) *kyverno.ClusterPolicy {
+	var policy kyverno.ClusterPolicy
+	assert.NilError(t, json.Unmarshal([]byte(raw), &policy))
+	return &policy
+}
+
+func Test_ResourceWebhookRules_Kinds(t *testing.T) {
+	wrc := newTestRegistrationClient(t, nil)
+	policy := newTestPolicy(t, `{
+		"metadata": {"name": "add-labels"},
+		"spec": {
+			"rules": [
+				{
+					"name": "pods",
+					"match": {"resources": {"kinds": ["Pod", "apps/v1/Deployment"]}},
+					"mutate": {"patchStrategicMerge": {"metadata": {"labels": {"app": "test"}}}}
+				},
+				{
+					"name": "exec",
+					"match": {"resources": {"kinds": ["Pod/exec"], "operations": ["CONNECT"]}},
+					"validate": {"deny": {}}
+				},
+				{
+					"name": "delete-secrets",
+					"match": {"resources": {"kinds": ["Secret"], "operations": ["DELETE"]}},
+					"mutate": {"patchStrategicMerge": {"metadata": {"labels": {"app": "test"}}}}
+				}
+			]
+		}
+	}`)
+
+	descriptions := policyDescriptions([]*kyverno.ClusterPolicy{policy}, kyverno.Rule.HasMutate)
+	rules, namespaceSelector := wrc.resourceWebhookRules(descriptions, mutatingWebhookOperations)
+	assert.DeepEqual(t, rules, []admregapi.RuleWithOperations{
+		newRule("*", "*", []string{"pods"}, mutatingWebhookOperations),
+		newRule("apps", "v1", []string{"deployments"}, mutatingWebhookOperations),
+	})
+	assert.Assert(t, namespaceSelector == nil)
+
+	descriptions = policyDescriptions([]*kyverno.ClusterPolicy{policy}, kyverno.Rule.HasValidate)
+	rules, _ = wrc.resourceWebhookRules(descriptions, validatingWebhookOperations)
+	assert.DeepEqual(t, rules, []admregapi.RuleWithOperations{
+		newRule("*", "*", []string{"pods/exec"}, []admregapi.OperationType{admregapi.Connect}),
+	})
+
+	descriptions = policyDescriptions([]*kyverno.ClusterPolicy{policy}, kyverno.Rule.HasGenerate)
+	rules, _ = wrc.resourceWebhookRules(descriptions, mutatingWebhookOperations)
+	assert.Assert(t, len(rules) == 0)
+}
+
+func Test_ResourceWebhookRules_CatchAll(t *testing.T) {
+	wrc := newTestRegistrationClient(t, nil)
+	for _, kind := range []string{"*", "Custom", "apps/v1/*"} {
+		policy := newTestPolicy(t, `{
+			"metadata": {"name": "catch-all"},
+			"spec": {
+				"rules": [
+					{
+						"name": "all",
+						"match": {"resources": {"kinds": ["Pod", "`+kind+`"]}},
+						"validate": {"deny": {}}
+					}
+				]
+			}
+		}`)
+
+		descriptions := policyDescriptions([]*kyverno.ClusterPolicy{policy}, kyverno.Rule.HasValidate)
+		rules, _ := wrc.resourceWebhookRules(descriptions, validatingWebhookOperations)
+		assert.DeepEqual(t, rules, catchAllRules(validatingWebhookOperations))
+	}
+}
+
+func Test_ResourceWebhookRules_Namespaces(t *testing.T) {
+	policy := newTestPolicy(t, `{
+		"metadata": {"name": "require-labels"},
+		"spec": {
+			"rules": [
+				{
+					"name": "pods",
+					"match": {"any": [
+						{"resources": {"kinds": ["Pod"], "namespaces": ["prod"]}},
+						{"resources": {"kinds": ["Pod"], "namespaces": ["staging"]}}
+					]},
+					"validate": {"deny": {}}
+				}
+			]
+		}
+	}`)
+	nsPolicy := newTestPolicy(t, `{
+		"metadata": {"name": "require-labels", "namespace": "dev"},
+		"spec": {
+			"rules": [
+				{
+					"name": "pods",
+					"match": {"resources": {"kinds": ["Pod"]}},
+					"validate": {"deny": {}}
+				}
+			]
+		}
+	}`)
+	policies := []*kyverno.ClusterPolicy{policy, nsPolicy}
+
+	wrc := newTestRegistrationClient(t, &version.Info{Major: "1", Minor: "21+"})
+	_, namespaceSelector := wrc.resourceWebhookRules(policyDescriptions(policies, kyverno.Rule.HasValidate), validatingWebhookOperations)
+	assert.DeepEqual(t, namespaceSelector, &v1.LabelSelector{
+		MatchExpressions: []v1.LabelSelectorRequirement{
+			{Key: namespaceNameLabel, Operator: v1.LabelSelectorOpIn, Values: []string{"dev", "prod", "staging"}},
+		},
+	})
+
+	// the namespaces are not labelled with their name before Kubernetes 1.21
+	wrc = newTestRegistrationClient(t, &version.Info{Major: "1", Minor: "18"})
+	_, namespaceSelector = wrc.resourceWebhookRules(policyDescriptions(policies, kyverno.Rule.HasValidate), validatingWebhookOperations)
+	assert.Assert(t, namespaceSelector == nil)
+
+	// the generated resources may be created in any namespace
+	generate := newTestPolicy(t, `{
+		"metadata": {"name": "default-configmap"},
+		"spec": {
+			"rules": [
+				{
+					"name": "configmap",
+					"match": {"resources": {"kinds": ["Namespace"]}},
+					"generate": {"kind": "ConfigMap", "name": "default", "namespace": "{{request.object.metadata.name}}", "synchronize": true}
+				}
+			]
+		}
+	}`)
+
+	wrc = newTestRegistrationClient(t, &version.Info{Major: "1", Minor: "21"})
+	descriptions := append(policyDescriptions(policies, kyverno.Rule.HasValidate), generatedResourceDescriptions([]*kyverno.ClusterPolicy{generate})...)
+	rules, namespaceSelector := wrc.resourceWebhookRules(descriptions, validatingWebhookOperations)
+	assert.Assert(t, namespaceSelector == nil)
+	assert.DeepEqual(t, rules, []admregapi.RuleWithOperations{
+		newRule("*", "*", []string{"pods"}, validatingWebhookOperations),
+		newRule("*", "*", []string{"configmaps"}, []admregapi.OperationType{admregapi.Update, admregapi.Delete}),
+	})
+}
+
+func Test_WebhookRulesEqual(t *testing.T) {
+	rules := catchAllRules(mutatingWebhookOperations)
+	assert.Assert(t, webhookRulesEqual(rules, &v1.LabelSelector{}, catchAllRules(mutatingWebhookOperations), nil))
+	assert.Assert(t, !webhookRulesEqual(rules, nil, catchAllRules(validatingWebhookOperations), nil))
+}
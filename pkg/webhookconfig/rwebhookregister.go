@@ -1,120 +1,237 @@
 package webhookconfig
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	checker "github.com/nirmata/kyverno/pkg/checker"
-	"github.com/tevino/abool"
-	mconfiginformer "k8s.io/client-go/informers/admissionregistration/v1beta1"
-	mconfiglister "k8s.io/client-go/listers/admissionregistration/v1beta1"
+	"github.com/nirmata/kyverno/pkg/policycache"
+	admregapi "k8s.io/api/admissionregistration/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	cache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 )
 
-//ResourceWebhookRegister manages the resource webhook registration
+const (
+	// webhookUpdateDelay is the delay before the resource webhooks are updated,
+	// so a burst of policy changes results in a single update
+	webhookUpdateDelay = 2 * time.Second
+
+	resourceWebhookQueueName = "resource-webhook-register"
+	// resourceWebhookKey is the single key of the queue, all the webhooks are updated together
+	resourceWebhookKey = "resource-webhooks"
+)
+
+//ResourceWebhookRegister manages the resource webhook registration,
+// the webhooks only receive the requests for the kinds, operations and namespaces matched by the policies
 type ResourceWebhookRegister struct {
-	// pendingCreation indicates the status of resource webhook creation
-	pendingMutateWebhookCreation   *abool.AtomicBool
-	pendingValidateWebhookCreation *abool.AtomicBool
 	LastReqTime                    *checker.LastReqTime
-	mwebhookconfigSynced           cache.InformerSynced
-	vwebhookconfigSynced           cache.InformerSynced
-	mWebhookConfigLister           mconfiglister.MutatingWebhookConfigurationLister
-	vWebhookConfigLister           mconfiglister.ValidatingWebhookConfigurationLister
+	pCacheSynced                   cache.InformerSynced
 	webhookRegistrationClient      *WebhookRegistrationClient
+	pCache                         policycache.Interface
 	RunValidationInMutatingWebhook string
 	log                            logr.Logger
+
+	// queue holds the update requests, failed updates are requeued with a rate limit
+	queue workqueue.RateLimitingInterface
 }
 
-// NewResourceWebhookRegister returns a new instance of ResourceWebhookRegister manager
+// NewResourceWebhookRegister returns a new instance of ResourceWebhookRegister manager,
+// the webhooks are updated when the policy cache changes
 func NewResourceWebhookRegister(
 	lastReqTime *checker.LastReqTime,
-	mconfigwebhookinformer mconfiginformer.MutatingWebhookConfigurationInformer,
-	vconfigwebhookinformer mconfiginformer.ValidatingWebhookConfigurationInformer,
 	webhookRegistrationClient *WebhookRegistrationClient,
+	pCacheController *policycache.Controller,
 	runValidationInMutatingWebhook string,
 	log logr.Logger,
 ) *ResourceWebhookRegister {
-	return &ResourceWebhookRegister{
-		pendingMutateWebhookCreation:   abool.New(),
-		pendingValidateWebhookCreation: abool.New(),
+	rww := &ResourceWebhookRegister{
 		LastReqTime:                    lastReqTime,
-		mwebhookconfigSynced:           mconfigwebhookinformer.Informer().HasSynced,
-		mWebhookConfigLister:           mconfigwebhookinformer.Lister(),
-		vwebhookconfigSynced:           vconfigwebhookinformer.Informer().HasSynced,
-		vWebhookConfigLister:           vconfigwebhookinformer.Lister(),
+		pCacheSynced:                   pCacheController.HasSynced,
 		webhookRegistrationClient:      webhookRegistrationClient,
+		pCache:                         pCacheController.Cache,
 		RunValidationInMutatingWebhook: runValidationInMutatingWebhook,
 		log:                            log,
+		queue:                          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), resourceWebhookQueueName),
 	}
+
+	pCacheController.AddChangeHandler(rww.RegisterResourceWebhook)
+	return rww
 }
 
-//RegisterResourceWebhook registers a resource webhook
+//RegisterResourceWebhook requests the resource webhooks to be registered or updated for the current policies
 func (rww *ResourceWebhookRegister) RegisterResourceWebhook() {
-	timeDiff := time.Since(rww.LastReqTime.Time())
-	if timeDiff < checker.DefaultDeadline {
-		if !rww.pendingMutateWebhookCreation.IsSet() {
-			go rww.createMutatingWebhook()
-		}
+	rww.queue.AddAfter(resourceWebhookKey, webhookUpdateDelay)
+}
 
-		if !rww.pendingValidateWebhookCreation.IsSet() {
-			go rww.createValidateWebhook()
-		}
+//Run starts the ResourceWebhookRegister manager
+func (rww *ResourceWebhookRegister) Run(stopCh <-chan struct{}) {
+	logger := rww.log
+	defer rww.queue.ShutDown()
+
+	// wait for cache to populate first time
+	if !cache.WaitForCacheSync(stopCh, rww.pCacheSynced) {
+		logger.Info("configuration: failed to sync webhook informer cache")
+		return
 	}
+
+	// the webhooks are reconciled with the policies that existed before this instance started
+	rww.queue.Add(resourceWebhookKey)
+
+	go wait.Until(rww.worker, time.Second, stopCh)
+	<-stopCh
 }
 
-func (rww *ResourceWebhookRegister) createMutatingWebhook() {
-	rww.pendingMutateWebhookCreation.Set()
-	defer rww.pendingMutateWebhookCreation.UnSet()
-
-	mutatingConfigName := rww.webhookRegistrationClient.GetResourceMutatingWebhookConfigName()
-	mutatingConfig, _ := rww.mWebhookConfigLister.Get(mutatingConfigName)
-	if mutatingConfig != nil {
-		rww.log.V(5).Info("mutating webhoook configuration exists", "name", mutatingConfigName)
-	} else {
-		err := rww.webhookRegistrationClient.CreateResourceMutatingWebhookConfiguration()
-		if err != nil {
-			rww.log.Error(err, "failed to create resource mutating webhook configuration, re-queue creation request")
-			rww.RegisterResourceWebhook()
-			return
-		}
+func (rww *ResourceWebhookRegister) worker() {
+	for rww.processNextWorkItem() {
+	}
+}
 
-		rww.log.V(2).Info("created mutating webhook", "name", mutatingConfigName)
+func (rww *ResourceWebhookRegister) processNextWorkItem() bool {
+	key, quit := rww.queue.Get()
+	if quit {
+		return false
 	}
+	defer rww.queue.Done(key)
+
+	if err := rww.updateWebhooks(); err != nil {
+		// the webhooks must match the policies, the update is retried until it succeeds
+		rww.log.Error(err, "failed to update resource webhook configurations, re-queue update request", "retries", rww.queue.NumRequeues(key))
+		rww.queue.AddRateLimited(key)
+		return true
+	}
+
+	rww.queue.Forget(key)
+	return true
 }
 
-func (rww *ResourceWebhookRegister) createValidateWebhook() {
-	rww.pendingValidateWebhookCreation.Set()
-	defer rww.pendingValidateWebhookCreation.UnSet()
+func (rww *ResourceWebhookRegister) updateWebhooks() error {
+	if err := rww.updateMutatingWebhook(); err != nil {
+		return err
+	}
 
 	if rww.RunValidationInMutatingWebhook == "true" {
-		rww.log.V(2).Info("validation is configured to run during mutate webhook")
-		return
+		rww.log.V(4).Info("validation is configured to run during mutate webhook")
+		return nil
+	}
+
+	return rww.updateValidatingWebhook()
+}
+
+func (rww *ResourceWebhookRegister) updateMutatingWebhook() error {
+	runValidation := rww.RunValidationInMutatingWebhook == "true"
+	policyTypes := []policycache.PolicyType{policycache.Mutate, policycache.Generate}
+	if runValidation {
+		policyTypes = append(policyTypes, policycache.ValidateEnforce, policycache.ValidateAudit)
+	}
+
+	filter := func(rule kyverno.Rule) bool {
+		return rule.HasMutate() || rule.HasGenerate() || (runValidation && rule.HasValidate())
 	}
 
-	validatingConfigName := rww.webhookRegistrationClient.GetResourceValidatingWebhookConfigName()
-	validatingConfig, _ := rww.vWebhookConfigLister.Get(validatingConfigName)
-	if validatingConfig != nil {
-		rww.log.V(4).Info("validating webhoook configuration exists", "name", validatingConfigName)
-	} else {
-		err := rww.webhookRegistrationClient.CreateResourceValidatingWebhookConfiguration()
-		if err != nil {
-			rww.log.Error(err, "failed to create resource validating webhook configuration; re-queue creation request")
-			rww.RegisterResourceWebhook()
-			return
+	descriptions := policyDescriptions(rww.policies(policyTypes...), filter)
+	rules, namespaceSelector := rww.webhookRegistrationClient.resourceWebhookRules(descriptions, mutatingWebhookOperations)
+
+	configName := rww.webhookRegistrationClient.GetResourceMutatingWebhookConfigName()
+	mutatingConfig, err := rww.webhookRegistrationClient.getResourceMutatingWebhookConfiguration()
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		if mutatingConfig != nil {
+			rww.webhookRegistrationClient.RemoveResourceMutatingWebhookConfiguration()
+		}
+		return nil
+	}
+
+	if mutatingConfig == nil {
+		if err := rww.webhookRegistrationClient.CreateResourceMutatingWebhookConfiguration(rules, namespaceSelector); err != nil {
+			return err
 		}
+		rww.log.V(2).Info("created mutating webhook", "name", configName)
+		return nil
+	}
+
+	if webhookRulesEqual(mutatingConfig.Webhooks[0].Rules, mutatingConfig.Webhooks[0].NamespaceSelector, rules, namespaceSelector) {
+		rww.log.V(5).Info("mutating webhoook configuration is up to date", "name", configName)
+		return nil
+	}
 
-		rww.log.V(2).Info("created validating webhook", "name", validatingConfigName)
+	if err := rww.webhookRegistrationClient.UpdateResourceMutatingWebhookConfiguration(mutatingConfig, rules, namespaceSelector); err != nil {
+		return err
 	}
+	rww.log.V(2).Info("updated mutating webhook", "name", configName)
+	return nil
 }
 
-//Run starts the ResourceWebhookRegister manager
-func (rww *ResourceWebhookRegister) Run(stopCh <-chan struct{}) {
-	logger := rww.log
-	// wait for cache to populate first time
-	if !cache.WaitForCacheSync(stopCh, rww.mwebhookconfigSynced, rww.vwebhookconfigSynced) {
-		logger.Info("configuration: failed to sync webhook informer cache")
+func (rww *ResourceWebhookRegister) updateValidatingWebhook() error {
+	descriptions := policyDescriptions(rww.policies(policycache.ValidateEnforce, policycache.ValidateAudit), kyverno.Rule.HasValidate)
+	// the updates and deletions of the resources created by the generate rules are validated
+	// to protect the synchronized resources
+	descriptions = append(descriptions, generatedResourceDescriptions(rww.policies(policycache.Generate))...)
+	rules, namespaceSelector := rww.webhookRegistrationClient.resourceWebhookRules(descriptions, validatingWebhookOperations)
+
+	configName := rww.webhookRegistrationClient.GetResourceValidatingWebhookConfigName()
+	validatingConfig, err := rww.webhookRegistrationClient.getResourceValidatingWebhookConfiguration()
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		if validatingConfig != nil {
+			rww.webhookRegistrationClient.RemoveResourceValidatingWebhookConfiguration()
+		}
+		return nil
+	}
+
+	if validatingConfig == nil {
+		if err := rww.webhookRegistrationClient.CreateResourceValidatingWebhookConfiguration(rules, namespaceSelector); err != nil {
+			return err
+		}
+		rww.log.V(2).Info("created validating webhook", "name", configName)
+		return nil
+	}
+
+	if webhookRulesEqual(validatingConfig.Webhooks[0].Rules, validatingConfig.Webhooks[0].NamespaceSelector, rules, namespaceSelector) {
+		rww.log.V(5).Info("validating webhoook configuration is up to date", "name", configName)
+		return nil
+	}
+
+	if err := rww.webhookRegistrationClient.UpdateResourceValidatingWebhookConfiguration(validatingConfig, rules, namespaceSelector); err != nil {
+		return err
+	}
+	rww.log.V(2).Info("updated validating webhook", "name", configName)
+	return nil
+}
+
+// policies returns the cached policies of the types, a policy with rules of several types is returned once
+func (rww *ResourceWebhookRegister) policies(policyTypes ...policycache.PolicyType) []*kyverno.ClusterPolicy {
+	var policies []*kyverno.ClusterPolicy
+	found := make(map[string]bool)
+	for _, policyType := range policyTypes {
+		for _, policy := range rww.pCache.List(policyType) {
+			key := kyverno.PolicyKey(policy.GetNamespace(), policy.GetName())
+			if found[key] {
+				continue
+			}
+			found[key] = true
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
+
+// webhookRulesEqual compares the rules of the registered webhook, the API server defaults an empty namespace selector
+func webhookRulesEqual(rules []admregapi.RuleWithOperations, namespaceSelector *v1.LabelSelector, desiredRules []admregapi.RuleWithOperations, desiredNamespaceSelector *v1.LabelSelector) bool {
+	if namespaceSelector == nil {
+		namespaceSelector = &v1.LabelSelector{}
+	}
+	if desiredNamespaceSelector == nil {
+		desiredNamespaceSelector = &v1.LabelSelector{}
 	}
+	return reflect.DeepEqual(rules, desiredRules) && reflect.DeepEqual(namespaceSelector, desiredNamespaceSelector)
 }
 
 // RemoveResourceWebhookConfiguration removes the resource webhook configurations
@@ -0,0 +1,64 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// admissionReview is the AdmissionReview received from and sent to the API server,
+// admission.k8s.io/v1 and admission.k8s.io/v1beta1 share the same request and response fields
+// so the request is handled as v1beta1 and the response is sent back in the version it was received
+type admissionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *v1beta1.AdmissionRequest `json:"request,omitempty"`
+	Response        *admissionResponse        `json:"response,omitempty"`
+}
+
+// admissionResponse adds the warnings introduced by admission.k8s.io/v1 to the v1beta1 response
+type admissionResponse struct {
+	v1beta1.AdmissionResponse `json:",inline"`
+
+	// Warnings are returned to the API client, only sent in admission.k8s.io/v1 responses
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// decodeAdmissionReview decodes admission.k8s.io/v1 and admission.k8s.io/v1beta1 AdmissionReview requests
+func decodeAdmissionReview(body []byte) (*admissionReview, error) {
+	review := &admissionReview{}
+	if err := json.Unmarshal(body, review); err != nil {
+		return nil, err
+	}
+
+	switch review.APIVersion {
+	case admissionv1.SchemeGroupVersion.String(), v1beta1.SchemeGroupVersion.String():
+	case "":
+		// older API servers may not set the type meta, assume v1beta1
+		review.APIVersion = v1beta1.SchemeGroupVersion.String()
+		review.Kind = "AdmissionReview"
+	default:
+		return nil, fmt.Errorf("unsupported AdmissionReview version %s", review.APIVersion)
+	}
+
+	if review.Request == nil {
+		return nil, fmt.Errorf("AdmissionReview has no request")
+	}
+
+	return review, nil
+}
+
+// setResponse sets the response of the admission review,
+// warnings are dropped for admission.k8s.io/v1beta1 as they are not supported
+func (review *admissionReview) setResponse(response *v1beta1.AdmissionResponse, warnings []string) {
+	response.UID = review.Request.UID
+	review.Response = &admissionResponse{
+		AdmissionResponse: *response,
+	}
+
+	if review.APIVersion == admissionv1.SchemeGroupVersion.String() {
+		review.Response.Warnings = warnings
+	}
+}
@@ -0,0 +1,75 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+	"k8s.io/api/admission/v1beta1"
+)
+
+func Test_AdmissionReviewV1(t *testing.T) {
+	body := []byte(`{
+		"apiVersion": "admission.k8s.io/v1",
+		"kind": "AdmissionReview",
+		"request": {
+			"uid": "705ab4f5-6393-11e8-b7cc-42010a800002",
+			"kind": {"group": "", "version": "v1", "kind": "Pod"},
+			"resource": {"group": "", "version": "v1", "resource": "pods"},
+			"name": "nginx",
+			"namespace": "default",
+			"operation": "CREATE",
+			"userInfo": {"username": "admin"}
+		}
+	}`)
+
+	review, err := decodeAdmissionReview(body)
+	assert.NilError(t, err)
+	assert.Equal(t, review.APIVersion, "admission.k8s.io/v1")
+	assert.Equal(t, string(review.Request.UID), "705ab4f5-6393-11e8-b7cc-42010a800002")
+	assert.Equal(t, review.Request.Kind.Kind, "Pod")
+	assert.Equal(t, review.Request.Operation, v1beta1.Create)
+
+	patchType := v1beta1.PatchTypeJSONPatch
+	review.setResponse(&v1beta1.AdmissionResponse{Allowed: true, PatchType: &patchType}, []string{"policy p rule r: failed"})
+
+	var out map[string]interface{}
+	raw, err := json.Marshal(admissionReview{TypeMeta: review.TypeMeta, Response: review.Response})
+	assert.NilError(t, err)
+	assert.NilError(t, json.Unmarshal(raw, &out))
+	assert.Equal(t, out["apiVersion"], "admission.k8s.io/v1")
+	assert.Equal(t, out["kind"], "AdmissionReview")
+	assert.Assert(t, out["request"] == nil)
+
+	response := out["response"].(map[string]interface{})
+	assert.Equal(t, response["uid"], "705ab4f5-6393-11e8-b7cc-42010a800002")
+	assert.Equal(t, response["allowed"], true)
+	assert.Equal(t, response["patchType"], "JSONPatch")
+	assert.DeepEqual(t, response["warnings"], []interface{}{"policy p rule r: failed"})
+}
+
+func Test_AdmissionReviewV1beta1(t *testing.T) {
+	body := []byte(`{
+		"request": {
+			"uid": "705ab4f5-6393-11e8-b7cc-42010a800002",
+			"kind": {"group": "", "version": "v1", "kind": "Pod"},
+			"operation": "UPDATE"
+		}
+	}`)
+
+	review, err := decodeAdmissionReview(body)
+	assert.NilError(t, err)
+	assert.Equal(t, review.APIVersion, "admission.k8s.io/v1beta1")
+
+	review.setResponse(&v1beta1.AdmissionResponse{Allowed: false}, []string{"policy p rule r: failed"})
+	assert.Equal(t, string(review.Response.UID), "705ab4f5-6393-11e8-b7cc-42010a800002")
+	assert.Assert(t, review.Response.Warnings == nil)
+}
+
+func Test_AdmissionReviewUnsupported(t *testing.T) {
+	_, err := decodeAdmissionReview([]byte(`{"apiVersion": "admission.k8s.io/v2", "kind": "AdmissionReview", "request": {}}`))
+	assert.ErrorContains(t, err, "unsupported AdmissionReview version")
+
+	_, err = decodeAdmissionReview([]byte(`{"apiVersion": "admission.k8s.io/v1", "kind": "AdmissionReview"}`))
+	assert.ErrorContains(t, err, "no request")
+}
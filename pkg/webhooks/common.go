@@ -7,10 +7,12 @@ import (
 	"github.com/go-logr/logr"
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	"github.com/nirmata/kyverno/pkg/common"
+	client "github.com/nirmata/kyverno/pkg/dclient"
 	"github.com/nirmata/kyverno/pkg/engine/response"
 	engineutils "github.com/nirmata/kyverno/pkg/engine/utils"
 	yamlv2 "gopkg.in/yaml.v2"
 	"k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -29,27 +31,55 @@ func isResponseSuccesful(engineReponses []response.EngineResponse) bool {
 // returns false -> if all the policies are meant to report only, we dont block resource request
 func toBlockResource(engineReponses []response.EngineResponse, log logr.Logger) bool {
 	for _, er := range engineReponses {
-		if !er.IsSuccessful() && er.PolicyResponse.ValidationFailureAction == common.Enforce {
-			log.Info("spec.ValidationFailureAction set to enforce blocking resource request", "policy", er.PolicyResponse.Policy)
-			return true
+		for _, rule := range er.PolicyResponse.Rules {
+			if !rule.Success && getRuleFailureAction(er, rule) == common.Enforce {
+				log.Info("ValidationFailureAction set to enforce blocking resource request", "policy", er.PolicyResponse.Policy, "rule", rule.Name)
+				return true
+			}
 		}
 	}
-	log.V(4).Info("sepc.ValidationFailureAction set to audit for all applicable policies, won't block resource operation")
+	log.V(4).Info("ValidationFailureAction set to audit for all failed rules, won't block resource operation")
 	return false
 }
 
+// filterRulesByFailureAction returns the engine response with the rules that have the failureAction,
+// a missing action is handled like audit
+func filterRulesByFailureAction(er response.EngineResponse, failureAction string) response.EngineResponse {
+	var rules []response.RuleResponse
+	for _, rule := range er.PolicyResponse.Rules {
+		if (getRuleFailureAction(er, rule) == common.Enforce) == (failureAction == common.Enforce) {
+			rules = append(rules, rule)
+		}
+	}
+
+	er.PolicyResponse.Rules = rules
+	er.PolicyResponse.RulesAppliedCount = len(rules)
+	return er
+}
+
+// getRuleFailureAction returns the ValidationFailureAction of the rule, or of the policy if the rule has none
+func getRuleFailureAction(er response.EngineResponse, rule response.RuleResponse) string {
+	if rule.ValidationFailureAction != "" {
+		return rule.ValidationFailureAction
+	}
+	return er.PolicyResponse.ValidationFailureAction
+}
+
 // getEnforceFailureErrorMsg gets the error messages for failed enforce policy
 func getEnforceFailureErrorMsg(engineResponses []response.EngineResponse) string {
 	policyToRule := make(map[string]interface{})
 	var resourceName string
 	for _, er := range engineResponses {
-		if !er.IsSuccessful() && er.PolicyResponse.ValidationFailureAction == common.Enforce {
+		if !er.IsSuccessful() {
 			ruleToReason := make(map[string]string)
 			for _, rule := range er.PolicyResponse.Rules {
-				if !rule.Success {
+				if !rule.Success && getRuleFailureAction(er, rule) == common.Enforce {
 					ruleToReason[rule.Name] = rule.Message
 				}
 			}
+			if len(ruleToReason) == 0 {
+				continue
+			}
 			resourceName = fmt.Sprintf("%s/%s/%s", er.PolicyResponse.Resource.Kind, er.PolicyResponse.Resource.Namespace, er.PolicyResponse.Resource.Name)
 
 			policyToRule[er.PolicyResponse.Policy] = ruleToReason
@@ -60,6 +90,24 @@ func getEnforceFailureErrorMsg(engineResponses []response.EngineResponse) string
 	return "\n\nresource " + resourceName + " was blocked due to the following policies\n\n" + string(result)
 }
 
+// getAuditWarnings returns a warning for each failed rule with the audit failure action
+func getAuditWarnings(engineResponses []response.EngineResponse) []string {
+	var warnings []string
+	for _, er := range engineResponses {
+		if er.IsSuccessful() {
+			continue
+		}
+
+		for _, rule := range er.PolicyResponse.Rules {
+			if !rule.Success && getRuleFailureAction(er, rule) == common.Audit {
+				warnings = append(warnings, fmt.Sprintf("policy %s rule %s: %s", er.PolicyResponse.Policy, rule.Name, rule.Message))
+			}
+		}
+	}
+
+	return warnings
+}
+
 // getErrorMsg gets all failed engine response message
 func getErrorMsg(engineReponses []response.EngineResponse) string {
 	var str []string
@@ -117,6 +165,14 @@ func containRBACinfo(policies ...[]*kyverno.ClusterPolicy) bool {
 				if len(rule.MatchResources.Roles) > 0 || len(rule.MatchResources.ClusterRoles) > 0 || len(rule.ExcludeResources.Roles) > 0 || len(rule.ExcludeResources.ClusterRoles) > 0 {
 					return true
 				}
+
+				for _, filters := range []kyverno.ResourceFilters{rule.MatchResources.Any, rule.MatchResources.All, rule.ExcludeResources.Any, rule.ExcludeResources.All} {
+					for _, filter := range filters {
+						if len(filter.Roles) > 0 || len(filter.ClusterRoles) > 0 {
+							return true
+						}
+					}
+				}
 			}
 		}
 	}
@@ -167,10 +223,28 @@ func convertResource(raw []byte, group, version, kind, namespace string) (unstru
 
 func excludeKyvernoResources(kind string) bool {
 	switch kind {
-	case "ClusterPolicy", "ClusterPolicyViolation", "PolicyViolation", "GenerateRequest":
+	case "ClusterPolicy", "Policy", "ClusterPolicyViolation", "PolicyViolation", "GenerateRequest":
 		return true
 	default:
 		return false
 	}
 
 }
+
+// setSubResourceInfo adds the subresource of the request and the kind of its parent resource to the request information
+func setSubResourceInfo(requestInfo *kyverno.RequestInfo, request *v1beta1.AdmissionRequest, client *client.Client, log logr.Logger) {
+	if request.SubResource == "" {
+		return
+	}
+
+	requestInfo.SubResource = request.SubResource
+	gvr := schema.GroupVersionResource{Group: request.Resource.Group, Version: request.Resource.Version, Resource: request.Resource.Resource}
+	gvk, err := client.DiscoveryClient.GetGVKFromGVR(gvr)
+	if err != nil {
+		log.Error(err, "failed to get the kind of the parent resource", "resource", gvr.String(), "subresource", request.SubResource)
+		return
+	}
+
+	parentKind := metav1.GroupVersionKind(gvk)
+	requestInfo.ParentKind = &parentKind
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/nirmata/kyverno/pkg/engine/response"
 	"github.com/nirmata/kyverno/pkg/engine/utils"
 	"github.com/nirmata/kyverno/pkg/event"
+	kyvernoutils "github.com/nirmata/kyverno/pkg/utils"
 	"github.com/nirmata/kyverno/pkg/webhooks/generate"
 	v1beta1 "k8s.io/api/admission/v1beta1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -44,6 +45,10 @@ func (ws *WebhookServer) HandleGenerate(request *v1beta1.AdmissionRequest, polic
 		AdmissionInfo:    userRequestInfo,
 		Context:          ctx,
 		ExcludeGroupRole: dynamicConfig.GetExcludeGroupRole(),
+		ConfigMapLister:  ws.cmLister,
+		Client:           ws.client,
+		APICallTimeout:   ws.webhookRegistrationClient.GetWebhookTimeOut(),
+		NamespaceLabels:  kyvernoutils.GetNamespaceLabels(ws.nsLister, request.Namespace, logger),
 	}
 
 	// engine.Generate returns a list of rules that are applicable on this resource
@@ -94,7 +99,7 @@ func applyGenerateRequest(gnGenerator generate.GenerateRequests, userRequestInfo
 func transform(userRequestInfo kyverno.RequestInfo, er response.EngineResponse) kyverno.GenerateRequestSpec {
 
 	gr := kyverno.GenerateRequestSpec{
-		Policy: er.PolicyResponse.Policy,
+		Policy: er.PolicyResponse.GetPolicyKey(),
 		Resource: kyverno.ResourceSpec{
 			Kind:      er.PolicyResponse.Resource.Kind,
 			Namespace: er.PolicyResponse.Resource.Namespace,
@@ -112,7 +117,7 @@ type generateStats struct {
 }
 
 func (gs generateStats) PolicyName() string {
-	return gs.resp.PolicyResponse.Policy
+	return gs.resp.PolicyResponse.GetPolicyKey()
 }
 
 func (gs generateStats) UpdateStatus(status kyverno.PolicyStatus) kyverno.PolicyStatus {
@@ -0,0 +1,373 @@
+package webhooks
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/go-logr/logr"
+	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
+	"github.com/nirmata/kyverno/pkg/config"
+	"github.com/nirmata/kyverno/pkg/constant"
+	client "github.com/nirmata/kyverno/pkg/dclient"
+	"github.com/nirmata/kyverno/pkg/engine"
+	enginectx "github.com/nirmata/kyverno/pkg/engine/context"
+	engineutils "github.com/nirmata/kyverno/pkg/engine/utils"
+	"github.com/nirmata/kyverno/pkg/policycache"
+	"github.com/nirmata/kyverno/pkg/policystatus"
+	"github.com/nirmata/kyverno/pkg/utils"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	informerv1 "k8s.io/client-go/informers/core/v1"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	mutateTargetsQueueName       = "mutate-targets-handler"
+	mutateTargetsQueueRetryLimit = 5
+)
+
+// MutateTargetsHandler applies the mutate rules with targets for the admission request
+// the handler adds the trigger resource to the work queue and returns immediately
+// the targets are listed and patched in background, failed requests are retried
+type MutateTargetsHandler interface {
+	Add(resource unstructured.Unstructured, userRequestInfo kyverno.RequestInfo)
+	Run(workers int, stopCh <-chan struct{})
+}
+
+// mutateTargetsRequest is the trigger resource of the mutate rules with targets
+type mutateTargetsRequest struct {
+	resource        unstructured.Unstructured
+	userRequestInfo kyverno.RequestInfo
+
+	// mutated holds the targets patched by the previous attempts, so that
+	// a target is counted once in the policy status when the request is retried
+	mutated map[string]bool
+}
+
+// countMutated records the targets patched by the rule, it returns the number of targets not counted before
+func (r *mutateTargetsRequest) countMutated(policyName, ruleName string, targets []string) int {
+	var count int
+	for _, target := range targets {
+		key := policyName + "/" + ruleName + "/" + target
+		if r.mutated[key] {
+			continue
+		}
+		r.mutated[key] = true
+		count++
+	}
+	return count
+}
+
+type mutateTargetsHandler struct {
+	dclient        *client.Client
+	queue          workqueue.RateLimitingInterface
+	pCache         policycache.Interface
+	statusListener policystatus.Listener
+
+	// requests holds the latest request for a trigger resource, the queue holds its key
+	// so that the updates of a trigger received while it waits are processed once
+	requests map[string]*mutateTargetsRequest
+	mu       sync.Mutex
+
+	cmLister listerv1.ConfigMapLister
+	cmSynced cache.InformerSynced
+	nsLister listerv1.NamespaceLister
+	nsSynced cache.InformerSynced
+
+	log           logr.Logger
+	configHandler config.Interface
+}
+
+// NewMutateTargetsHandler returns a new instance of the mutate targets handler
+func NewMutateTargetsHandler(dclient *client.Client,
+	pCache policycache.Interface,
+	statusListener policystatus.Listener,
+	cmInformer informerv1.ConfigMapInformer,
+	nsInformer informerv1.NamespaceInformer,
+	log logr.Logger,
+	dynamicConfig config.Interface) MutateTargetsHandler {
+
+	return &mutateTargetsHandler{
+		dclient:        dclient,
+		pCache:         pCache,
+		queue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), mutateTargetsQueueName),
+		statusListener: statusListener,
+		requests:       make(map[string]*mutateTargetsRequest),
+		cmLister:       cmInformer.Lister(),
+		cmSynced:       cmInformer.Informer().HasSynced,
+		nsLister:       nsInformer.Lister(),
+		nsSynced:       nsInformer.Informer().HasSynced,
+		log:            log,
+		configHandler:  dynamicConfig,
+	}
+}
+
+func (h *mutateTargetsHandler) Add(resource unstructured.Unstructured, userRequestInfo kyverno.RequestInfo) {
+	key := fmt.Sprintf("%s/%s/%s/%s", resource.GetAPIVersion(), resource.GetKind(), resource.GetNamespace(), resource.GetName())
+	h.log.V(4).Info("trigger resource added", "kind", resource.GetKind(), "namespace", resource.GetNamespace(), "name", resource.GetName())
+
+	h.mu.Lock()
+	h.requests[key] = &mutateTargetsRequest{resource: resource, userRequestInfo: userRequestInfo, mutated: map[string]bool{}}
+	h.mu.Unlock()
+
+	h.queue.Add(key)
+}
+
+func (h *mutateTargetsHandler) Run(workers int, stopCh <-chan struct{}) {
+	h.log.V(4).Info("starting")
+
+	defer func() {
+		utilruntime.HandleCrash()
+		h.queue.ShutDown()
+		h.log.V(4).Info("shutting down")
+	}()
+
+	if !cache.WaitForCacheSync(stopCh, h.cmSynced, h.nsSynced) {
+		h.log.Info("failed to sync informer cache")
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(h.runWorker, constant.GenerateControllerResync, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (h *mutateTargetsHandler) runWorker() {
+	for h.processNextWorkItem() {
+	}
+}
+
+func (h *mutateTargetsHandler) processNextWorkItem() bool {
+	obj, shutdown := h.queue.Get()
+	if shutdown {
+		return false
+	}
+
+	defer h.queue.Done(obj)
+
+	key, ok := obj.(string)
+	if !ok {
+		h.queue.Forget(obj)
+		h.log.Info("incorrect type: expecting type 'string'", "object", obj)
+		return true
+	}
+
+	h.mu.Lock()
+	request, ok := h.requests[key]
+	h.mu.Unlock()
+	if !ok {
+		h.queue.Forget(key)
+		return true
+	}
+
+	lastAttempt := h.queue.NumRequeues(key) >= mutateTargetsQueueRetryLimit
+	err := h.process(request, lastAttempt)
+	h.handleErr(err, key, request)
+
+	return true
+}
+
+func (h *mutateTargetsHandler) handleErr(err error, key string, request *mutateTargetsRequest) {
+	if err != nil && h.queue.NumRequeues(key) < mutateTargetsQueueRetryLimit {
+		h.log.Error(err, "failed to mutate targets, requeueing", "key", key)
+		h.queue.AddRateLimited(key)
+		return
+	}
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		h.log.Error(err, "dropping trigger resource from the queue", "key", key)
+	}
+
+	h.queue.Forget(key)
+
+	// keep the request if the trigger has been updated while it was processed
+	h.mu.Lock()
+	if h.requests[key] == request {
+		delete(h.requests, key)
+	}
+	h.mu.Unlock()
+}
+
+// process applies the mutate rules with targets of the policies matching the trigger resource,
+// the failed targets are recorded in the policy status on the last attempt only
+func (h *mutateTargetsHandler) process(request *mutateTargetsRequest, lastAttempt bool) error {
+	resource := request.resource
+	logger := h.log.WithName("process").WithValues("kind", resource.GetKind(), "namespace", resource.GetNamespace(), "name", resource.GetName())
+
+	ctx := enginectx.NewContext()
+	resourceRaw, err := resource.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal trigger resource")
+	}
+
+	if err := ctx.AddResource(resourceRaw); err != nil {
+		return errors.Wrap(err, "failed to load trigger resource in context")
+	}
+
+	if err := ctx.AddUserInfo(request.userRequestInfo); err != nil {
+		return errors.Wrap(err, "failed to load userInfo in context")
+	}
+
+	if err := ctx.AddSA(request.userRequestInfo.AdmissionUserInfo.Username); err != nil {
+		return errors.Wrap(err, "failed to load service account in context")
+	}
+
+	policyContext := engine.PolicyContext{
+		NewResource:      resource,
+		AdmissionInfo:    request.userRequestInfo,
+		Context:          ctx,
+		ExcludeGroupRole: h.configHandler.GetExcludeGroupRole(),
+		ConfigMapLister:  h.cmLister,
+		Client:           h.dclient,
+		NamespaceLabels:  utils.GetNamespaceLabels(h.nsLister, resource.GetNamespace(), logger),
+	}
+
+	var errs []error
+	for _, policy := range h.pCache.Get(policycache.Mutate, resource.GetNamespace()) {
+		policyName := kyverno.PolicyKey(policy.Namespace, policy.Name)
+
+		stats := mutateTargetsStats{
+			policyName: policyName,
+			mutated:    map[string]int{},
+			failed:     map[string]int{},
+		}
+
+		policyContext.Policy = *policy
+		for _, rule := range policy.Spec.Rules {
+			if !rule.HasMutateTargets() {
+				continue
+			}
+
+			mutated, err := h.mutateTargets(policyContext, rule.Name, logger)
+			stats.mutated[rule.Name] += request.countMutated(policyName, rule.Name, mutated)
+			if err != nil {
+				logger.Error(err, "failed to mutate targets", "policy", policy.Name, "rule", rule.Name)
+				errs = append(errs, err)
+				if lastAttempt {
+					stats.failed[rule.Name]++
+				}
+			}
+		}
+
+		h.statusListener.Send(stats)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to mutate targets: %v", errs)
+	}
+	return nil
+}
+
+// mutateTargets patches the targets of the rule, it returns the kind/namespace/name of the patched targets
+func (h *mutateTargetsHandler) mutateTargets(policyContext engine.PolicyContext, ruleName string, logger logr.Logger) ([]string, error) {
+	engineResponses, err := engine.MutateTargets(policyContext, ruleName)
+	if err != nil {
+		return nil, err
+	}
+
+	var mutated []string
+	var errs []error
+	for _, engineResponse := range engineResponses {
+		target := engineResponse.PolicyResponse.Resource
+		if !engineResponse.IsSuccessful() {
+			errs = append(errs, fmt.Errorf("failed to apply rule to target %s/%s: %v", target.Namespace, target.Name, engineResponse.GetFailedRules()))
+			continue
+		}
+
+		patches := engineResponse.GetPatches()
+		if len(patches) == 0 {
+			continue
+		}
+
+		// the patches were computed for this version of the target, the test
+		// operation fails the patch if the target has been changed since
+		testVersion := fmt.Sprintf(`{ "op": "test", "path": "/metadata/resourceVersion", "value": "%s" }`, engineResponse.PatchedResource.GetResourceVersion())
+		patches = append([][]byte{[]byte(testVersion)}, patches...)
+
+		_, err := h.dclient.PatchResource(target.APIVersion, target.Kind, target.Namespace, target.Name, engineutils.JoinPatches(patches))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to patch target %s/%s: %v", target.Namespace, target.Name, err))
+			continue
+		}
+
+		logger.V(3).Info("mutated target", "targetKind", target.Kind, "targetNamespace", target.Namespace, "targetName", target.Name)
+		mutated = append(mutated, target.GetKey())
+	}
+
+	if len(errs) > 0 {
+		return mutated, fmt.Errorf("%v", errs)
+	}
+	return mutated, nil
+}
+
+// hasMutateTargets checks if any of the policies has a mutate rule with targets
+func hasMutateTargets(policies []*kyverno.ClusterPolicy) bool {
+	for _, policy := range policies {
+		for _, rule := range policy.Spec.Rules {
+			if rule.HasMutateTargets() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mutateTargetsStats records the targets mutated by the rules of a policy in the policy status
+type mutateTargetsStats struct {
+	policyName string
+	mutated    map[string]int
+	failed     map[string]int
+}
+
+func (ms mutateTargetsStats) PolicyName() string {
+	return ms.policyName
+}
+
+func (ms mutateTargetsStats) UpdateStatus(status kyverno.PolicyStatus) kyverno.PolicyStatus {
+	ruleIndex := map[string]int{}
+	for i, rule := range status.Rules {
+		ruleIndex[rule.Name] = i
+	}
+
+	for _, ruleName := range sortedRuleNames(ms.mutated, ms.failed) {
+		mutated, failed := ms.mutated[ruleName], ms.failed[ruleName]
+		if mutated == 0 && failed == 0 {
+			continue
+		}
+
+		i, ok := ruleIndex[ruleName]
+		if !ok {
+			status.Rules = append(status.Rules, kyverno.RuleStats{Name: ruleName})
+			i = len(status.Rules) - 1
+			ruleIndex[ruleName] = i
+		}
+
+		status.TargetsMutatedCount += mutated
+		status.Rules[i].TargetsMutatedCount += mutated
+		status.Rules[i].TargetsFailedCount += failed
+	}
+
+	return status
+}
+
+func sortedRuleNames(counts ...map[string]int) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, count := range counts {
+		for name := range count {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
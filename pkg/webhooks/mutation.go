@@ -12,6 +12,7 @@ import (
 	"github.com/nirmata/kyverno/pkg/engine/response"
 	engineutils "github.com/nirmata/kyverno/pkg/engine/utils"
 	"github.com/nirmata/kyverno/pkg/policyviolation"
+	"github.com/nirmata/kyverno/pkg/utils"
 	v1beta1 "k8s.io/api/admission/v1beta1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -43,6 +44,10 @@ func (ws *WebhookServer) HandleMutation(
 		AdmissionInfo:    userRequestInfo,
 		Context:          ctx,
 		ExcludeGroupRole: ws.configHandler.GetExcludeGroupRole(),
+		ConfigMapLister:  ws.cmLister,
+		Client:           ws.client,
+		APICallTimeout:   ws.webhookRegistrationClient.GetWebhookTimeOut(),
+		NamespaceLabels:  utils.GetNamespaceLabels(ws.nsLister, request.Namespace, logger),
 	}
 
 	if request.Operation == v1beta1.Update {
@@ -122,7 +127,7 @@ type mutateStats struct {
 }
 
 func (ms mutateStats) PolicyName() string {
-	return ms.resp.PolicyResponse.Policy
+	return ms.resp.PolicyResponse.GetPolicyKey()
 }
 
 func (ms mutateStats) UpdateStatus(status kyverno.PolicyStatus) kyverno.PolicyStatus {
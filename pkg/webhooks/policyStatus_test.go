@@ -209,3 +209,51 @@ func Test_ValidateStats(t *testing.T) {
 		t.Errorf("\n\nTestcase has failed\nExpected:\n%v\nGot:\n%v\n\n", string(testCase.expectedOutput), string(output))
 	}
 }
+
+func Test_MutateTargetsStats(t *testing.T) {
+	status := v1.PolicyStatus{
+		Rules: []v1.RuleStats{{Name: "rule1", TargetsMutatedCount: 2}},
+	}
+
+	stats := []mutateTargetsStats{
+		{
+			policyName: "policy1",
+			mutated:    map[string]int{"rule1": 3, "rule2": 0},
+			failed:     map[string]int{},
+		},
+		{
+			policyName: "policy1",
+			mutated:    map[string]int{"rule2": 1},
+			failed:     map[string]int{"rule2": 1},
+		},
+	}
+
+	for _, stat := range stats {
+		status = stat.UpdateStatus(status)
+	}
+
+	output, _ := json.Marshal(status)
+	expectedOutput := []byte(`{"targetsMutatedCount":4,"ruleStatus":[{"ruleName":"rule1","targetsMutatedCount":5},{"ruleName":"rule2","targetsMutatedCount":1,"targetsFailedCount":1}]}`)
+	if !reflect.DeepEqual(output, expectedOutput) {
+		t.Errorf("\n\nTestError - \nExpected Output: %v \n\nActual Output: %v", string(expectedOutput), string(output))
+	}
+}
+
+func Test_MutateTargetsRequest_CountMutated(t *testing.T) {
+	request := &mutateTargetsRequest{mutated: map[string]bool{}}
+
+	// the first attempt patches a target and fails for the other one
+	if count := request.countMutated("policy1", "rule1", []string{"ConfigMap/default/cm1"}); count != 1 {
+		t.Errorf("expected 1 mutated target, got %d", count)
+	}
+
+	// the retry patches both targets, only the target that failed before is counted
+	if count := request.countMutated("policy1", "rule1", []string{"ConfigMap/default/cm1", "ConfigMap/default/cm2"}); count != 1 {
+		t.Errorf("expected 1 mutated target on retry, got %d", count)
+	}
+
+	// the targets are counted for each rule
+	if count := request.countMutated("policy1", "rule2", []string{"ConfigMap/default/cm1"}); count != 1 {
+		t.Errorf("expected 1 mutated target for another rule, got %d", count)
+	}
+}
@@ -29,9 +29,6 @@ func (ws *WebhookServer) policyValidation(request *v1beta1.AdmissionRequest) *v1
 		}
 	}
 
-	// if the policy contains mutating & validation rules and it config does not exist we create one
-	// queue the request
-	ws.resourceWebhookWatcher.RegisterResourceWebhook()
 	return &v1beta1.AdmissionResponse{
 		Allowed: true,
 	}
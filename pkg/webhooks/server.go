@@ -14,6 +14,7 @@ import (
 	"github.com/julienschmidt/httprouter"
 	v1 "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	"github.com/nirmata/kyverno/pkg/checker"
+	"github.com/nirmata/kyverno/pkg/common"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	kyvernoclient "github.com/nirmata/kyverno/pkg/client/clientset/versioned"
@@ -21,9 +22,11 @@ import (
 	kyvernolister "github.com/nirmata/kyverno/pkg/client/listers/kyverno/v1"
 	"github.com/nirmata/kyverno/pkg/config"
 	client "github.com/nirmata/kyverno/pkg/dclient"
+	"github.com/nirmata/kyverno/pkg/engine"
 	context2 "github.com/nirmata/kyverno/pkg/engine/context"
 	enginutils "github.com/nirmata/kyverno/pkg/engine/utils"
 	"github.com/nirmata/kyverno/pkg/event"
+	"github.com/nirmata/kyverno/pkg/metrics"
 	"github.com/nirmata/kyverno/pkg/openapi"
 	"github.com/nirmata/kyverno/pkg/policycache"
 	"github.com/nirmata/kyverno/pkg/policystatus"
@@ -34,8 +37,12 @@ import (
 	"github.com/nirmata/kyverno/pkg/webhookconfig"
 	"github.com/nirmata/kyverno/pkg/webhooks/generate"
 	v1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	informerv1 "k8s.io/client-go/informers/core/v1"
 	rbacinformer "k8s.io/client-go/informers/rbac/v1"
+	listerv1 "k8s.io/client-go/listers/core/v1"
 	rbaclister "k8s.io/client-go/listers/rbac/v1"
 	"k8s.io/client-go/tools/cache"
 )
@@ -76,6 +83,18 @@ type WebhookServer struct {
 	// return true if cluster role  store has synced atleast once
 	crSynced cache.InformerSynced
 
+	// list/get configmap resource
+	cmLister listerv1.ConfigMapLister
+
+	// return true if configmap store has synced atleast once
+	cmSynced cache.InformerSynced
+
+	// list/get namespace resource
+	nsLister listerv1.NamespaceLister
+
+	// return true if namespace store has synced atleast once
+	nsSynced cache.InformerSynced
+
 	// generate events
 	eventGen event.Interface
 
@@ -107,10 +126,16 @@ type WebhookServer struct {
 
 	auditHandler AuditHandler
 
+	// applies the mutate rules with targets in background
+	mutateTargetsHandler MutateTargetsHandler
+
 	log               logr.Logger
 	openAPIController *openapi.Controller
 
 	supportMudateValidate bool
+
+	// return the validate audit failures as warnings in the admission response
+	auditWarnings bool
 }
 
 // NewWebhookServer creates new instance of WebhookServer accordingly to given configuration
@@ -124,6 +149,8 @@ func NewWebhookServer(
 	crbInformer rbacinformer.ClusterRoleBindingInformer,
 	rInformer rbacinformer.RoleInformer,
 	crInformer rbacinformer.ClusterRoleInformer,
+	cmInformer informerv1.ConfigMapInformer,
+	nsInformer informerv1.NamespaceInformer,
 	eventGen event.Interface,
 	pCache policycache.Interface,
 	webhookRegistrationClient *webhookconfig.WebhookRegistrationClient,
@@ -133,7 +160,9 @@ func NewWebhookServer(
 	grGenerator *generate.Generator,
 	resourceWebhookWatcher *webhookconfig.ResourceWebhookRegister,
 	auditHandler AuditHandler,
+	mutateTargetsHandler MutateTargetsHandler,
 	supportMudateValidate bool,
+	auditWarnings bool,
 	cleanUp chan<- struct{},
 	log logr.Logger,
 	openAPIController *openapi.Controller,
@@ -164,6 +193,10 @@ func NewWebhookServer(
 		crLister:                  crInformer.Lister(),
 		crbSynced:                 crbInformer.Informer().HasSynced,
 		crSynced:                  crInformer.Informer().HasSynced,
+		cmLister:                  cmInformer.Lister(),
+		cmSynced:                  cmInformer.Informer().HasSynced,
+		nsLister:                  nsInformer.Lister(),
+		nsSynced:                  nsInformer.Informer().HasSynced,
 		eventGen:                  eventGen,
 		pCache:                    pCache,
 		webhookRegistrationClient: webhookRegistrationClient,
@@ -175,17 +208,19 @@ func NewWebhookServer(
 		grGenerator:               grGenerator,
 		resourceWebhookWatcher:    resourceWebhookWatcher,
 		auditHandler:              auditHandler,
+		mutateTargetsHandler:      mutateTargetsHandler,
 		log:                       log,
 		openAPIController:         openAPIController,
 		supportMudateValidate:     supportMudateValidate,
+		auditWarnings:             auditWarnings,
 	}
 
 	mux := httprouter.New()
 	mux.HandlerFunc("POST", config.MutatingWebhookServicePath, ws.handlerFunc(ws.resourceMutation, true))
 	mux.HandlerFunc("POST", config.ValidatingWebhookServicePath, ws.handlerFunc(ws.resourceValidation, true))
-	mux.HandlerFunc("POST", config.PolicyMutatingWebhookServicePath, ws.handlerFunc(ws.policyMutation, true))
-	mux.HandlerFunc("POST", config.PolicyValidatingWebhookServicePath, ws.handlerFunc(ws.policyValidation, true))
-	mux.HandlerFunc("POST", config.VerifyMutatingWebhookServicePath, ws.handlerFunc(ws.verifyHandler, false))
+	mux.HandlerFunc("POST", config.PolicyMutatingWebhookServicePath, ws.handlerFunc(noWarnings(ws.policyMutation), true))
+	mux.HandlerFunc("POST", config.PolicyValidatingWebhookServicePath, ws.handlerFunc(noWarnings(ws.policyValidation), true))
+	mux.HandlerFunc("POST", config.VerifyMutatingWebhookServicePath, ws.handlerFunc(noWarnings(ws.verifyHandler), false))
 
 	// Handle Liveness responds to a Kubernetes Liveness probe
 	// Fail this request if Kubernetes should restart this instance
@@ -202,6 +237,9 @@ func NewWebhookServer(
 		w.WriteHeader(http.StatusOK)
 	})
 
+	// Handle Metrics exposes the admission, engine and controller metrics to Prometheus
+	mux.Handler("GET", config.MetricsServicePath, metrics.Handler())
+
 	ws.server = http.Server{
 		Addr:         ":443", // Listen on port for HTTPS requests
 		TLSConfig:    &tlsConfig,
@@ -213,7 +251,17 @@ func NewWebhookServer(
 	return ws, nil
 }
 
-func (ws *WebhookServer) handlerFunc(handler func(request *v1beta1.AdmissionRequest) *v1beta1.AdmissionResponse, filter bool) http.HandlerFunc {
+// admissionHandler handles the admission request and returns the response with the warnings for the API client
+type admissionHandler func(request *v1beta1.AdmissionRequest) (*v1beta1.AdmissionResponse, []string)
+
+// noWarnings adapts a handler which does not return any warnings
+func noWarnings(handler func(request *v1beta1.AdmissionRequest) *v1beta1.AdmissionResponse) admissionHandler {
+	return func(request *v1beta1.AdmissionRequest) (*v1beta1.AdmissionResponse, []string) {
+		return handler(request), nil
+	}
+}
+
+func (ws *WebhookServer) handlerFunc(handler admissionHandler, filter bool) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		startTime := time.Now()
 		ws.lastReqTime.SetTime(startTime)
@@ -224,30 +272,29 @@ func (ws *WebhookServer) handlerFunc(handler func(request *v1beta1.AdmissionRequ
 			return
 		}
 
-		logger := ws.log.WithValues("kind", admissionReview.Request.Kind, "namespace", admissionReview.Request.Namespace, "name", admissionReview.Request.Name)
-
-		admissionReview.Response = &v1beta1.AdmissionResponse{
-			Allowed: true,
-			UID:     admissionReview.Request.UID,
-		}
+		logger := ws.log.WithValues("kind", admissionReview.Request.Kind, "namespace", admissionReview.Request.Namespace, "name", admissionReview.Request.Name, "apiVersion", admissionReview.APIVersion)
 
 		// Do not process the admission requests for kinds that are in filterKinds for filtering
 		request := admissionReview.Request
 		if filter && ws.configHandler.ToFilter(request.Kind.Kind, request.Namespace, request.Name) {
+			admissionReview.setResponse(&v1beta1.AdmissionResponse{Allowed: true}, nil)
 			writeResponse(rw, admissionReview)
 			return
 		}
 
-		admissionReview.Response = handler(request)
+		response, warnings := handler(request)
+		admissionReview.setResponse(response, warnings)
 		writeResponse(rw, admissionReview)
+		metrics.ObserveAdmissionRequest(r.URL.Path, response.Allowed, time.Since(startTime))
 		logger.V(4).Info("request processed", "processingTime", time.Since(startTime).String())
 
 		return
 	}
 }
 
-func writeResponse(rw http.ResponseWriter, admissionReview *v1beta1.AdmissionReview) {
-	responseJSON, err := json.Marshal(admissionReview)
+func writeResponse(rw http.ResponseWriter, review *admissionReview) {
+	// the request is not sent back to the API server
+	responseJSON, err := json.Marshal(admissionReview{TypeMeta: review.TypeMeta, Response: review.Response})
 	if err != nil {
 		http.Error(rw, fmt.Sprintf("Could not encode response: %v", err), http.StatusInternalServerError)
 		return
@@ -259,7 +306,7 @@ func writeResponse(rw http.ResponseWriter, admissionReview *v1beta1.AdmissionRev
 	}
 }
 
-func (ws *WebhookServer) resourceMutation(request *v1beta1.AdmissionRequest) *v1beta1.AdmissionResponse {
+func (ws *WebhookServer) resourceMutation(request *v1beta1.AdmissionRequest) (*v1beta1.AdmissionResponse, []string) {
 
 	logger := ws.log.WithName("resourceMutation").WithValues("uid", request.UID, "kind", request.Kind.Kind, "namespace", request.Namespace, "name", request.Name, "operation", request.Operation)
 
@@ -269,12 +316,12 @@ func (ws *WebhookServer) resourceMutation(request *v1beta1.AdmissionRequest) *v1
 			Result: &metav1.Status{
 				Status: "Success",
 			},
-		}
+		}, nil
 	}
 
-	mutatePolicies := ws.pCache.Get(policycache.Mutate)
-	validatePolicies := ws.pCache.Get(policycache.ValidateEnforce)
-	generatePolicies := ws.pCache.Get(policycache.Generate)
+	mutatePolicies := ws.pCache.Get(policycache.Mutate, request.Namespace)
+	validatePolicies := ws.pCache.Get(policycache.ValidateEnforce, request.Namespace)
+	generatePolicies := ws.pCache.Get(policycache.Generate, request.Namespace)
 
 	// getRoleRef only if policy has roles/clusterroles defined
 	var roles, clusterRoles []string
@@ -297,13 +344,15 @@ func (ws *WebhookServer) resourceMutation(request *v1beta1.AdmissionRequest) *v1
 				Status:  "Failure",
 				Message: err.Error(),
 			},
-		}
+		}, nil
 	}
 
 	userRequestInfo := v1.RequestInfo{
 		Roles:             roles,
 		ClusterRoles:      clusterRoles,
-		AdmissionUserInfo: *request.UserInfo.DeepCopy()}
+		AdmissionUserInfo: *request.UserInfo.DeepCopy(),
+		Operation:         v1.AdmissionOperation(request.Operation)}
+	setSubResourceInfo(&userRequestInfo, request, ws.client, logger)
 
 	// build context
 	ctx := context2.NewContext()
@@ -322,6 +371,7 @@ func (ws *WebhookServer) resourceMutation(request *v1beta1.AdmissionRequest) *v1
 	}
 
 	var patches []byte
+	var warnings []string
 	patchedResource := request.Object.Raw
 
 	if ws.supportMudateValidate {
@@ -331,6 +381,11 @@ func (ws *WebhookServer) resourceMutation(request *v1beta1.AdmissionRequest) *v1
 		if request.Operation != v1beta1.Delete {
 			patches = ws.HandleMutation(request, resource, mutatePolicies, ctx, userRequestInfo)
 			logger.V(6).Info("", "generated patches", string(patches))
+
+			// the targets are mutated in background, not for dry run requests as they have side effects
+			if hasMutateTargets(mutatePolicies) && (request.DryRun == nil || !*request.DryRun) {
+				ws.mutateTargetsHandler.Add(resource, userRequestInfo)
+			}
 		}
 
 		// patch the resource with patches before handling validation rules
@@ -338,11 +393,10 @@ func (ws *WebhookServer) resourceMutation(request *v1beta1.AdmissionRequest) *v1
 		logger.V(6).Info("", "patchedResource", string(patchedResource))
 
 		if ws.resourceWebhookWatcher != nil && ws.resourceWebhookWatcher.RunValidationInMutatingWebhook == "true" {
-			// push admission request to audit handler, this won't block the admission request
-			ws.auditHandler.Add(request.DeepCopy())
+			warnings = ws.audit(request)
 
 			// VALIDATION
-			ok, msg := HandleValidation(request, validatePolicies, nil, ctx, userRequestInfo, ws.statusListener, ws.eventGen, ws.pvGenerator, ws.log, ws.configHandler)
+			ok, msg := ws.validate(request, validatePolicies, ctx, userRequestInfo)
 			if !ok {
 				logger.Info("admission request denied")
 				return &v1beta1.AdmissionResponse{
@@ -351,7 +405,7 @@ func (ws *WebhookServer) resourceMutation(request *v1beta1.AdmissionRequest) *v1
 						Status:  "Failure",
 						Message: msg,
 					},
-				}
+				}, warnings
 			}
 		}
 	} else {
@@ -374,11 +428,49 @@ func (ws *WebhookServer) resourceMutation(request *v1beta1.AdmissionRequest) *v1
 		},
 		Patch:     patches,
 		PatchType: &patchType,
+	}, warnings
+
+}
+
+// audit applies the validate audit policies to the admission request, the failures are returned
+// as warnings if enabled, otherwise the request is processed in background
+func (ws *WebhookServer) audit(request *v1beta1.AdmissionRequest) []string {
+	if !ws.auditWarnings {
+		// push admission request to audit handler, this won't block the admission request
+		ws.auditHandler.Add(request.DeepCopy())
+		return nil
+	}
+
+	warnings, err := ws.auditHandler.Validate(request)
+	if err != nil {
+		ws.log.Error(err, "failed to apply audit policies", "uid", request.UID, "kind", request.Kind.Kind, "namespace", request.Namespace, "name", request.Name)
 	}
+	return warnings
+}
 
+// validate applies the enforce validation rules of the policies to the request
+func (ws *WebhookServer) validate(request *v1beta1.AdmissionRequest, policies []*v1.ClusterPolicy, ctx *context2.Context, userRequestInfo v1.RequestInfo) (bool, string) {
+	policyContext := engine.PolicyContext{
+		AdmissionInfo:    userRequestInfo,
+		Context:          ctx,
+		ExcludeGroupRole: ws.configHandler.GetExcludeGroupRole(),
+		ConfigMapLister:  ws.cmLister,
+		Client:           ws.client,
+		APICallTimeout:   ws.webhookRegistrationClient.GetWebhookTimeOut(),
+		NamespaceLabels:  utils.GetNamespaceLabels(ws.nsLister, request.Namespace, ws.log),
+	}
+
+	ok, msg, _ := HandleValidation(request, policies, nil, policyContext, ValidationOptions{
+		StatusListener: ws.statusListener,
+		EventGen:       ws.eventGen,
+		PVGenerator:    ws.pvGenerator,
+		Log:            ws.log,
+		FailureAction:  common.Enforce,
+	})
+	return ok, msg
 }
 
-func (ws *WebhookServer) resourceValidation(request *v1beta1.AdmissionRequest) *v1beta1.AdmissionResponse {
+func (ws *WebhookServer) resourceValidation(request *v1beta1.AdmissionRequest) (*v1beta1.AdmissionResponse, []string) {
 	logger := ws.log.WithName("resourceValidation").WithValues("uid", request.UID, "kind", request.Kind.Kind, "namespace", request.Namespace, "name", request.Name, "operation", request.Operation)
 
 	if request.Operation == v1beta1.Delete || request.Operation == v1beta1.Update {
@@ -389,7 +481,7 @@ func (ws *WebhookServer) resourceValidation(request *v1beta1.AdmissionRequest) *
 					Status:  "Failure",
 					Message: err.Error(),
 				},
-			}
+			}, nil
 		}
 	}
 
@@ -400,7 +492,7 @@ func (ws *WebhookServer) resourceValidation(request *v1beta1.AdmissionRequest) *
 			Result: &metav1.Status{
 				Status: "Success",
 			},
-		}
+		}, nil
 	}
 
 	if excludeKyvernoResources(request.Kind.Kind) {
@@ -409,16 +501,16 @@ func (ws *WebhookServer) resourceValidation(request *v1beta1.AdmissionRequest) *
 			Result: &metav1.Status{
 				Status: "Success",
 			},
-		}
+		}, nil
 	}
 
-	// push admission request to audit handler, this won't block the admission request
-	ws.auditHandler.Add(request.DeepCopy())
+	warnings := ws.audit(request)
 
-	policies := ws.pCache.Get(policycache.ValidateEnforce)
+	policies := ws.pCache.Get(policycache.ValidateEnforce, request.Namespace)
 	if len(policies) == 0 {
 		logger.V(4).Info("No enforce Validation policy found, returning")
-		return &v1beta1.AdmissionResponse{Allowed: true}
+		ws.removeDeletedResourceResults(request)
+		return &v1beta1.AdmissionResponse{Allowed: true}, warnings
 	}
 
 	var roles, clusterRoles []string
@@ -434,7 +526,7 @@ func (ws *WebhookServer) resourceValidation(request *v1beta1.AdmissionRequest) *
 					Status:  "Failure",
 					Message: err.Error(),
 				},
-			}
+			}, warnings
 		}
 		logger = logger.WithValues("username", request.UserInfo.Username,
 			"groups", request.UserInfo.Groups, "roles", roles, "clusterRoles", clusterRoles)
@@ -443,7 +535,9 @@ func (ws *WebhookServer) resourceValidation(request *v1beta1.AdmissionRequest) *
 	userRequestInfo := v1.RequestInfo{
 		Roles:             roles,
 		ClusterRoles:      clusterRoles,
-		AdmissionUserInfo: request.UserInfo}
+		AdmissionUserInfo: request.UserInfo,
+		Operation:         v1.AdmissionOperation(request.Operation)}
+	setSubResourceInfo(&userRequestInfo, request, ws.client, logger)
 
 	// build context
 	ctx := context2.NewContext()
@@ -461,7 +555,7 @@ func (ws *WebhookServer) resourceValidation(request *v1beta1.AdmissionRequest) *
 		logger.Error(err, "failed to load service account in context")
 	}
 
-	ok, msg := HandleValidation(request, policies, nil, ctx, userRequestInfo, ws.statusListener, ws.eventGen, ws.pvGenerator, ws.log, ws.configHandler)
+	ok, msg := ws.validate(request, policies, ctx, userRequestInfo)
 	if !ok {
 		logger.Info("admission request denied")
 		return &v1beta1.AdmissionResponse{
@@ -470,21 +564,36 @@ func (ws *WebhookServer) resourceValidation(request *v1beta1.AdmissionRequest) *
 				Status:  "Failure",
 				Message: msg,
 			},
-		}
+		}, warnings
 	}
 
+	ws.removeDeletedResourceResults(request)
 	return &v1beta1.AdmissionResponse{
 		Allowed: true,
 		Result: &metav1.Status{
 			Status: "Success",
 		},
+	}, warnings
+}
+
+// removeDeletedResourceResults removes the results of the resource from the policy reports when its deletion is allowed
+func (ws *WebhookServer) removeDeletedResourceResults(request *v1beta1.AdmissionRequest) {
+	if request.Operation != v1beta1.Delete || request.SubResource != "" || (request.DryRun != nil && *request.DryRun) {
+		return
 	}
+
+	ws.pvGenerator.RemoveResource(corev1.ObjectReference{
+		APIVersion: schema.GroupVersion{Group: request.Kind.Group, Version: request.Kind.Version}.String(),
+		Kind:       request.Kind.Kind,
+		Namespace:  request.Namespace,
+		Name:       request.Name,
+	})
 }
 
 // RunAsync TLS server in separate thread and returns control immediately
 func (ws *WebhookServer) RunAsync(stopCh <-chan struct{}) {
 	logger := ws.log
-	if !cache.WaitForCacheSync(stopCh, ws.pSynced, ws.rbSynced, ws.crbSynced, ws.rSynced, ws.crSynced) {
+	if !cache.WaitForCacheSync(stopCh, ws.pSynced, ws.rbSynced, ws.crbSynced, ws.rSynced, ws.crSynced, ws.cmSynced, ws.nsSynced) {
 		logger.Info("failed to sync informer cache")
 	}
 
@@ -503,12 +612,11 @@ func (ws *WebhookServer) RunAsync(stopCh <-chan struct{}) {
 	go ws.lastReqTime.Run(ws.pLister, ws.eventGen, ws.client, checker.DefaultResync, checker.DefaultDeadline, stopCh)
 }
 
-// Stop TLS server and returns control after the server is shut down
+// Stop TLS server and returns control after the server is shut down,
+// the webhook configurations are kept, the other instances keep serving requests and the next leader updates them
 func (ws *WebhookServer) Stop(ctx context.Context) {
 	logger := ws.log
-	// cleanUp
-	// remove the static webhookconfigurations
-	go ws.webhookRegistrationClient.RemoveWebhookConfigurations(ws.cleanUp)
+	close(ws.cleanUp)
 	// shutdown http.Server with context timeout
 	err := ws.server.Shutdown(ctx)
 	if err != nil {
@@ -520,7 +628,7 @@ func (ws *WebhookServer) Stop(ctx context.Context) {
 
 // bodyToAdmissionReview creates AdmissionReview object from request body
 // Answers to the http.ResponseWriter if request is not valid
-func (ws *WebhookServer) bodyToAdmissionReview(request *http.Request, writer http.ResponseWriter) *v1beta1.AdmissionReview {
+func (ws *WebhookServer) bodyToAdmissionReview(request *http.Request, writer http.ResponseWriter) *admissionReview {
 	logger := ws.log
 	if request.Body == nil {
 		logger.Info("empty body", "req", request.URL.String())
@@ -542,8 +650,8 @@ func (ws *WebhookServer) bodyToAdmissionReview(request *http.Request, writer htt
 		return nil
 	}
 
-	admissionReview := &v1beta1.AdmissionReview{}
-	if err := json.Unmarshal(body, &admissionReview); err != nil {
+	admissionReview, err := decodeAdmissionReview(body)
+	if err != nil {
 		logger.Error(err, "failed to decode request body to type 'AdmissionReview")
 		http.Error(writer, "Can't decode body as AdmissionReview", http.StatusExpectationFailed)
 		return nil
@@ -5,19 +5,25 @@ import (
 	"github.com/minio/minio/cmd/logger"
 	v1 "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	kyvernoclient "github.com/nirmata/kyverno/pkg/client/clientset/versioned"
+	"github.com/nirmata/kyverno/pkg/common"
 	"github.com/nirmata/kyverno/pkg/config"
 	"github.com/nirmata/kyverno/pkg/constant"
+	client "github.com/nirmata/kyverno/pkg/dclient"
+	"github.com/nirmata/kyverno/pkg/engine"
 	enginectx "github.com/nirmata/kyverno/pkg/engine/context"
 	"github.com/nirmata/kyverno/pkg/event"
 	"github.com/nirmata/kyverno/pkg/policycache"
 	"github.com/nirmata/kyverno/pkg/policystatus"
 	"github.com/nirmata/kyverno/pkg/policyviolation"
 	"github.com/nirmata/kyverno/pkg/userinfo"
+	"github.com/nirmata/kyverno/pkg/utils"
 	"github.com/pkg/errors"
 	"k8s.io/api/admission/v1beta1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	informerv1 "k8s.io/client-go/informers/core/v1"
 	rbacinformer "k8s.io/client-go/informers/rbac/v1"
+	listerv1 "k8s.io/client-go/listers/core/v1"
 	rbaclister "k8s.io/client-go/listers/rbac/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
@@ -34,11 +40,15 @@ const (
 // when process the admission request in the webhook
 type AuditHandler interface {
 	Add(request *v1beta1.AdmissionRequest)
+	// Validate applies the audit policies to the request in the caller's thread
+	// and returns the failed rules as warnings
+	Validate(request *v1beta1.AdmissionRequest) ([]string, error)
 	Run(workers int, stopCh <-chan struct{})
 }
 
 type auditHandler struct {
 	client         *kyvernoclient.Clientset
+	dclient        *client.Client
 	queue          workqueue.RateLimitingInterface
 	pCache         policycache.Interface
 	eventGen       event.Interface
@@ -49,22 +59,30 @@ type auditHandler struct {
 	rbSynced  cache.InformerSynced
 	crbLister rbaclister.ClusterRoleBindingLister
 	crbSynced cache.InformerSynced
+	cmLister  listerv1.ConfigMapLister
+	cmSynced  cache.InformerSynced
+	nsLister  listerv1.NamespaceLister
+	nsSynced  cache.InformerSynced
 
 	log           logr.Logger
 	configHandler config.Interface
 }
 
 // NewValidateAuditHandler returns a new instance of audit policy handler
-func NewValidateAuditHandler(pCache policycache.Interface,
+func NewValidateAuditHandler(dclient *client.Client,
+	pCache policycache.Interface,
 	eventGen event.Interface,
 	statusListener policystatus.Listener,
 	pvGenerator policyviolation.GeneratorInterface,
 	rbInformer rbacinformer.RoleBindingInformer,
 	crbInformer rbacinformer.ClusterRoleBindingInformer,
+	cmInformer informerv1.ConfigMapInformer,
+	nsInformer informerv1.NamespaceInformer,
 	log logr.Logger,
 	dynamicConfig config.Interface) AuditHandler {
 
 	return &auditHandler{
+		dclient:        dclient,
 		pCache:         pCache,
 		queue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), workQueueName),
 		eventGen:       eventGen,
@@ -74,6 +92,10 @@ func NewValidateAuditHandler(pCache policycache.Interface,
 		rbSynced:       rbInformer.Informer().HasSynced,
 		crbLister:      crbInformer.Lister(),
 		crbSynced:      crbInformer.Informer().HasSynced,
+		cmLister:       cmInformer.Lister(),
+		cmSynced:       cmInformer.Informer().HasSynced,
+		nsLister:       nsInformer.Lister(),
+		nsSynced:       nsInformer.Informer().HasSynced,
 		log:            log,
 		configHandler:  dynamicConfig,
 	}
@@ -92,7 +114,7 @@ func (h *auditHandler) Run(workers int, stopCh <-chan struct{}) {
 		h.log.V(4).Info("shutting down")
 	}()
 
-	if !cache.WaitForCacheSync(stopCh, h.rbSynced, h.crbSynced) {
+	if !cache.WaitForCacheSync(stopCh, h.rbSynced, h.crbSynced, h.cmSynced, h.nsSynced) {
 		logger.Info("failed to sync informer cache")
 	}
 
@@ -129,12 +151,22 @@ func (h *auditHandler) processNextWorkItem() bool {
 	return true
 }
 
+func (h *auditHandler) Validate(request *v1beta1.AdmissionRequest) ([]string, error) {
+	h.log.V(4).Info("validating admission request", "uid", request.UID, "kind", request.Kind.Kind, "namespace", request.Namespace, "name", request.Name, "operation", request.Operation)
+	return h.validate(request)
+}
+
 func (h *auditHandler) process(request *v1beta1.AdmissionRequest) error {
+	_, err := h.validate(request)
+	return err
+}
+
+func (h *auditHandler) validate(request *v1beta1.AdmissionRequest) ([]string, error) {
 	var roles, clusterRoles []string
 	var err error
 
 	logger := h.log.WithName("process")
-	policies := h.pCache.Get(policycache.ValidateAudit)
+	policies := h.pCache.Get(policycache.ValidateAudit, request.Namespace)
 
 	// getRoleRef only if policy has roles/clusterroles defined
 	if containRBACinfo(policies) {
@@ -147,26 +179,43 @@ func (h *auditHandler) process(request *v1beta1.AdmissionRequest) error {
 	userRequestInfo := v1.RequestInfo{
 		Roles:             roles,
 		ClusterRoles:      clusterRoles,
-		AdmissionUserInfo: request.UserInfo}
+		AdmissionUserInfo: request.UserInfo,
+		Operation:         v1.AdmissionOperation(request.Operation)}
+	setSubResourceInfo(&userRequestInfo, request, h.dclient, logger)
 
 	// build context
 	ctx := enginectx.NewContext()
 	err = ctx.AddRequest(request)
 	if err != nil {
-		return errors.Wrap(err, "failed to load incoming request in context")
+		return nil, errors.Wrap(err, "failed to load incoming request in context")
 	}
 
 	err = ctx.AddUserInfo(userRequestInfo)
 	if err != nil {
-		return errors.Wrap(err, "failed to load userInfo in context")
+		return nil, errors.Wrap(err, "failed to load userInfo in context")
 	}
 	err = ctx.AddSA(userRequestInfo.AdmissionUserInfo.Username)
 	if err != nil {
-		return errors.Wrap(err, "failed to load service account in context")
+		return nil, errors.Wrap(err, "failed to load service account in context")
+	}
+
+	policyContext := engine.PolicyContext{
+		AdmissionInfo:    userRequestInfo,
+		Context:          ctx,
+		ExcludeGroupRole: h.configHandler.GetExcludeGroupRole(),
+		ConfigMapLister:  h.cmLister,
+		Client:           h.dclient,
+		NamespaceLabels:  utils.GetNamespaceLabels(h.nsLister, request.Namespace, logger),
 	}
 
-	HandleValidation(request, policies, nil, ctx, userRequestInfo, h.statusListener, h.eventGen, h.pvGenerator, logger, h.configHandler)
-	return nil
+	_, _, warnings := HandleValidation(request, policies, nil, policyContext, ValidationOptions{
+		StatusListener: h.statusListener,
+		EventGen:       h.eventGen,
+		PVGenerator:    h.pvGenerator,
+		Log:            logger,
+		FailureAction:  common.Audit,
+	})
+	return warnings, nil
 }
 
 func (h *auditHandler) handleErr(err error) {
@@ -1,7 +1,6 @@
 package webhooks
 
 import (
-	"github.com/nirmata/kyverno/pkg/config"
 	"reflect"
 	"sort"
 	"time"
@@ -14,7 +13,6 @@ import (
 	kyverno "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	v1 "github.com/nirmata/kyverno/pkg/api/kyverno/v1"
 	"github.com/nirmata/kyverno/pkg/engine"
-	"github.com/nirmata/kyverno/pkg/engine/context"
 	"github.com/nirmata/kyverno/pkg/engine/response"
 	"github.com/nirmata/kyverno/pkg/policyviolation"
 	v1beta1 "k8s.io/api/admission/v1beta1"
@@ -22,23 +20,30 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
+// ValidationOptions holds the handlers of the validation results
+type ValidationOptions struct {
+	StatusListener policystatus.Listener
+	EventGen       event.Interface
+	PVGenerator    policyviolation.GeneratorInterface
+	Log            logr.Logger
+	// FailureAction selects the rules to process, as the enforce rules are applied
+	// by the webhook and the audit rules by the audit handler
+	FailureAction string
+}
+
 // HandleValidation handles validating webhook admission request
 // If there are no errors in validating rule we apply generation rules
 // patchedResource is the (resource + patches) after applying mutation rules
+// policyContext holds the request context, the resources are extracted from the request
 func HandleValidation(
 	request *v1beta1.AdmissionRequest,
 	policies []*kyverno.ClusterPolicy,
 	patchedResource []byte,
-	ctx *context.Context,
-	userRequestInfo kyverno.RequestInfo,
-	statusListener policystatus.Listener,
-	eventGen event.Interface,
-	pvGenerator policyviolation.GeneratorInterface,
-	log logr.Logger,
-	dynamicConfig config.Interface) (bool, string) {
+	policyContext engine.PolicyContext,
+	opts ValidationOptions) (bool, string, []string) {
 
 	if len(policies) == 0 {
-		return true, ""
+		return true, "", nil
 	}
 
 	resourceName := request.Kind.Kind + "/" + request.Name
@@ -46,14 +51,14 @@ func HandleValidation(
 		resourceName = request.Namespace + "/" + resourceName
 	}
 
-	logger := log.WithValues("action", "validate", "resource", resourceName, "operation", request.Operation)
+	logger := opts.Log.WithValues("action", "validate", "resource", resourceName, "operation", request.Operation)
 
 	// Get new and old resource
 	newR, oldR, err := utils.ExtractResources(patchedResource, request)
 	if err != nil {
 		// as resource cannot be parsed, we skip processing
 		logger.Error(err, "failed to extract resource")
-		return true, ""
+		return true, "", nil
 	}
 
 	var deletionTimeStamp *metav1.Time
@@ -64,16 +69,11 @@ func HandleValidation(
 	}
 
 	if deletionTimeStamp != nil && request.Operation == v1beta1.Update {
-		return true, ""
+		return true, "", nil
 	}
 
-	policyContext := engine.PolicyContext{
-		NewResource:      newR,
-		OldResource:      oldR,
-		Context:          ctx,
-		AdmissionInfo:    userRequestInfo,
-		ExcludeGroupRole: dynamicConfig.GetExcludeGroupRole(),
-	}
+	policyContext.NewResource = newR
+	policyContext.OldResource = oldR
 
 	var engineResponses []response.EngineResponse
 	for _, policy := range policies {
@@ -85,8 +85,13 @@ func HandleValidation(
 			// allow updates if resource update doesnt change the policy evaluation
 			continue
 		}
+
+		engineResponse = filterRulesByFailureAction(engineResponse, opts.FailureAction)
+		if len(engineResponse.PolicyResponse.Rules) == 0 {
+			continue
+		}
 		engineResponses = append(engineResponses, engineResponse)
-		statusListener.Send(validateStats{
+		opts.StatusListener.Send(validateStats{
 			resp: engineResponse,
 		})
 		if !engineResponse.IsSuccessful() {
@@ -111,18 +116,21 @@ func HandleValidation(
 	//   all policies were applied succesfully.
 	//   create an event on the resource
 	events := generateEvents(engineResponses, blocked, (request.Operation == v1beta1.Update), logger)
-	eventGen.Add(events...)
+	opts.EventGen.Add(events...)
 	if blocked {
 		logger.V(4).Info("resource blocked")
-		return false, getEnforceFailureErrorMsg(engineResponses)
+		return false, getEnforceFailureErrorMsg(engineResponses), nil
 	}
 
 	// ADD POLICY VIOLATIONS
 	// violations are created with resource on "audit"
-	pvInfos := policyviolation.GeneratePVsFromEngineResponse(engineResponses, logger)
-	pvGenerator.Add(pvInfos...)
+	// the results of a deleted resource are removed from the reports instead
+	if request.Operation != v1beta1.Delete {
+		pvInfos := policyviolation.GeneratePVsFromEngineResponse(engineResponses, logger)
+		opts.PVGenerator.Add(pvInfos...)
+	}
 
-	return true, ""
+	return true, "", getAuditWarnings(engineResponses)
 }
 
 type validateStats struct {
@@ -130,7 +138,7 @@ type validateStats struct {
 }
 
 func (vs validateStats) PolicyName() string {
-	return vs.resp.PolicyResponse.Policy
+	return vs.resp.PolicyResponse.GetPolicyKey()
 }
 
 func (vs validateStats) UpdateStatus(status kyverno.PolicyStatus) kyverno.PolicyStatus {
@@ -159,7 +167,7 @@ func (vs validateStats) UpdateStatus(status kyverno.PolicyStatus) kyverno.Policy
 		} else {
 			status.RulesFailedCount++
 			ruleStat.FailedCount++
-			if vs.resp.PolicyResponse.ValidationFailureAction == "enforce" {
+			if getRuleFailureAction(vs.resp, rule) == "enforce" {
 				status.ResourcesBlockedCount++
 				ruleStat.ResourcesBlockedCount++
 			}
@@ -27,3 +27,10 @@ ${CODEGEN_PKG}/generate-groups.sh \
     ${NIRMATA_PKG}/pkg/client \
     ${NIRMATA_PKG}/pkg/api \
     kyverno:v1
+
+# policy reports are written with the dynamic client, only the deepcopy functions are generated
+${CODEGEN_PKG}/generate-groups.sh \
+    "deepcopy" \
+    ${NIRMATA_PKG}/pkg/client \
+    ${NIRMATA_PKG}/pkg/api \
+    policyreport:v1alpha1